@@ -1,6 +1,7 @@
 package accountmgr
 
 import (
+	"github.com/ellcrys/elld/rpc"
 	"github.com/ellcrys/elld/rpc/jsonrpc"
 	"github.com/ellcrys/elld/types"
 )
@@ -26,6 +27,8 @@ func (am *AccountManager) APIs() jsonrpc.APISet {
 		"listAccounts": {
 			Namespace:   types.NamespacePersonal,
 			Description: "List all accounts",
+			Result:      jsonrpc.Array("The addresses of the accounts", jsonrpc.String("An address")),
+			Errors:      []*jsonrpc.Err{rpc.ErrDescListAccountFailed},
 			Func:        am.apiListAccounts,
 		},
 	}
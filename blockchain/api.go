@@ -464,6 +464,38 @@ func (b *Blockchain) apiSuggestNonce(arg interface{}) *jsonrpc.Response {
 	return jsonrpc.Success(suggested)
 }
 
+// apiGetMinedBlocksParams describes the parameters of getMinedBlocks
+var apiGetMinedBlocksParams = jsonrpc.Object("The query options", map[string]*jsonrpc.Schema{
+	"limit":         jsonrpc.Integer("The maximum number of blocks").Opt(),
+	"lastHash":      jsonrpc.String("The hash of the last block of the previous page").Opt(),
+	"creatorPubKey": jsonrpc.String("The public key of the block creator").Opt(),
+}).Opt()
+
+// apiGetMinedBlocksResult describes the result of getMinedBlocks
+var apiGetMinedBlocksResult = jsonrpc.Object("The blocks", map[string]*jsonrpc.Schema{
+	"blocks":  jsonrpc.Array("The blocks", jsonrpc.Object("A block", nil)),
+	"hasMore": jsonrpc.Boolean("Whether there are more blocks"),
+})
+
+// apiGetDifficultyResult describes the result of getDifficulty
+var apiGetDifficultyResult = jsonrpc.Object("The difficulty of the tip block", map[string]*jsonrpc.Schema{
+	"difficulty":      jsonrpc.String("The difficulty"),
+	"totalDifficulty": jsonrpc.String("The total difficulty"),
+})
+
+// apiGetObjectsParams describes the parameters of getObjects
+var apiGetObjectsParams = jsonrpc.Object("The query", map[string]*jsonrpc.Schema{
+	"type":        jsonrpc.String("The type of objects to get"),
+	"chainID":     jsonrpc.String("The ID of the chain").Opt(),
+	"blockNumber": jsonrpc.Integer("The number of the block").Opt(),
+	"address":     jsonrpc.String("The address of an account").Opt(),
+})
+
+// apiGetTransactionStatusResult describes the result of getTransactionStatus
+var apiGetTransactionStatusResult = jsonrpc.Object("The status", map[string]*jsonrpc.Schema{
+	"status": jsonrpc.String("The status (unknown, pooled or mined)"),
+})
+
 // APIs returns all API handlers
 func (b *Blockchain) APIs() jsonrpc.APISet {
 	return map[string]jsonrpc.APIInfo{
@@ -472,81 +504,123 @@ func (b *Blockchain) APIs() jsonrpc.APISet {
 		"getBranches": {
 			Namespace:   types.NamespaceState,
 			Description: "Get all branches",
+			Result:      jsonrpc.Array("The branches", jsonrpc.Object("A branch", nil)),
+			Errors:      []*jsonrpc.Err{rpc.ErrDescQueryFailed},
 			Func:        b.apiGetChains,
 		},
 		"getBlock": {
 			Namespace:   types.NamespaceState,
 			Description: "Get a block by number",
+			Params:      jsonrpc.Integer("The number of the block"),
+			Result:      jsonrpc.Object("The block", nil),
+			Errors:      []*jsonrpc.Err{rpc.ErrDescArgType, rpc.ErrDescBlockNotFound, rpc.ErrDescQueryFailed},
 			Func:        b.apiGetBlock,
 		},
 		"getTipBlock": {
 			Namespace:   types.NamespaceState,
 			Description: "Get a highest block on the main chain",
+			Result:      jsonrpc.Object("The block", nil),
+			Errors:      []*jsonrpc.Err{rpc.ErrDescBlockNotFound, rpc.ErrDescQueryFailed},
 			Func:        b.apiGetTipBlock,
 		},
 		"getBlockByHash": {
 			Namespace:   types.NamespaceState,
 			Description: "Get a block by hash",
+			Params:      jsonrpc.String("The hash of the block"),
+			Result:      jsonrpc.Object("The block", nil),
+			Errors:      []*jsonrpc.Err{rpc.ErrDescArgType, rpc.ErrDescBlockNotFound, rpc.ErrDescQueryFailed},
 			Func:        b.apiGetBlockByHash,
 		},
 		"getMinedBlocks": {
 			Namespace:   types.NamespaceState,
 			Description: "Get blocks mined on this node",
+			Params:      apiGetMinedBlocksParams,
+			Result:      apiGetMinedBlocksResult,
+			Cost:        10,
+			Errors:      []*jsonrpc.Err{rpc.ErrDescArgType, rpc.ErrDescQueryFailed},
 			Func:        b.apiGetMinedBlocks,
 		},
 		"getOrphans": {
 			Namespace:   types.NamespaceState,
 			Description: "Get a list of orphans",
+			Result:      jsonrpc.Array("The orphan blocks", jsonrpc.Object("The block", nil)),
 			Func:        b.apiGetOrphans,
 		},
 		"getBestChain": {
 			Namespace:   types.NamespaceState,
 			Description: "Get the best chain",
+			Result:      jsonrpc.Object("The best chain", nil),
+			Errors:      []*jsonrpc.Err{rpc.ErrDescQueryFailed},
 			Func:        b.apiGetBestchain,
 		},
 		"getReOrgs": {
 			Namespace:   types.NamespaceState,
 			Description: "Get a list of re-organization events",
+			Result:      jsonrpc.Array("The re-organizations", jsonrpc.Object("A re-organization", nil)),
 			Func:        b.apiGetReOrgs,
 		},
 		"getAccount": {
 			Namespace:   types.NamespaceState,
 			Description: "Get an account",
+			Params:      jsonrpc.String("The address of the account"),
+			Result:      jsonrpc.Object("The account", nil),
+			Errors:      []*jsonrpc.Err{rpc.ErrDescArgType, rpc.ErrDescAccountNotFound},
 			Func:        b.apiGetAccount,
 		},
 		"listAccounts": {
 			Namespace:   types.NamespaceState,
 			Description: "List all accounts",
+			Result:      jsonrpc.Array("The accounts", jsonrpc.Object("An account", nil)),
+			Cost:        50,
+			Errors:      []*jsonrpc.Err{rpc.ErrDescQueryFailed},
 			Func:        b.apiListAccounts,
 		},
 		"listTopAccounts": {
 			Namespace:   types.NamespaceState,
 			Description: "List top accounts",
+			Params:      jsonrpc.Integer("The number of accounts"),
+			Result:      jsonrpc.Array("The accounts", jsonrpc.Object("An account", nil)),
+			Cost:        50,
+			Errors:      []*jsonrpc.Err{rpc.ErrDescArgType, rpc.ErrDescQueryFailed},
 			Func:        b.apiListTopNAccounts,
 		},
 		"getAccountNonce": {
 			Namespace:   types.NamespaceState,
 			Description: "Get the nonce of an account",
+			Params:      jsonrpc.String("The address of the account"),
+			Result:      jsonrpc.Integer("The nonce of the account"),
+			Errors:      []*jsonrpc.Err{rpc.ErrDescArgType, rpc.ErrDescAccountNotFound},
 			Func:        b.apiGetNonce,
 		},
 		"getTransaction": {
 			Namespace:   types.NamespaceState,
 			Description: "Get a transaction by hash",
+			Params:      jsonrpc.String("The hash of the transaction"),
+			Result:      jsonrpc.Object("The transaction", nil),
+			Errors:      []*jsonrpc.Err{rpc.ErrDescArgType, rpc.ErrDescQueryParam, rpc.ErrDescTxNotFound},
 			Func:        b.apiGetTransaction,
 		},
 		"getDifficulty": {
 			Namespace:   types.NamespaceState,
 			Description: "Get difficulty information",
+			Result:      apiGetDifficultyResult,
+			Errors:      []*jsonrpc.Err{rpc.ErrDescBlockNotFound, rpc.ErrDescQueryFailed},
 			Func:        b.apiGetDifficultyInfo,
 		},
 		"getObjects": {
 			Namespace:   types.NamespaceState,
 			Description: "Get raw database objects (for debugging)",
+			Params:      apiGetObjectsParams,
+			Result:      jsonrpc.Array("The objects", jsonrpc.Object("An object", nil)),
+			Cost:        50,
 			Func:        b.apiGetDBObjects,
 		},
 		"suggestNonce": {
 			Namespace:   types.NamespaceState,
 			Description: "Suggest an account nonce to use in a new transaction",
+			Params:      jsonrpc.String("The address of the account"),
+			Result:      jsonrpc.Integer("The suggested nonce"),
+			Errors:      []*jsonrpc.Err{rpc.ErrDescArgType, rpc.ErrDescAccountNotFound, rpc.ErrDescUnexpected},
 			Func:        b.apiSuggestNonce,
 		},
 
@@ -554,17 +628,26 @@ func (b *Blockchain) APIs() jsonrpc.APISet {
 		"getTransactionStatus": {
 			Namespace:   types.NamespaceNode,
 			Description: "Get a transaction's status",
+			Params:      jsonrpc.String("The hash of the transaction"),
+			Result:      apiGetTransactionStatusResult,
+			Errors:      []*jsonrpc.Err{rpc.ErrDescArgType, rpc.ErrDescQueryParam, rpc.ErrDescQueryFailed},
 			Func:        b.apiGetTransactionStatus,
 		},
 		"getTransactionFromPool": {
 			Namespace:   types.NamespaceNode,
 			Description: "Get a transaction by hash from pool",
+			Params:      jsonrpc.String("The hash of the transaction"),
+			Result:      jsonrpc.Object("The transaction", nil),
+			Errors:      []*jsonrpc.Err{rpc.ErrDescArgType, rpc.ErrDescQueryParam, rpc.ErrDescTxNotFound},
 			Func:        b.apiGetTransactionFromPool,
 		},
 		// namespace: "ell"
 		"getBalance": {
 			Namespace:   types.NamespaceEll,
 			Description: "Get account balance",
+			Params:      jsonrpc.String("The address of the account"),
+			Result:      jsonrpc.String("The balance of the account"),
+			Errors:      []*jsonrpc.Err{rpc.ErrDescArgType, rpc.ErrDescAccountNotFound},
 			Func:        b.apiGetBalance,
 		},
 	}
@@ -189,7 +189,7 @@ func (b *Blockchain) Generate(params *types.GenerateBlockParams, opts ...types.C
 
 	// select transactions and compute transaction root
 	if len(params.Transactions) == 0 {
-		selectedTxs, err := b.selectTransactions(p.MaxBlockTxsSize)
+		selectedTxs, err := b.selectTransactions(p.MaxBlockTxsSize, block.Header)
 		if err != nil {
 			return nil, err
 		}
@@ -325,6 +325,7 @@ func (v *BlockValidator) checkSignature() (errs []error) {
 func (v *BlockValidator) CheckTransactions(opts ...types.CallOp) (errs []error) {
 	txValidator := NewTxsValidator(v.block.GetTransactions(), v.txpool, v.bChain)
 	txValidator.addContext(v.contexts...)
+	txValidator.block = v.block
 	for _, err := range txValidator.Validate(opts...) {
 		errs = append(errs, fmt.Errorf(strings.Replace(err.Error(), "index:", "tx:", -1)))
 	}
@@ -750,19 +750,43 @@ func (b *Blockchain) GetEventEmitter() *emitter.Emitter {
 }
 
 // selectTransactions collects transactions from the head
-// of the pool up to the specified maxSize.
-func (b *Blockchain) selectTransactions(maxSize int64) (selectedTxs []types.Transaction,
-	err error) {
+// of the pool up to the specified maxSize. Only transactions
+// whose validity window includes the given header's number
+// and timestamp are selected. Transactions whose window
+// has ended are removed from the pool.
+func (b *Blockchain) selectTransactions(maxSize int64,
+	header types.Header) (selectedTxs []types.Transaction, err error) {
 
 	totalSelectedTxsSize := int64(0)
 	cache := []types.Transaction{}
 	nonces := make(map[util.String]uint64)
+
+	// Keep track of local transactions so they
+	// are put back in the pool as local transactions
+	locals := make(map[string]struct{})
+	for _, tx := range b.txPool.GetLocal() {
+		locals[tx.GetHash().HexStr()] = struct{}{}
+	}
 	for b.txPool.Size() > 0 {
 
 		// Get a transaction from the top of
 		// the pool
 		tx := b.txPool.Container().First()
 
+		// Transactions whose validity window has ended
+		// are dropped while those whose window has not
+		// started are put back in the pool.
+		if validity := tx.GetValidity(); validity != nil {
+			if validity.IsExpired(header.GetNumber(), header.GetTimestamp()) {
+				go b.eventEmitter.Emit(core.EventTransactionExpired, tx)
+				continue
+			}
+			if validity.IsPending(header.GetNumber(), header.GetTimestamp()) {
+				cache = append(cache, tx)
+				continue
+			}
+		}
+
 		// Check whether the addition of this
 		// transaction will push us over the
 		// size limit
@@ -816,6 +840,10 @@ func (b *Blockchain) selectTransactions(maxSize int64) (selectedTxs []types.Tran
 
 	// put the cached transactions back to the pool
 	for _, tx := range cache {
+		if _, ok := locals[tx.GetHash().HexStr()]; ok {
+			_ = b.txPool.PutLocal(tx)
+			continue
+		}
 		_ = b.txPool.Put(tx)
 	}
 
@@ -14,6 +14,7 @@ import (
 	"github.com/ellcrys/elld/types/core"
 
 	"github.com/ellcrys/elld/util"
+	"github.com/olebedev/emitter"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 )
@@ -592,6 +593,7 @@ var _ = Describe("IntegrationBlockchain", func() {
 
 		Describe(".SelectTransactions", func() {
 
+			var header = &core.Header{Number: 2, Timestamp: time.Now().Unix()}
 			var tp types.TxPool
 			var tx, tx2, tx3 *core.Transaction
 			var txs []types.Transaction
@@ -604,7 +606,7 @@ var _ = Describe("IntegrationBlockchain", func() {
 						tx.Hash = tx.ComputeHash()
 						tp.Put(tx)
 						maxSize := tx.GetSizeNoFee() + 100
-						txs, err = bc.selectTransactions(maxSize)
+						txs, err = bc.selectTransactions(maxSize, header)
 						Expect(err).To(BeNil())
 					})
 
@@ -637,7 +639,7 @@ var _ = Describe("IntegrationBlockchain", func() {
 
 						Expect(tp.Size()).To(Equal(int64(2)))
 						maxSize := tx.GetSizeNoFee() + tx2.GetSizeNoFee()
-						txs, err = bc.selectTransactions(maxSize)
+						txs, err = bc.selectTransactions(maxSize, header)
 						Expect(err).To(BeNil())
 					})
 
@@ -667,7 +669,7 @@ var _ = Describe("IntegrationBlockchain", func() {
 						Expect(err).To(BeNil())
 
 						maxSize := tx.GetSizeNoFee() + tx2.GetSizeNoFee()
-						txs, err = bc.selectTransactions(maxSize)
+						txs, err = bc.selectTransactions(maxSize, header)
 						Expect(err).To(BeNil())
 					})
 
@@ -699,7 +701,7 @@ var _ = Describe("IntegrationBlockchain", func() {
 						Expect(err).To(BeNil())
 
 						maxSize := tx.GetSizeNoFee() + tx2.GetSizeNoFee()
-						txs, err = bc.selectTransactions(maxSize)
+						txs, err = bc.selectTransactions(maxSize, header)
 						Expect(err).To(BeNil())
 					})
 
@@ -722,7 +724,7 @@ var _ = Describe("IntegrationBlockchain", func() {
 						tx.Hash = tx.ComputeHash()
 						tp.Put(tx)
 						maxSize := tx.GetSizeNoFee() + 100
-						txs, err = bc.selectTransactions(maxSize)
+						txs, err = bc.selectTransactions(maxSize, header)
 						Expect(err).To(BeNil())
 					})
 
@@ -755,7 +757,7 @@ var _ = Describe("IntegrationBlockchain", func() {
 
 						Expect(tp.Size()).To(Equal(int64(2)))
 						maxSize := tx.GetSizeNoFee() + tx2.GetSizeNoFee()
-						txs, err = bc.selectTransactions(maxSize)
+						txs, err = bc.selectTransactions(maxSize, header)
 						Expect(err).To(BeNil())
 					})
 
@@ -787,14 +789,14 @@ var _ = Describe("IntegrationBlockchain", func() {
 
 				It("should only include transactions up to the given max size", func() {
 					maxSize := tx.GetSizeNoFee() + tx2.GetSizeNoFee()
-					txs, err := bc.selectTransactions(maxSize)
+					txs, err := bc.selectTransactions(maxSize, header)
 					Expect(err).To(BeNil())
 					Expect(txs).To(HaveLen(2))
 				})
 
 				It("should only include all transactions when max size exceeds pool size", func() {
 					maxSize := tx.GetSizeNoFee() + tx2.GetSizeNoFee() + tx3.GetSizeNoFee() + 100
-					txs, err := bc.selectTransactions(maxSize)
+					txs, err := bc.selectTransactions(maxSize, header)
 					Expect(err).To(BeNil())
 					Expect(txs).To(HaveLen(3))
 				})
@@ -802,13 +804,66 @@ var _ = Describe("IntegrationBlockchain", func() {
 				When("max size is too small", func() {
 					It("should select nothing and put back all transactions back in the pool", func() {
 						maxSize := int64(1)
-						txs, err := bc.selectTransactions(maxSize)
+						txs, err := bc.selectTransactions(maxSize, header)
 						Expect(err).To(BeNil())
 						Expect(txs).To(HaveLen(0))
 						Expect(tp.Size()).To(Equal(int64(3)))
 					})
 				})
 			})
+
+			Context("with transactions that have a validity window", func() {
+				BeforeEach(func() {
+					tp = bc.txPool
+					tx = core.NewTx(core.TxTypeBalance, 1, util.String(sender.Addr()), sender, "0.1", "0.001", time.Now().Unix())
+					tx.Validity = &types.TxValidity{NotAfterHeight: 1}
+					tx.Hash = tx.ComputeHash()
+					tp.Put(tx)
+
+					tx2 = core.NewTx(core.TxTypeBalance, 1, util.String(sender.Addr()), sender, "0.2", "0.001", time.Now().Unix())
+					tx2.Validity = &types.TxValidity{NotBeforeHeight: 3}
+					tx2.Hash = tx2.ComputeHash()
+					tp.Put(tx2)
+				})
+
+				It("should not select expired and pending transactions", func() {
+					txs, err := bc.selectTransactions(1000, header)
+					Expect(err).To(BeNil())
+					Expect(txs).To(BeEmpty())
+				})
+
+				It("should remove expired transactions and keep pending transactions in the pool", func() {
+					_, err := bc.selectTransactions(1000, header)
+					Expect(err).To(BeNil())
+					Expect(tp.Has(tx)).To(BeFalse())
+					Expect(tp.Has(tx2)).To(BeTrue())
+				})
+
+				It("should emit an expired event for expired transactions", func() {
+					expired := bc.GetEventEmitter().On(core.EventTransactionExpired)
+					_, err := bc.selectTransactions(1000, header)
+					Expect(err).To(BeNil())
+					var e emitter.Event
+					Eventually(expired).Should(Receive(&e))
+					Expect(e.Args[0]).To(Equal(tx))
+				})
+			})
+
+			Context("with local transactions", func() {
+				BeforeEach(func() {
+					tp = bc.txPool
+					tx = core.NewTx(core.TxTypeBalance, 1, util.String(sender.Addr()), sender, "0.1", "0.001", time.Now().Unix())
+					tx.Hash = tx.ComputeHash()
+					tp.PutLocal(tx)
+				})
+
+				It("should put local transactions back in the pool as local transactions", func() {
+					txs, err := bc.selectTransactions(1000, header)
+					Expect(err).To(BeNil())
+					Expect(txs).To(HaveLen(1))
+					Expect(tp.IsLocal(tx.GetHash().HexStr())).To(BeTrue())
+				})
+			})
 		})
 	})
 
@@ -137,6 +137,122 @@ func (b *Blockchain) processBalanceTx(tx types.Transaction, ops []common.Transit
 	return txOps, nil
 }
 
+// processMultiTransferTx process a TxTypeMultiTransfer
+// transaction. It takes the total value of the outputs
+// and the fee from a sender's account and adds the value
+// of each output to its recipient's account. The nonce
+// of the sender account is incremented.
+//
+// The accounts are searched in the given ops which
+// contains other transition objects effected by other
+// transactions in same block.
+//
+// It will create a OpCreateAccount transition object
+// for each recipient account that does not exist.
+func (b *Blockchain) processMultiTransferTx(tx types.Transaction, ops []common.Transition,
+	chain types.Chainer, opts ...types.CallOp) ([]common.Transition, error) {
+	var txOps []common.Transition
+
+	// accounts holds the current state of the accounts
+	// affected by the transaction. It ensures a recipient
+	// that appears in more than one output or is also
+	// the sender is updated cumulatively.
+	var accounts = make(map[util.String]types.Account)
+
+	// getAccount finds the current state of an account in the
+	// accounts affected so far, in previous operations or in
+	// the database. If create is true, a new account is
+	// created if it does not exist.
+	getAccount := func(address util.String, create bool) (types.Account, error) {
+		if acct, ok := accounts[address]; ok {
+			return acct, nil
+		}
+
+		var acct types.Account
+		for _, prevOp := range ops {
+			if opNewBalance, yes := prevOp.(*common.OpNewAccountBalance); yes &&
+				opNewBalance.Address() == address {
+				acct = opNewBalance.Account
+			}
+		}
+
+		if acct == nil {
+			var err error
+			acct, err = b.NewWorldReader().GetAccount(chain, address, opts...)
+			if err != nil {
+				if err != core.ErrAccountNotFound || !create {
+					return nil, err
+				}
+				acct = &core.Account{
+					Type:    core.AccountTypeBalance,
+					Address: address,
+					Balance: "0",
+				}
+				txOps = append(txOps, &common.OpCreateAccount{
+					OpBase:  &common.OpBase{Addr: address},
+					Account: acct,
+				})
+			}
+		}
+
+		accounts[address] = acct
+		return acct, nil
+	}
+
+	senderAcct, err := getAccount(tx.GetFrom(), false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sender's account: %s", err)
+	}
+
+	// Ensure the sender's account balance is
+	// sufficient for this transaction value + fee
+	deductable := tx.GetValue().Decimal().Add(tx.GetFee().Decimal())
+	if senderAcct.GetBalance().Decimal().LessThan(deductable) {
+		return nil, fmt.Errorf("insufficient sender account balance")
+	}
+
+	// Set the new balance of the sender
+	newSenderBal := senderAcct.GetBalance().Decimal().
+		Sub(deductable).StringFixed(params.Decimals)
+	senderAcct.SetBalance(util.String(newSenderBal))
+
+	// Set the new balance of each recipient
+	for _, out := range tx.GetOutputs() {
+		recipientAcct, err := getAccount(out.To, true)
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve recipient account: %s", err)
+		}
+		newRecipientBal := recipientAcct.GetBalance().Decimal().
+			Add(out.Value.Decimal()).StringFixed(params.Decimals)
+		recipientAcct.SetBalance(util.String(newRecipientBal))
+	}
+
+	// increment the sender's nonce
+	senderAcct.IncrNonce()
+
+	// Add an operation to set the new balance of the
+	// sender and each recipient. An account that appears
+	// more than once gets a single operation holding its
+	// cumulative balance.
+	txOps = append(txOps, &common.OpNewAccountBalance{
+		OpBase:  &common.OpBase{Addr: tx.GetFrom()},
+		Account: senderAcct,
+	})
+	updated := map[util.String]struct{}{tx.GetFrom(): {}}
+	for _, out := range tx.GetOutputs() {
+		if _, ok := updated[out.To]; ok {
+			continue
+		}
+		updated[out.To] = struct{}{}
+		txOps = append(txOps, &common.OpNewAccountBalance{
+			OpBase:  &common.OpBase{Addr: out.To},
+			Account: accounts[out.To],
+		})
+	}
+
+	return txOps, nil
+}
+
 // processAllocCoinTx process a TxTypeAllocCoin. It
 // allocates value set in a transaction to specific
 // account.
@@ -247,6 +363,8 @@ func (b *Blockchain) ProcessTransactions(txs []types.Transaction, chain types.Ch
 			newOps, err = b.processBalanceTx(tx, ops, chain, opts...)
 		case core.TxTypeAlloc:
 			newOps, err = b.processAllocCoinTx(tx, ops, chain, opts...)
+		case core.TxTypeMultiTransfer:
+			newOps, err = b.processMultiTransferTx(tx, ops, chain, opts...)
 		}
 
 		if err != nil {
@@ -347,6 +347,89 @@ var _ = Describe("Process", func() {
 		})
 	})
 
+	Describe(".processTransactions (only TxTypeMultiTransfer transactions)", func() {
+
+		var receiver2 = crypto.NewKeyFromIntSeed(3)
+		var ops []common.Transition
+		var tx *core.Transaction
+
+		BeforeEach(func() {
+			account := &core.Account{Type: core.AccountTypeBalance, Address: util.String(sender.Addr()), Balance: "10"}
+			err = bc.CreateAccount(1, genesisChain, account)
+			Expect(err).To(BeNil())
+
+			tx = &core.Transaction{
+				Type: core.TxTypeMultiTransfer, Nonce: 1,
+				From:         sender.Addr(),
+				SenderPubKey: "48d9u6L7tWpSVYmTE4zBDChMUasjP5pvoXE7kPw5HbJnXRnZBNC",
+				Value:        "3",
+				Outputs: []*types.TxOutput{
+					{To: receiver.Addr(), Value: "1"},
+					{To: receiver2.Addr(), Value: "0.5"},
+					{To: receiver.Addr(), Value: "1.5"},
+				},
+				Timestamp: 1532730724,
+				Fee:       "0.1", Sig: []uint8{},
+				Hash: util.Hash{},
+			}
+		})
+
+		It("should return error if sender account has insufficient value", func() {
+			tx.Value = "100"
+			_, err := bc.ProcessTransactions([]types.Transaction{tx}, genesisChain)
+			Expect(err).ToNot(BeNil())
+			Expect(err.Error()).To(Equal("index{0}: insufficient sender account balance"))
+		})
+
+		Context("recipients do not have accounts", func() {
+
+			BeforeEach(func() {
+				ops, err = bc.ProcessTransactions([]types.Transaction{tx}, genesisChain)
+				Expect(err).To(BeNil())
+			})
+
+			It("should return 5 operations", func() {
+				Expect(ops).To(HaveLen(5))
+			})
+
+			It("should create an account for each recipient", func() {
+				Expect(ops[0]).To(BeAssignableToTypeOf(&common.OpCreateAccount{}))
+				Expect(ops[0].Address()).To(Equal(receiver.Addr()))
+				Expect(ops[1]).To(BeAssignableToTypeOf(&common.OpCreateAccount{}))
+				Expect(ops[1].Address()).To(Equal(receiver2.Addr()))
+			})
+
+			It("should set the sender account balance = 6.900000000000000000", func() {
+				Expect(ops[2].Address()).To(Equal(tx.GetFrom()))
+				Expect(ops[2].(*common.OpNewAccountBalance).Account.GetBalance()).To(Equal(util.String("6.900000000000000000")))
+			})
+
+			It("should set the balance of each recipient to the sum of its outputs", func() {
+				Expect(ops[3].Address()).To(Equal(receiver.Addr()))
+				Expect(ops[3].(*common.OpNewAccountBalance).Account.GetBalance()).To(Equal(util.String("2.500000000000000000")))
+				Expect(ops[4].Address()).To(Equal(receiver2.Addr()))
+				Expect(ops[4].(*common.OpNewAccountBalance).Account.GetBalance()).To(Equal(util.String("0.500000000000000000")))
+			})
+		})
+
+		It("should return a single balance operation for a recipient in more than one output", func() {
+			ops, err = bc.processMultiTransferTx(tx, nil, genesisChain)
+			Expect(err).To(BeNil())
+			Expect(ops).To(HaveLen(5))
+			var balanceOps []string
+			for _, op := range ops {
+				if _, ok := op.(*common.OpNewAccountBalance); ok {
+					balanceOps = append(balanceOps, op.Address().String())
+				}
+			}
+			Expect(balanceOps).To(Equal([]string{
+				sender.Addr().String(),
+				receiver.Addr().String(),
+				receiver2.Addr().String(),
+			}))
+		})
+	})
+
 	Describe(".processTransactions (only TxTypeAllocCoin transactions)", func() {
 		When("recipient account does not exist", func() {
 			It("should successfully return one state object = OpNewAccountBalance", func() {
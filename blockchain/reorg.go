@@ -434,5 +434,13 @@ func (b *Blockchain) reOrg(mainChain, proposedBranch *Chain, opts ...types.CallO
 		return nil, fmt.Errorf("failed to commit: %s", err)
 	}
 
+	go b.eventEmitter.Emit(core.EventReOrg, &ReOrgInfo{
+		MainChainID: mainChain.id.String(),
+		BranchID:    proposedBranch.id.String(),
+		BranchLen:   sideTip.GetNumber() - parentBlock.GetNumber(),
+		ReOrgLen:    tip.GetNumber() - parentBlock.GetNumber(),
+		Timestamp:   now.Unix(),
+	})
+
 	return mainChain, nil
 }
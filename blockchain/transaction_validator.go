@@ -2,6 +2,7 @@ package blockchain
 
 import (
 	"fmt"
+	"time"
 
 	validation "github.com/go-ozzo/ozzo-validation"
 
@@ -21,6 +22,7 @@ import (
 var KnownTransactionTypes = []int64{
 	core.TxTypeBalance,
 	core.TxTypeAlloc,
+	core.TxTypeMultiTransfer,
 }
 
 // TxsValidator implements a validator for checking
@@ -43,6 +45,11 @@ type TxsValidator struct {
 	// transaction being validated.
 	curIndex int
 
+	// block is the block that includes the
+	// transactions. It is used to check the validity
+	// window of transactions in a block context
+	block types.Block
+
 	// nonces caches valid nonces
 	nonces map[string]uint64
 }
@@ -195,13 +202,27 @@ func (v *TxsValidator) CheckFields(tx types.Transaction) (errs []error) {
 			"unsupported transaction type"))),
 	))
 
-	// Recipient's address must be set and it must be valid
-	errs = appendErr(errs, validation.Validate(tx.GetTo(),
-		validation.Required.Error(fieldErrorWithIndex(v.curIndex, "to",
-			"recipient address is required").Error()),
-		validation.By(validAddrRule(fieldErrorWithIndex(v.curIndex, "to",
-			"recipient address is not valid"))),
-	))
+	// Recipient's address must be set and it must be valid.
+	// Multi-recipient transfers set the recipients in the
+	// outputs and must not have any other recipient.
+	if tx.GetType() != core.TxTypeMultiTransfer {
+		errs = appendErr(errs, validation.Validate(tx.GetTo(),
+			validation.Required.Error(fieldErrorWithIndex(v.curIndex, "to",
+				"recipient address is required").Error()),
+			validation.By(validAddrRule(fieldErrorWithIndex(v.curIndex, "to",
+				"recipient address is not valid"))),
+		))
+		if len(tx.GetOutputs()) > 0 {
+			errs = append(errs, fieldErrorWithIndex(v.curIndex, "outputs",
+				"outputs are only allowed in multi-recipient transfers"))
+		}
+	} else {
+		if tx.GetTo() != "" {
+			errs = append(errs, fieldErrorWithIndex(v.curIndex, "to",
+				"recipient address is not allowed in multi-recipient transfers"))
+		}
+		errs = append(errs, v.checkOutputs(tx, validAddrRule, validValueRule)...)
+	}
 
 	// Value must be >= 0 and it must be valid number
 	errs = appendErr(errs, validation.Validate(tx.GetValue(),
@@ -278,6 +299,12 @@ func (v *TxsValidator) CheckFields(tx types.Transaction) (errs []error) {
 		}
 	}
 
+	// The bounds of the validity window must not be
+	// negative and must describe a non-empty window.
+	if validity := tx.GetValidity(); validity != nil {
+		errs = append(errs, v.checkValidityFields(validity)...)
+	}
+
 	// Check signature validity
 	if sigErr := v.checkSignature(tx); len(sigErr) > 0 {
 		errs = append(errs, sigErr...)
@@ -309,6 +336,138 @@ func (v *TxsValidator) checkSignature(tx types.Transaction) (errs []error) {
 	return
 }
 
+// checkOutputs checks the outputs of a multi-recipient
+// transfer. There must be at least one and at most
+// params.MaxTxOutputs outputs. Each output must have a valid
+// recipient address and a value greater than zero. The sum
+// of the output values must equal the transaction value.
+func (v *TxsValidator) checkOutputs(tx types.Transaction,
+	validAddrRule func(error) func(interface{}) error,
+	validValueRule func(string) func(interface{}) error) (errs []error) {
+
+	outputs := tx.GetOutputs()
+	if len(outputs) == 0 {
+		errs = append(errs, fieldErrorWithIndex(v.curIndex, "outputs",
+			"at least one output is required"))
+		return
+	}
+
+	if len(outputs) > params.MaxTxOutputs {
+		errs = append(errs, fieldErrorWithIndex(v.curIndex, "outputs",
+			fmt.Sprintf("too many outputs. Maximum allowed: %d", params.MaxTxOutputs)))
+		return
+	}
+
+	var total = decimal.Zero
+	for i, out := range outputs {
+		if out == nil {
+			errs = append(errs, fieldErrorWithIndex(v.curIndex,
+				fmt.Sprintf("outputs.%d", i), "output is required"))
+			continue
+		}
+
+		err := validation.Validate(out.To,
+			validation.Required.Error(fieldErrorWithIndex(v.curIndex,
+				fmt.Sprintf("outputs.%d.to", i), "recipient address is required").Error()),
+			validation.By(validAddrRule(fieldErrorWithIndex(v.curIndex,
+				fmt.Sprintf("outputs.%d.to", i), "recipient address is not valid"))),
+		)
+		errs = appendErr(errs, err)
+
+		field := fmt.Sprintf("outputs.%d.value", i)
+		err = validation.Validate(out.Value,
+			validation.Required.Error(fieldErrorWithIndex(v.curIndex, field,
+				"value is required").Error()),
+			validation.By(validValueRule(field)),
+		)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		value := out.Value.Decimal()
+		if !value.GreaterThan(decimal.Zero) {
+			errs = append(errs, fieldErrorWithIndex(v.curIndex, field,
+				"value must be greater than zero"))
+			continue
+		}
+
+		total = total.Add(value)
+	}
+
+	if len(errs) == 0 && !total.Equal(tx.GetValue().Decimal()) {
+		errs = append(errs, fieldErrorWithIndex(v.curIndex, "value",
+			"value must equal the sum of the output values"))
+	}
+
+	return
+}
+
+// checkValidityFields checks the bounds of
+// a transaction's validity window
+func (v *TxsValidator) checkValidityFields(validity *types.TxValidity) (errs []error) {
+
+	if validity.NotBeforeTime < 0 || validity.NotAfterTime < 0 {
+		errs = append(errs, fieldErrorWithIndex(v.curIndex, "validity",
+			"timestamp bounds must not be negative"))
+	}
+
+	if validity.NotAfterHeight > 0 && validity.NotBeforeHeight > validity.NotAfterHeight {
+		errs = append(errs, fieldErrorWithIndex(v.curIndex, "validity",
+			"not-before height is greater than not-after height"))
+	}
+
+	if validity.NotAfterTime > 0 && validity.NotBeforeTime > validity.NotAfterTime {
+		errs = append(errs, fieldErrorWithIndex(v.curIndex, "validity",
+			"not-before time is greater than not-after time"))
+	}
+
+	return
+}
+
+// checkValidityWindow checks whether a transaction can be
+// included at the current height and time. In a block context,
+// the height and timestamp of the block are used. Otherwise,
+// the height of the block after the current main chain tip and
+// the current time are used. Outside the block context, only
+// expired transactions are rejected so that transactions whose
+// window has not started can wait in the pool.
+func (v *TxsValidator) checkValidityWindow(tx types.Transaction) (errs []error) {
+
+	validity := tx.GetValidity()
+	if validity == nil {
+		return
+	}
+
+	var height uint64
+	var timestamp int64
+	if v.block != nil {
+		height = v.block.GetNumber()
+		timestamp = v.block.GetHeader().GetTimestamp()
+	} else {
+		tip, err := v.bChain.ChainReader().Current()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to get chain tip: %s", err))
+			return
+		}
+		height = tip.GetNumber() + 1
+		timestamp = time.Now().Unix()
+	}
+
+	if validity.IsExpired(height, timestamp) {
+		errs = append(errs, fieldErrorWithIndex(v.curIndex, "validity",
+			"transaction validity window has ended"))
+		return
+	}
+
+	if v.block != nil && validity.IsPending(height, timestamp) {
+		errs = append(errs, fieldErrorWithIndex(v.curIndex, "validity",
+			"transaction validity window has not started"))
+	}
+
+	return
+}
+
 // consistencyCheck checks whether the transaction
 // against the current state of the blockchain.
 func (v *TxsValidator) consistencyCheck(tx types.Transaction, opts ...types.CallOp) (errs []error) {
@@ -329,6 +488,12 @@ func (v *TxsValidator) consistencyCheck(tx types.Transaction, opts ...types.Call
 		return
 	}
 
+	// The transaction must be within its validity window
+	if windowErrs := v.checkValidityWindow(tx); len(windowErrs) > 0 {
+		errs = append(errs, windowErrs...)
+		return
+	}
+
 	// No need performing nonce and balance checks for
 	// transactions inside a block that may be appended
 	// to a branch. This will be performed if the the
@@ -152,6 +152,40 @@ var _ = Describe("TransactionValidator", func() {
 					SenderPubKey: util.String(sender.PubKey().Base58()),
 					From:         receiver.Addr(),
 				}: fmt.Errorf("index:0, field:from, error:sender address is not derived from the sender public key"),
+
+				&core.Transaction{
+					Type:    core.TxTypeBalance,
+					Outputs: []*types.TxOutput{{To: receiver.Addr(), Value: "1"}},
+				}: fmt.Errorf("index:0, field:outputs, error:outputs are only allowed in multi-recipient transfers"),
+
+				&core.Transaction{
+					Type: core.TxTypeMultiTransfer,
+				}: fmt.Errorf("index:0, field:outputs, error:at least one output is required"),
+
+				&core.Transaction{
+					Type:    core.TxTypeMultiTransfer,
+					To:      receiver.Addr(),
+					Outputs: []*types.TxOutput{{To: receiver.Addr(), Value: "1"}},
+				}: fmt.Errorf("index:0, field:to, error:recipient address is not allowed in multi-recipient transfers"),
+
+				&core.Transaction{
+					Type:    core.TxTypeMultiTransfer,
+					Outputs: []*types.TxOutput{{To: "invalid", Value: "1"}},
+				}: fmt.Errorf("index:0, field:outputs.0.to, error:recipient address is not valid"),
+
+				&core.Transaction{
+					Type:    core.TxTypeMultiTransfer,
+					Outputs: []*types.TxOutput{{To: receiver.Addr(), Value: "0"}},
+				}: fmt.Errorf("index:0, field:outputs.0.value, error:value must be greater than zero"),
+
+				&core.Transaction{
+					Type:  core.TxTypeMultiTransfer,
+					Value: "3",
+					Outputs: []*types.TxOutput{
+						{To: receiver.Addr(), Value: "1"},
+						{To: sender.Addr(), Value: "1"},
+					},
+				}: fmt.Errorf("index:0, field:value, error:value must equal the sum of the output values"),
 			}
 			for tx, err := range cases {
 				validator = NewTxsValidator([]types.Transaction{tx}, nil, bc)
@@ -465,6 +499,67 @@ var _ = Describe("TransactionValidator", func() {
 				Expect(errs).To(ContainElement(fmt.Errorf("index:0, error:invalid nonce: has 2, wants 1")))
 			})
 		})
+
+		When("transaction has a validity window", func() {
+
+			var block types.Block
+
+			BeforeEach(func() {
+				block = &core.Block{Header: &core.Header{Number: 10, Timestamp: 1000}}
+			})
+
+			It("should return error when the window ended before the next block", func() {
+				tx.(*core.Transaction).Validity = &types.TxValidity{NotAfterHeight: 1}
+				validator := NewTxValidator(nil, txp, bc)
+				errs := validator.consistencyCheck(tx)
+				Expect(errs).To(ContainElement(fmt.Errorf("index:0, field:validity, error:transaction validity window has ended")))
+			})
+
+			It("should not return error when the window starts after the next block", func() {
+				tx.(*core.Transaction).Validity = &types.TxValidity{NotBeforeHeight: 100}
+				validator := NewTxValidator(nil, txp, bc)
+				errs := validator.consistencyCheck(tx)
+				Expect(errs).To(BeEmpty())
+			})
+
+			It("should return error in block context when the block is after the window", func() {
+				tx.(*core.Transaction).Validity = &types.TxValidity{NotAfterTime: 999}
+				validator := NewTxValidator(nil, txp, bc)
+				validator.addContext(types.ContextBlock)
+				validator.block = block
+				errs := validator.consistencyCheck(tx)
+				Expect(errs).To(ContainElement(fmt.Errorf("index:0, field:validity, error:transaction validity window has ended")))
+			})
+
+			It("should return error in block context when the block is before the window", func() {
+				tx.(*core.Transaction).Validity = &types.TxValidity{NotBeforeHeight: 11}
+				validator := NewTxValidator(nil, txp, bc)
+				validator.addContext(types.ContextBlock)
+				validator.block = block
+				errs := validator.consistencyCheck(tx)
+				Expect(errs).To(ContainElement(fmt.Errorf("index:0, field:validity, error:transaction validity window has not started")))
+			})
+		})
+	})
+
+	Describe(".checkValidityFields", func() {
+		It("should return error when not-before height is greater than not-after height", func() {
+			validator := NewTxsValidator(nil, nil, bc)
+			errs := validator.checkValidityFields(&types.TxValidity{NotBeforeHeight: 10, NotAfterHeight: 5})
+			Expect(errs).To(ContainElement(fmt.Errorf("index:0, field:validity, error:not-before height is greater than not-after height")))
+		})
+
+		It("should return error when a timestamp bound is negative", func() {
+			validator := NewTxsValidator(nil, nil, bc)
+			errs := validator.checkValidityFields(&types.TxValidity{NotBeforeTime: -1})
+			Expect(errs).To(ContainElement(fmt.Errorf("index:0, field:validity, error:timestamp bounds must not be negative")))
+		})
+
+		It("should return no error when bounds are valid", func() {
+			validator := NewTxsValidator(nil, nil, bc)
+			errs := validator.checkValidityFields(&types.TxValidity{NotBeforeHeight: 5, NotAfterHeight: 10})
+			Expect(errs).To(BeEmpty())
+		})
 	})
 
 })
@@ -29,6 +29,11 @@ var (
 type ContainerItem struct {
 	Tx      types.Transaction
 	FeeRate util.String
+
+	// Local indicates that the transaction was
+	// submitted by a local user and must not
+	// be evicted to make room for other transactions
+	Local bool
 }
 
 // newItem creates a container item
@@ -98,12 +103,34 @@ func (q *TxContainer) Full() bool {
 // It computes the fee rate and sorts the transactions
 // after addition.
 func (q *TxContainer) Add(tx types.Transaction) bool {
-
 	if q.Full() {
 		return false
 	}
+	return q.add(newItem(tx))
+}
 
+// AddLocal is like Add except the transaction is
+// flagged as local. When the container is full, a
+// non-local transaction is evicted to make room for
+// it and returned. Returns false if there is no
+// non-local transaction to evict.
+func (q *TxContainer) AddLocal(tx types.Transaction) (bool, types.Transaction) {
+	var evicted types.Transaction
+	if q.Full() {
+		if evicted = q.evictNonLocal(); evicted == nil {
+			return false, nil
+		}
+	}
 	item := newItem(tx)
+	item.Local = true
+	return q.add(item), evicted
+}
+
+// add computes the fee rate of an item's
+// transaction and appends it to the container
+func (q *TxContainer) add(item *ContainerItem) bool {
+
+	tx := item.Tx
 
 	// Calculate the transaction's fee rate
 	// formula: tx fee / size
@@ -247,6 +274,48 @@ func (q *TxContainer) Remove(txs ...types.Transaction) {
 	q.remove(txs...)
 }
 
+// evictNonLocal removes the non-local transaction
+// closest to the tail of the container (the lowest
+// fee rate when sorting is enabled). It returns the
+// evicted transaction or nil if none was evicted.
+func (q *TxContainer) evictNonLocal() types.Transaction {
+	q.gmx.Lock()
+	defer q.gmx.Unlock()
+	for i := len(q.container) - 1; i >= 0; i-- {
+		if item := q.container[i]; !item.Local {
+			q.remove(item.Tx)
+			return item.Tx
+		}
+	}
+	return nil
+}
+
+// IsLocal checks whether a transaction matching
+// the given hash was added as a local transaction
+func (q *TxContainer) IsLocal(hash string) bool {
+	q.gmx.RLock()
+	defer q.gmx.RUnlock()
+	for _, item := range q.container {
+		if item.Local && hash == item.Tx.GetHash().HexStr() {
+			return true
+		}
+	}
+	return false
+}
+
+// GetLocal returns all local transactions
+func (q *TxContainer) GetLocal() []types.Transaction {
+	q.gmx.RLock()
+	defer q.gmx.RUnlock()
+	var txs []types.Transaction
+	for _, item := range q.container {
+		if item.Local {
+			txs = append(txs, item.Tx)
+		}
+	}
+	return txs
+}
+
 // GetByHash get a transaction by its hash from the pool
 func (q *TxContainer) GetByHash(hash string) types.Transaction {
 	for _, item := range q.container {
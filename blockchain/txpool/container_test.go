@@ -381,4 +381,40 @@ var _ = Describe("TxContainer", func() {
 
 	})
 
+	Describe(".AddLocal", func() {
+		It("should evict the non-local transaction with the lowest fee rate when full", func() {
+			q := newTxContainer(2)
+			tx := core.NewTransaction(core.TxTypeBalance, 1, "something", "pub_key", "0", "0.2", time.Now().Unix())
+			tx.From = "sender_a"
+			tx.Hash = tx.ComputeHash()
+			tx2 := core.NewTransaction(core.TxTypeBalance, 2, "something", "pub_key", "0", "1", time.Now().Unix())
+			tx2.From = "sender_b"
+			tx2.Hash = tx2.ComputeHash()
+			tx3 := core.NewTransaction(core.TxTypeBalance, 3, "something", "pub_key", "0", "0.1", time.Now().Unix())
+			tx3.Hash = tx3.ComputeHash()
+			Expect(q.Add(tx)).To(BeTrue())
+			Expect(q.Add(tx2)).To(BeTrue())
+			added, evicted := q.AddLocal(tx3)
+			Expect(added).To(BeTrue())
+			Expect(evicted).To(Equal(tx))
+			Expect(q.Size()).To(Equal(int64(2)))
+			Expect(q.Has(tx)).To(BeFalse())
+			Expect(q.IsLocal(tx3.Hash.HexStr())).To(BeTrue())
+		})
+
+		It("should return false when full and all transactions are local", func() {
+			q := newTxContainer(1)
+			tx := core.NewTransaction(core.TxTypeBalance, 1, "something", "pub_key", "0", "0.2", time.Now().Unix())
+			tx.Hash = tx.ComputeHash()
+			tx2 := core.NewTransaction(core.TxTypeBalance, 2, "something", "pub_key", "0", "1", time.Now().Unix())
+			tx2.Hash = tx2.ComputeHash()
+			added, _ := q.AddLocal(tx)
+			Expect(added).To(BeTrue())
+			added, evicted := q.AddLocal(tx2)
+			Expect(added).To(BeFalse())
+			Expect(evicted).To(BeNil())
+			Expect(q.GetLocal()).To(HaveLen(1))
+		})
+	})
+
 })
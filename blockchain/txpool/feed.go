@@ -0,0 +1,121 @@
+package txpool
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ellcrys/elld/types"
+	"github.com/ellcrys/elld/types/core"
+	"github.com/olebedev/emitter"
+)
+
+// Feed event types
+const (
+	// FeedEventAdded describes a transaction
+	// that was added to the pool
+	FeedEventAdded = "added"
+
+	// FeedEventEvicted describes a transaction that was
+	// evicted from the pool to make room for a local transaction
+	FeedEventEvicted = "evicted"
+
+	// FeedEventMined describes a pooled transaction
+	// that was included in a block
+	FeedEventMined = "mined"
+
+	// FeedEventExpired describes a transaction that
+	// was removed from the pool because it expired
+	FeedEventExpired = "expired"
+)
+
+// feedSubscriberBufferSize is the number of events
+// that can be queued for a subscriber. Events are
+// dropped for subscribers that fall behind.
+const feedSubscriberBufferSize = 256
+
+// FeedEvent describes a change to the pool
+type FeedEvent struct {
+	Type string            `json:"type"`
+	Tx   types.Transaction `json:"tx"`
+	Time int64             `json:"time"`
+}
+
+// Feed converts pool related events emitted
+// by the event emitter into FeedEvents and
+// delivers them to subscribers.
+type Feed struct {
+	mtx    sync.RWMutex
+	evt    *emitter.Emitter
+	subs   map[int]chan *FeedEvent
+	nextID int
+}
+
+// NewFeed creates an instance of Feed
+func NewFeed(evt *emitter.Emitter) *Feed {
+	return &Feed{
+		evt:  evt,
+		subs: make(map[int]chan *FeedEvent),
+	}
+}
+
+// Start listens for pool related events
+// and delivers them to subscribers
+func (f *Feed) Start() {
+
+	var topics = map[string]string{
+		core.EventTransactionPooled:  FeedEventAdded,
+		core.EventTransactionEvicted: FeedEventEvicted,
+		core.EventTransactionMined:   FeedEventMined,
+		core.EventTransactionExpired: FeedEventExpired,
+	}
+
+	for topic, eventType := range topics {
+		go func(events <-chan emitter.Event, eventType string) {
+			for evt := range events {
+				f.send(&FeedEvent{
+					Type: eventType,
+					Tx:   evt.Args[0].(types.Transaction),
+					Time: time.Now().Unix(),
+				})
+			}
+		}(f.evt.On(topic), eventType)
+	}
+}
+
+// send delivers an event to all subscribers.
+// Subscribers whose buffer is full miss the event.
+func (f *Feed) send(fe *FeedEvent) {
+	f.mtx.RLock()
+	defer f.mtx.RUnlock()
+	for _, ch := range f.subs {
+		select {
+		case ch <- fe:
+		default:
+		}
+	}
+}
+
+// Subscribe returns a subscription ID and a
+// channel on which pool events are delivered
+func (f *Feed) Subscribe() (int, <-chan *FeedEvent) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	f.nextID++
+	ch := make(chan *FeedEvent, feedSubscriberBufferSize)
+	f.subs[f.nextID] = ch
+	return f.nextID, ch
+}
+
+// Unsubscribe removes a subscription
+func (f *Feed) Unsubscribe(id int) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	delete(f.subs, id)
+}
+
+// NumSubscribers returns the number of subscribers
+func (f *Feed) NumSubscribers() int {
+	f.mtx.RLock()
+	defer f.mtx.RUnlock()
+	return len(f.subs)
+}
@@ -0,0 +1,46 @@
+package txpool
+
+import (
+	"time"
+
+	"github.com/ellcrys/elld/crypto"
+	"github.com/ellcrys/elld/types/core"
+	"github.com/olebedev/emitter"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Feed", func() {
+
+	var feed *Feed
+	var evt *emitter.Emitter
+	var key = crypto.NewKeyFromIntSeed(1)
+
+	BeforeEach(func() {
+		evt = &emitter.Emitter{}
+		feed = NewFeed(evt)
+		feed.Start()
+	})
+
+	Describe(".Subscribe", func() {
+		It("should deliver a pooled transaction as an 'added' event", func() {
+			_, events := feed.Subscribe()
+			tx := core.NewTx(core.TxTypeBalance, 1, "a", key, "1", "0.2", time.Now().Unix())
+			<-evt.Emit(core.EventTransactionPooled, tx)
+			var fe *FeedEvent
+			Eventually(events).Should(Receive(&fe))
+			Expect(fe.Type).To(Equal(FeedEventAdded))
+			Expect(fe.Tx).To(Equal(tx))
+		})
+	})
+
+	Describe(".Unsubscribe", func() {
+		It("should remove the subscription", func() {
+			id, _ := feed.Subscribe()
+			Expect(feed.NumSubscribers()).To(Equal(1))
+			feed.Unsubscribe(id)
+			Expect(feed.NumSubscribers()).To(Equal(0))
+		})
+	})
+})
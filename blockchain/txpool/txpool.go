@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/ellcrys/elld/util"
+	"github.com/olebedev/emitter"
 
 	"github.com/ellcrys/elld/params"
 	"github.com/ellcrys/elld/types"
@@ -13,8 +14,9 @@ import (
 
 // TxPool stores transactions.
 type TxPool struct {
-	sync.RWMutex              // general mutex
-	container    *TxContainer // transaction queue
+	sync.RWMutex                  // general mutex
+	container    *TxContainer     // transaction queue
+	event        *emitter.Emitter // event emitter for pool changes
 }
 
 // New creates a new instance of TxPool.
@@ -26,6 +28,23 @@ func New(cap int64) *TxPool {
 	return tp
 }
 
+// SetEventEmitter sets the event emitter used
+// to broadcast changes to the pool
+func (tp *TxPool) SetEventEmitter(e *emitter.Emitter) {
+	tp.Lock()
+	defer tp.Unlock()
+	tp.event = e
+}
+
+// emit broadcasts an event if an
+// event emitter has been set
+func (tp *TxPool) emit(topic string, args ...interface{}) {
+	if tp.event == nil {
+		return
+	}
+	go tp.event.Emit(topic, args...)
+}
+
 // Remove removes transactions
 func (tp *TxPool) Remove(txs ...types.Transaction) {
 	tp.Lock()
@@ -39,7 +58,23 @@ func (tp *TxPool) Put(tx types.Transaction) error {
 	tp.Lock()
 	defer tp.Unlock()
 
-	if err := tp.addTx(tx); err != nil {
+	if err := tp.addTx(tx, false); err != nil {
+		return err
+	}
+
+	tp.clean()
+
+	return nil
+}
+
+// PutLocal is like Put but adds the transaction
+// as a local transaction. Local transactions are
+// not evicted to make room for other transactions.
+func (tp *TxPool) PutLocal(tx types.Transaction) error {
+	tp.Lock()
+	defer tp.Unlock()
+
+	if err := tp.addTx(tx, true); err != nil {
 		return err
 	}
 
@@ -48,10 +83,18 @@ func (tp *TxPool) Put(tx types.Transaction) error {
 	return nil
 }
 
-// isExpired checks whether a transaction has expired
+// isExpired checks whether a transaction has expired.
+// A transaction expires when it has been in the pool
+// for longer than the TTL or when the time bound of
+// its validity window has passed.
 func (tp *TxPool) isExpired(tx types.Transaction) bool {
+	now := time.Now().UTC()
+	if validity := tx.GetValidity(); validity != nil &&
+		validity.NotAfterTime > 0 && now.Unix() > validity.NotAfterTime {
+		return true
+	}
 	expTime := time.Unix(tx.GetTimestamp(), 0).UTC().AddDate(0, 0, params.TxTTL)
-	return time.Now().UTC().After(expTime)
+	return now.After(expTime)
 }
 
 // clean removes old transactions
@@ -59,17 +102,20 @@ func (tp *TxPool) clean() {
 	tp.container.IFind(func(tx types.Transaction) bool {
 		if tp.isExpired(tx) {
 			tp.container.remove(tx)
+			tp.emit(core.EventTransactionExpired, tx)
 		}
 		return false
 	})
 }
 
 // addTx adds a transaction to the queue.
+// If local is true, the transaction is
+// added as a local transaction.
 // (Not thread-safe)
-func (tp *TxPool) addTx(tx types.Transaction) error {
+func (tp *TxPool) addTx(tx types.Transaction, local bool) error {
 
 	switch tx.GetType() {
-	case core.TxTypeBalance:
+	case core.TxTypeBalance, core.TxTypeMultiTransfer:
 	default:
 		return core.ErrTxTypeUnknown
 	}
@@ -83,10 +129,21 @@ func (tp *TxPool) addTx(tx types.Transaction) error {
 	// Append the the transaction to the
 	// the queue. This will cause the pool
 	// to be re-sorted
-	if !tp.container.Add(tx) {
+	var added bool
+	var evicted types.Transaction
+	if local {
+		added, evicted = tp.container.AddLocal(tx)
+	} else {
+		added = tp.container.Add(tx)
+	}
+	if !added {
 		return ErrContainerFull
 	}
 
+	if evicted != nil {
+		tp.emit(core.EventTransactionEvicted, evicted)
+	}
+
 	return nil
 }
 
@@ -100,6 +157,17 @@ func (tp *TxPool) HasByHash(hash string) bool {
 	return tp.container.HasByHash(hash)
 }
 
+// IsLocal checks whether a transaction matching
+// the given hash was added as a local transaction
+func (tp *TxPool) IsLocal(hash string) bool {
+	return tp.container.IsLocal(hash)
+}
+
+// GetLocal returns all local transactions
+func (tp *TxPool) GetLocal() []types.Transaction {
+	return tp.container.GetLocal()
+}
+
 // Container gets the underlying
 // transaction container
 func (tp *TxPool) Container() types.TxContainer {
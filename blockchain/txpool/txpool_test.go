@@ -92,9 +92,9 @@ var _ = Describe("TxPool", func() {
 			tx = core.NewTx(core.TxTypeBalance, 1, "a", key1, "12.2", "0.2", time.Now().Unix())
 			tx2 = core.NewTx(core.TxTypeBalance, 2, "a", key1, "12.3", "0.2", time.Now().Unix())
 			tx3 = core.NewTx(core.TxTypeBalance, 2, "a", key2, "12.3", "0.2", time.Now().Unix())
-			_ = tp.addTx(tx)
-			_ = tp.addTx(tx2)
-			_ = tp.addTx(tx3)
+			_ = tp.addTx(tx, false)
+			_ = tp.addTx(tx2, false)
+			_ = tp.addTx(tx3, false)
 			Expect(tp.Size()).To(Equal(int64(3)))
 		})
 
@@ -263,4 +263,31 @@ var _ = Describe("TxPool", func() {
 
 	})
 
+	Describe(".PutLocal", func() {
+
+		var tp *TxPool
+		var key = crypto.NewKeyFromIntSeed(1)
+		var tx, tx2 types.Transaction
+
+		BeforeEach(func() {
+			tp = New(2)
+			tx = core.NewTx(core.TxTypeBalance, 1, "a", key, "12.2", "0.2", time.Now().Unix())
+			tx2 = core.NewTx(core.TxTypeBalance, 2, "a", key, "12.3", "0.2", time.Now().Unix())
+			Expect(tp.Put(tx)).To(BeNil())
+			Expect(tp.PutLocal(tx2)).To(BeNil())
+		})
+
+		It("should flag only the local transaction as local", func() {
+			Expect(tp.IsLocal(tx.GetHash().HexStr())).To(BeFalse())
+			Expect(tp.IsLocal(tx2.GetHash().HexStr())).To(BeTrue())
+			Expect(tp.GetLocal()).To(Equal([]types.Transaction{tx2}))
+		})
+
+		It("should no longer return the local transaction after it is removed", func() {
+			tp.Remove(tx2)
+			Expect(tp.IsLocal(tx2.GetHash().HexStr())).To(BeFalse())
+			Expect(tp.GetLocal()).To(BeEmpty())
+		})
+	})
+
 })
@@ -20,6 +20,7 @@ import (
 	"github.com/ellcrys/elld/config"
 	"github.com/ellcrys/elld/console"
 	"github.com/ellcrys/elld/crypto"
+	"github.com/ellcrys/elld/rpc/jsonrpc"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -43,6 +44,10 @@ account does not exist, the command will fail.`,
 		account := viper.GetString("node.account")
 		password := viper.GetString("node.password")
 		rpcAddress := viper.GetString("rpc.address")
+		rpcTLS, _ := cmd.Flags().GetBool("rpc-tls")
+		rpcTLSCA, _ := cmd.Flags().GetString("rpc-tls-ca")
+		rpcTLSCert, _ := cmd.Flags().GetString("rpc-tls-cert")
+		rpcTLSKey, _ := cmd.Flags().GetString("rpc-tls-key")
 
 		var err error
 		var coinbase *crypto.Key
@@ -59,8 +64,17 @@ account does not exist, the command will fail.`,
 		cs := console.NewAttached(coinbase, consoleHistoryFilePath, cfg, log)
 		cs.SetVersions(config.GetVersions().Protocol, BuildVersion, GoVersion, BuildCommit)
 
+		// Configure TLS if the RPC server is secured
+		if rpcTLS {
+			tlsConfig, err := jsonrpc.ClientTLSConfig(rpcTLSCA, rpcTLSCert, rpcTLSKey)
+			if err != nil {
+				log.Fatal("Failed to configure RPC TLS", "Err", err.Error())
+			}
+			cs.SetRPCTLSConfig(tlsConfig)
+		}
+
 		// Set the RPC server address to be dialled
-		cs.SetRPCServerAddr(rpcAddress, false)
+		cs.SetRPCServerAddr(rpcAddress, rpcTLS)
 
 		// Prepare the console and JS context
 		if err := cs.Prepare(); err != nil {
@@ -78,6 +92,10 @@ account does not exist, the command will fail.`,
 func init() {
 	rootCmd.AddCommand(attachCmd)
 	attachCmd.Flags().String("rpc-address", "127.0.0.1:8999", "Address RPC server will listen on")
+	attachCmd.Flags().Bool("rpc-tls", false, "Connect to the RPC server over TLS")
+	attachCmd.Flags().String("rpc-tls-ca", "", "CA certificate used to verify the RPC server (Default: system CAs)")
+	attachCmd.Flags().String("rpc-tls-cert", "", "Client certificate to present to the RPC server")
+	attachCmd.Flags().String("rpc-tls-key", "", "Private key of the client certificate")
 	attachCmd.Flags().String("account", "", "Account to load. Default account is used if not provided")
 	attachCmd.Flags().String("pwd", "", "Used as password during initial account creation or to unlock an account")
 }
@@ -84,6 +84,15 @@ func init() {
 	consoleCmd.Flags().String("rpc-address", "127.0.0.1:8999", "Address RPC server will listen on")
 	consoleCmd.Flags().Bool("rpc-disable-auth", false, "Disable RPC authentication (not recommended)")
 	consoleCmd.Flags().Int64("rpc-session-ttl", 3600000, "The time-to-live (in milliseconds) of RPC session tokens")
+	consoleCmd.Flags().String("rpc-tls-cert", "", "Certificate of the RPC server. Enables TLS when set")
+	consoleCmd.Flags().String("rpc-tls-key", "", "Private key of the RPC server certificate")
+	consoleCmd.Flags().String("rpc-tls-client-ca", "", "CA certificate used to verify RPC client certificates. Requires client certificates when set")
+	consoleCmd.Flags().String("rpc-tls-console-cert", "", "Client certificate the console presents to the RPC server. Required when --rpc-tls-client-ca is set")
+	consoleCmd.Flags().String("rpc-tls-console-key", "", "Private key of the console client certificate")
+	consoleCmd.Flags().Float64("rpc-rate-limit", 0, "The cost of RPC requests a client can make per second. Rate limiting is disabled when zero")
+	consoleCmd.Flags().Int("rpc-rate-burst", 0, "The maximum cost of RPC requests a client can make at once. Defaults to the rate limit when zero")
+	consoleCmd.Flags().Bool("rpc-rest", false, "Enables the read-only REST gateway of the RPC server")
+	consoleCmd.Flags().Bool("rpc-graphql", false, "Enables the GraphQL endpoint of the RPC server")
 	consoleCmd.Flags().String("account", "", "Coinbase account to load. An ephemeral account is used as default.")
 	consoleCmd.Flags().Int64P("seed", "s", 0, "Provide a strong seed for network account creation (not recommended)")
 	consoleCmd.Flags().String("pwd", "", "Used as password during initial account creation or loading an account")
@@ -91,4 +100,7 @@ func init() {
 	consoleCmd.Flags().Int("miners", 0, "The number of miner threads to use. (Default: Number of CPU)")
 	consoleCmd.Flags().Bool("no-net", false, "Closes the network host and prevents (in/out) connections")
 	consoleCmd.Flags().Bool("sync-disabled", false, "Disable block and transaction synchronization")
+	consoleCmd.Flags().Bool("dht", false, "Discover peers using the distributed hash table")
+	consoleCmd.Flags().Bool("mdns", false, "Discover peers on the local network using mDNS")
+	consoleCmd.Flags().String("network-id", config.DefaultNetworkID, "The ID of the network to join. Peers of other networks are rejected")
 }
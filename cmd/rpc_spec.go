@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/ellcrys/elld/accountmgr"
+	"github.com/ellcrys/elld/blockchain"
+	"github.com/ellcrys/elld/miner"
+	"github.com/ellcrys/elld/node"
+	"github.com/ellcrys/elld/rpc"
+	"github.com/ellcrys/elld/rpc/jsonrpc"
+	"github.com/spf13/cobra"
+)
+
+// rpcSpecCmd represents the rpc-spec command
+var rpcSpecCmd = &cobra.Command{
+	Use:   "rpc-spec",
+	Short: "Print the OpenRPC document of the RPC API",
+	Long: `Description:
+Print the OpenRPC document describing the namespaces, methods,
+parameters and errors of the RPC API. Use '--out' to write the
+document to a file.`,
+	Run: func(cmd *cobra.Command, args []string) {
+
+		out, _ := cmd.Flags().GetString("out")
+
+		// The API sets of the modules are collected from
+		// zero values. The API functions are not called
+		// so the modules do not need to be initialized.
+		server := jsonrpc.New("", "", false)
+		server.MergeAPISet(
+			new(node.Node).APIs(),
+			new(miner.Miner).APIs(),
+			new(accountmgr.AccountManager).APIs(),
+			new(blockchain.Blockchain).APIs(),
+			new(rpc.Server).APIs(),
+		)
+
+		doc, _ := json.MarshalIndent(server.OpenRPC(rpc.OpenRPCInfo(BuildVersion)), "", "  ")
+		if out == "" {
+			fmt.Println(string(doc))
+			return
+		}
+
+		if err := ioutil.WriteFile(out, doc, 0644); err != nil {
+			log.Fatal("Failed to write the OpenRPC document", "Err", err.Error())
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(rpcSpecCmd)
+	rpcSpecCmd.Flags().String("out", "", "The file to write the document to")
+}
@@ -18,6 +18,7 @@ import (
 	"github.com/ellcrys/elld/blockchain"
 	"github.com/ellcrys/elld/miner"
 	"github.com/ellcrys/elld/rpc"
+	"github.com/ellcrys/elld/rpc/jsonrpc"
 
 	"gopkg.in/asaskevich/govalidator.v4"
 
@@ -140,11 +141,23 @@ func start(cmd *cobra.Command, args []string, startConsole bool) (*node.Node, *r
 	viper.BindPFlag("rpc.address", cmd.Flags().Lookup("rpc-address"))
 	viper.BindPFlag("rpc.disableAuth", cmd.Flags().Lookup("rpc-disable-auth"))
 	viper.BindPFlag("rpc.sessionTTL", cmd.Flags().Lookup("rpc-session-ttl"))
+	viper.BindPFlag("rpc.tlsCert", cmd.Flags().Lookup("rpc-tls-cert"))
+	viper.BindPFlag("rpc.tlsKey", cmd.Flags().Lookup("rpc-tls-key"))
+	viper.BindPFlag("rpc.tlsClientCA", cmd.Flags().Lookup("rpc-tls-client-ca"))
+	viper.BindPFlag("rpc.tlsConsoleCert", cmd.Flags().Lookup("rpc-tls-console-cert"))
+	viper.BindPFlag("rpc.tlsConsoleKey", cmd.Flags().Lookup("rpc-tls-console-key"))
+	viper.BindPFlag("rpc.rateLimit", cmd.Flags().Lookup("rpc-rate-limit"))
+	viper.BindPFlag("rpc.rateBurst", cmd.Flags().Lookup("rpc-rate-burst"))
+	viper.BindPFlag("rpc.rest", cmd.Flags().Lookup("rpc-rest"))
+	viper.BindPFlag("rpc.graphql", cmd.Flags().Lookup("rpc-graphql"))
 	viper.BindPFlag("node.seed", cmd.Flags().Lookup("seed"))
 	viper.BindPFlag("miner.enabled", cmd.Flags().Lookup("mine"))
 	viper.BindPFlag("miner.numMiners", cmd.Flags().Lookup("miners"))
 	viper.BindPFlag("node.noNet", cmd.Flags().Lookup("no-net"))
 	viper.BindPFlag("node.syncDisabled", cmd.Flags().Lookup("sync-disabled"))
+	viper.BindPFlag("node.dht", cmd.Flags().Lookup("dht"))
+	viper.BindPFlag("node.mdns", cmd.Flags().Lookup("mdns"))
+	viper.BindPFlag("node.networkId", cmd.Flags().Lookup("network-id"))
 	account := viper.GetString("node.account")
 	password := viper.GetString("node.password")
 	listeningAddr := viper.GetString("node.address")
@@ -214,6 +227,7 @@ func start(cmd *cobra.Command, args []string, startConsole bool) (*node.Node, *r
 
 	// Configure transactions pool and assign to node
 	pool := txpool.New(params.PoolCapacity)
+	pool.SetEventEmitter(event)
 	n.SetTxsPool(pool)
 
 	if !noNet {
@@ -245,6 +259,8 @@ func start(cmd *cobra.Command, args []string, startConsole bool) (*node.Node, *r
 	// Initialize the miner, rpc server
 	miner := miner.NewMiner(coinbase, bChain, event, cfg, log)
 	rpcServer := rpc.NewServer(n.DB(), rpcAddress, cfg, log)
+	rpcServer.SetEventEmitter(event)
+	rpcServer.SetBlockchain(bChain)
 
 	// Set the node's references
 	n.SetBlockchain(bChain)
@@ -266,6 +282,11 @@ func start(cmd *cobra.Command, args []string, startConsole bool) (*node.Node, *r
 		log.Fatal("failed to load blockchain manager", "Err", err.Error())
 	}
 
+	// Set fee estimator
+	fe := node.NewFeeEstimator(n)
+	fe.Manage()
+	n.SetFeeEstimator(fe)
+
 	// Start the block manager and the node
 	n.Start()
 
@@ -297,7 +318,25 @@ func start(cmd *cobra.Command, args []string, startConsole bool) (*node.Node, *r
 		// Configure the RPC client if the server has started
 		cs = console.New(coinbase, consoleHistoryFilePath, cfg, log)
 		cs.SetVersions(config.GetVersions().Protocol, BuildVersion, GoVersion, BuildCommit)
-		cs.SetRPCServer(rpcServer, false)
+		if rpcServer.IsSecured() {
+			// The console trusts the certificate of the server. When
+			// the server verifies client certificates, the console
+			// must be given a certificate signed by the client CA.
+			var certFile, keyFile string
+			if cfg.RPC.TLSClientCA != "" {
+				certFile, keyFile = cfg.RPC.TLSConsoleCert, cfg.RPC.TLSConsoleKey
+				if certFile == "" || keyFile == "" {
+					log.Fatal("Console requires a client certificate signed by the RPC client CA " +
+						"(set --rpc-tls-console-cert and --rpc-tls-console-key)")
+				}
+			}
+			tlsConfig, err := jsonrpc.ClientTLSConfig(cfg.RPC.TLSCert, certFile, keyFile)
+			if err != nil {
+				log.Fatal("Failed to configure console RPC TLS", "Err", err.Error())
+			}
+			cs.SetRPCTLSConfig(tlsConfig)
+		}
+		cs.SetRPCServer(rpcServer, rpcServer.IsSecured())
 
 		// Prepare the console
 		if err := cs.Prepare(); err != nil {
@@ -380,6 +419,15 @@ func init() {
 	startCmd.Flags().String("rpc-address", "127.0.0.1:8999", "Address RPC server will listen on.")
 	startCmd.Flags().Bool("rpc-disable-auth", false, "Disable RPC authentication (not recommended)")
 	startCmd.Flags().Int64("rpc-session-ttl", 3600000, "The time-to-live (in milliseconds) of RPC session tokens")
+	startCmd.Flags().String("rpc-tls-cert", "", "Certificate of the RPC server. Enables TLS when set")
+	startCmd.Flags().String("rpc-tls-key", "", "Private key of the RPC server certificate")
+	startCmd.Flags().String("rpc-tls-client-ca", "", "CA certificate used to verify RPC client certificates. Requires client certificates when set")
+	startCmd.Flags().String("rpc-tls-console-cert", "", "Client certificate the console presents to the RPC server. Required when --rpc-tls-client-ca is set")
+	startCmd.Flags().String("rpc-tls-console-key", "", "Private key of the console client certificate")
+	startCmd.Flags().Float64("rpc-rate-limit", 0, "The cost of RPC requests a client can make per second. Rate limiting is disabled when zero")
+	startCmd.Flags().Int("rpc-rate-burst", 0, "The maximum cost of RPC requests a client can make at once. Defaults to the rate limit when zero")
+	startCmd.Flags().Bool("rpc-rest", false, "Enables the read-only REST gateway of the RPC server")
+	startCmd.Flags().Bool("rpc-graphql", false, "Enables the GraphQL endpoint of the RPC server")
 	startCmd.Flags().String("account", "", "Coinbase account to load. An ephemeral account is used as default.")
 	startCmd.Flags().String("pwd", "", "The password of the node's network account.")
 	startCmd.Flags().Int64P("seed", "s", 0, "Provide a strong seed for network account creation (not recommended)")
@@ -387,4 +435,7 @@ func init() {
 	startCmd.Flags().Int("miners", 0, "The number of miner threads to use. (Default: Number of CPU)")
 	startCmd.Flags().Bool("no-net", false, "Closes the network host and prevents (in/out) connections")
 	startCmd.Flags().Bool("sync-disabled", false, "Disable block and transaction synchronization")
+	startCmd.Flags().Bool("dht", false, "Discover peers using the distributed hash table")
+	startCmd.Flags().Bool("mdns", false, "Discover peers on the local network using mDNS")
+	startCmd.Flags().String("network-id", config.DefaultNetworkID, "The ID of the network to join. Peers of other networks are rejected")
 }
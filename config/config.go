@@ -32,6 +32,7 @@ var AccountDirName = "accounts"
 // in flag, env or config file.
 func setDefaultConfig() {
 	viper.SetDefault("net.version", DefaultNetVersion)
+	viper.SetDefault("node.networkId", DefaultNetworkID)
 	viper.SetDefault("node.getAddrInt", 300)
 	viper.SetDefault("node.pingInt", 500)
 	viper.SetDefault("node.selfAdvInt", 1800)
@@ -47,6 +48,11 @@ func setDefaultConfig() {
 	viper.SetDefault("rpc.password", "admin")
 	viper.SetDefault("rpc.sessionSecretKey", util.RandString(32))
 	viper.SetDefault("rpc.disableAuth", false)
+	viper.SetDefault("rpc.rateLimit", 0)
+	viper.SetDefault("rpc.rateBurst", 0)
+	viper.SetDefault("rpc.auditLogMaxAge", 0)
+	viper.SetDefault("rpc.rest", false)
+	viper.SetDefault("rpc.graphql", false)
 }
 
 func setDevDefaultConfig() {
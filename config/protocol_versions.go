@@ -1,15 +1,78 @@
 package config
 
-import "sync"
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
 
 // DefaultNetVersion is the default network
 // version used when no network version is provided.
 const DefaultNetVersion = "0001"
 
+// DefaultNetworkID is the default ID of the network
+// the client belongs to. Peers with a different
+// network ID are rejected during handshake.
+const DefaultNetworkID = "mainnet"
+
+// Message types of the wire protocol
+const (
+	MsgHandshake      = "handshake"
+	MsgPing           = "ping"
+	MsgGetAddr        = "getaddr"
+	MsgAddr           = "addr"
+	MsgTx             = "tx"
+	MsgBlockInfo      = "blockinfo"
+	MsgBlockBody      = "blockbody"
+	MsgGetBlockHashes = "getblockhashes"
+	MsgRequestBlock   = "requestblock"
+	MsgGetBlockBodies = "getblockbodies"
+	MsgCompactBlock   = "compactblock"
+	MsgTxInv          = "txinv"
+	MsgFindPeers      = "findpeers"
+)
+
+// Optional features of the wire protocol
+const (
+	// FeatureCompactBlocks indicates support
+	// for relaying blocks as CompactBlock messages
+	FeatureCompactBlocks = "compactblocks"
+
+	// FeatureTxInv indicates support for announcing
+	// transactions in batched TxInv messages
+	FeatureTxInv = "txinv"
+)
+
 var (
 	// versions contains protocol handlers versions information
 	versions *ProtocolVersions
 	cfgLck   = &sync.RWMutex{}
+
+	// msgVersions contains the versions of each message
+	// type supported by this client in ascending order.
+	// The second version of every message type frames
+	// messages with a length prefix. The first version
+	// is kept for peers that predate framing.
+	msgVersions = map[string][]uint32{
+		MsgHandshake:      {1, 2},
+		MsgPing:           {1, 2},
+		MsgGetAddr:        {1, 2},
+		MsgAddr:           {1, 2},
+		MsgTx:             {1, 2},
+		MsgBlockInfo:      {1, 2},
+		MsgBlockBody:      {1, 2},
+		MsgGetBlockHashes: {1, 2},
+		MsgRequestBlock:   {1, 2},
+		MsgGetBlockBodies: {1, 2},
+		MsgCompactBlock:   {1, 2},
+		MsgTxInv:          {1, 2},
+		MsgFindPeers:      {1, 2},
+	}
+
+	// features contains the optional
+	// features supported by this client
+	features = []string{FeatureCompactBlocks, FeatureTxInv}
 )
 
 // SetVersions sets the protocol version.
@@ -23,18 +86,17 @@ func SetVersions(netVersion string) {
 		netVersion = DefaultNetVersion
 	}
 
-	versions = &ProtocolVersions{
-		Protocol:       netVersion,
-		Handshake:      netVersion + "/handshake/1",
-		Ping:           netVersion + "/ping/1",
-		GetAddr:        netVersion + "/getaddr/1",
-		Addr:           netVersion + "/addr/1",
-		Tx:             netVersion + "/tx/1",
-		BlockBody:      netVersion + "/blockbody/1",
-		GetBlockHashes: netVersion + "/getblockhashes/1",
-		RequestBlock:   netVersion + "/requestblock/1",
-		GetBlockBodies: netVersion + "/getblockbodies/1",
-	}
+	versions = &ProtocolVersions{Protocol: netVersion}
+	versions.Handshake = versions.latestProtocolID(MsgHandshake)
+	versions.Ping = versions.latestProtocolID(MsgPing)
+	versions.GetAddr = versions.latestProtocolID(MsgGetAddr)
+	versions.Addr = versions.latestProtocolID(MsgAddr)
+	versions.Tx = versions.latestProtocolID(MsgTx)
+	versions.BlockInfo = versions.latestProtocolID(MsgBlockInfo)
+	versions.BlockBody = versions.latestProtocolID(MsgBlockBody)
+	versions.GetBlockHashes = versions.latestProtocolID(MsgGetBlockHashes)
+	versions.RequestBlock = versions.latestProtocolID(MsgRequestBlock)
+	versions.GetBlockBodies = versions.latestProtocolID(MsgGetBlockBodies)
 }
 
 // GetVersions returns the protocol version object
@@ -44,6 +106,82 @@ func GetVersions() *ProtocolVersions {
 	return versions
 }
 
+// GetMsgVersions returns the versions of each
+// message type supported by this client
+func GetMsgVersions() map[string][]uint32 {
+	cfgLck.RLock()
+	defer cfgLck.RUnlock()
+	result := make(map[string][]uint32, len(msgVersions))
+	for msgType, v := range msgVersions {
+		result[msgType] = append([]uint32{}, v...)
+	}
+	return result
+}
+
+// GetFeatures returns the optional
+// features supported by this client
+func GetFeatures() []string {
+	cfgLck.RLock()
+	defer cfgLck.RUnlock()
+	return append([]string{}, features...)
+}
+
+// NegotiateMsgVersions selects the highest version of each
+// message type supported by both this client and a remote
+// peer. A peer that advertises no versions is assumed to
+// support only the first version of every message type.
+// Message types unknown to the remote peer are left out.
+// It returns an error if both support a message type but
+// share no version of it.
+func NegotiateMsgVersions(remote map[string][]uint32) (map[string]uint32, error) {
+
+	local := GetMsgVersions()
+	if len(remote) == 0 {
+		remote = make(map[string][]uint32, len(local))
+		for msgType := range local {
+			remote[msgType] = []uint32{1}
+		}
+	}
+
+	selected := make(map[string]uint32)
+	for msgType, localVersions := range local {
+		remoteVersions, ok := remote[msgType]
+		if !ok {
+			continue
+		}
+		for _, lv := range localVersions {
+			for _, rv := range remoteVersions {
+				if lv == rv && lv > selected[msgType] {
+					selected[msgType] = lv
+				}
+			}
+		}
+		if selected[msgType] == 0 {
+			return nil, fmt.Errorf("no common version for '%s' messages", msgType)
+		}
+	}
+
+	if _, ok := selected[MsgHandshake]; !ok {
+		return nil, fmt.Errorf("no common version for '%s' messages", MsgHandshake)
+	}
+
+	return selected, nil
+}
+
+// NegotiateFeatures returns the features
+// supported by this client and a remote peer
+func NegotiateFeatures(remote []string) (common []string) {
+	for _, f := range GetFeatures() {
+		for _, rf := range remote {
+			if f == rf {
+				common = append(common, f)
+				break
+			}
+		}
+	}
+	return
+}
+
 func init() {
 	SetVersions("")
 }
@@ -84,3 +222,39 @@ type ProtocolVersions struct {
 	// GetBlockBodies is the message version for handling wire.GetBlockBodies messages
 	GetBlockBodies string
 }
+
+// ProtocolID returns the protocol ID of
+// a version of a message type
+func (v *ProtocolVersions) ProtocolID(msgType string, version uint32) string {
+	return fmt.Sprintf("%s/%s/%d", v.Protocol, msgType, version)
+}
+
+// latestProtocolID returns the protocol ID of the
+// latest version of a message type
+func (v *ProtocolVersions) latestProtocolID(msgType string) string {
+	versions := msgVersions[msgType]
+	return v.ProtocolID(msgType, versions[len(versions)-1])
+}
+
+// ProtocolIDs returns the protocol IDs of the versions
+// of a message type supported by this client, starting
+// with the latest version
+func (v *ProtocolVersions) ProtocolIDs(msgType string) []string {
+	versions := GetMsgVersions()[msgType]
+	ids := make([]string, 0, len(versions))
+	for i := len(versions) - 1; i >= 0; i-- {
+		ids = append(ids, v.ProtocolID(msgType, versions[i]))
+	}
+	return ids
+}
+
+// ParseProtocolID returns the message type
+// and version of a protocol ID
+func ParseProtocolID(id string) (msgType string, version uint32) {
+	parts := strings.Split(id, "/")
+	if len(parts) != 3 {
+		return "", 0
+	}
+	v, _ := strconv.ParseUint(parts[2], 10, 32)
+	return parts[1], uint32(v)
+}
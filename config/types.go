@@ -25,6 +25,10 @@ type NodeConfig struct {
 	// Mode determines the current environment type
 	Mode int `json:"mode" mapstructure:"mode"`
 
+	// NetworkID is the ID of the network the node belongs
+	// to. Peers of a different network are rejected.
+	NetworkID string `json:"networkId" mapstructure:"networkId"`
+
 	// GetAddrInterval is the interval between GetAddr messages
 	GetAddrInterval int64 `json:"getAddrInt" mapstructure:"getAddrInt"`
 
@@ -58,6 +62,14 @@ type NodeConfig struct {
 
 	// Account is the coinbase account
 	Account string `json:"account" mapstructure:"account"`
+
+	// EnableDHT enables the discovery of peers
+	// through lookups in the distributed hash table
+	EnableDHT bool `json:"dht" mapstructure:"dht"`
+
+	// EnableMDNS enables the discovery of
+	// peers on the local network using mDNS
+	EnableMDNS bool `json:"mdns" mapstructure:"mdns"`
 }
 
 // RPCConfig defines configuration for the RPC component
@@ -71,16 +83,98 @@ type RPCConfig struct {
 	// Username is the RPC username
 	Username string `json:"username" mapstructure:"username"`
 
-	// Password is the RPC password
-	Password string `json:"password" mapstructure:"password"`
+	// Password is the RPC password. It is
+	// never included in the JSON encoding.
+	Password string `json:"-" mapstructure:"password"`
 
 	// SessionSecretKey is the key used to sign the
-	// session tokens. Must be kept secret.
-	SessionSecretKey string `json:"sessionSecretKey" mapstructure:"sessionSecretKey"`
+	// session tokens. Must be kept secret. It is
+	// never included in the JSON encoding.
+	SessionSecretKey string `json:"-" mapstructure:"sessionSecretKey"`
 
 	// SessionTTL is the duration a session can
 	// remain active before it is considered expired.
 	SessionTTL int64 `json:"sessionTTL" mapstructure:"sessionTTL"`
+
+	// MaxBatchSize is the maximum number of requests
+	// in a batch request. The default maximum of the
+	// JSON RPC server is used when it is zero.
+	MaxBatchSize int `json:"maxBatchSize" mapstructure:"maxBatchSize"`
+
+	// TLSCert is the path to the certificate of the
+	// server. TLS is enabled when it is set.
+	TLSCert string `json:"tlsCert" mapstructure:"tlsCert"`
+
+	// TLSKey is the path to the private
+	// key of the server certificate
+	TLSKey string `json:"tlsKey" mapstructure:"tlsKey"`
+
+	// TLSClientCA is the path to the CA certificates
+	// used to verify client certificates. Clients must
+	// present a valid certificate when it is set.
+	TLSClientCA string `json:"tlsClientCA" mapstructure:"tlsClientCA"`
+
+	// TLSConsoleCert is the path to the client
+	// certificate the embedded console presents
+	// to the server. Required when TLSClientCA is set.
+	TLSConsoleCert string `json:"tlsConsoleCert" mapstructure:"tlsConsoleCert"`
+
+	// TLSConsoleKey is the path to the private
+	// key of the console client certificate
+	TLSConsoleKey string `json:"tlsConsoleKey" mapstructure:"tlsConsoleKey"`
+
+	// Users are RPC users whose role determine
+	// the private methods they can call
+	Users []*RPCUser `json:"users" mapstructure:"users"`
+
+	// RateLimit is the cost of the requests a client
+	// can make per second. Clients are identified by
+	// the subject of their session token or their IP
+	// address. Rate limiting is disabled when zero.
+	RateLimit float64 `json:"rateLimit" mapstructure:"rateLimit"`
+
+	// RateBurst is the maximum cost of the requests a
+	// client can make at once. Defaults to the cost
+	// allowed in a second when zero.
+	RateBurst int `json:"rateBurst" mapstructure:"rateBurst"`
+
+	// MethodCosts overrides the cost of methods. The
+	// default cost of a method is set by its API.
+	MethodCosts map[string]float64 `json:"methodCosts" mapstructure:"methodCosts"`
+
+	// AuditLogMaxAge is the number of days rotated
+	// audit logs are kept. They are never deleted
+	// when zero.
+	AuditLogMaxAge int `json:"auditLogMaxAge" mapstructure:"auditLogMaxAge"`
+
+	// EnableREST enables the read-only REST gateway
+	EnableREST bool `json:"rest" mapstructure:"rest"`
+
+	// EnableGraphQL enables the GraphQL endpoint
+	EnableGraphQL bool `json:"graphql" mapstructure:"graphql"`
+
+	// GraphQLMaxDepth is the maximum depth of
+	// GraphQL queries. A default is used when zero.
+	GraphQLMaxDepth int `json:"graphqlMaxDepth" mapstructure:"graphqlMaxDepth"`
+
+	// GraphQLMaxComplexity is the maximum complexity
+	// of GraphQL queries. A default is used when zero.
+	GraphQLMaxComplexity int `json:"graphqlMaxComplexity" mapstructure:"graphqlMaxComplexity"`
+}
+
+// RPCUser describes an RPC user
+type RPCUser struct {
+
+	// Username is the username of the user
+	Username string `json:"username" mapstructure:"username"`
+
+	// Password is the password of the user. It
+	// is never included in the JSON encoding.
+	Password string `json:"-" mapstructure:"password"`
+
+	// Role is the role of the user (read-only,
+	// wallet, miner-operator or admin)
+	Role string `json:"role" mapstructure:"role"`
 }
 
 // TxPoolConfig defines configuration for the transaction pool
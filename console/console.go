@@ -5,6 +5,7 @@
 package console
 
 import (
+	"crypto/tls"
 	"fmt"
 	"io/ioutil"
 	"path"
@@ -61,6 +62,10 @@ type Console struct {
 
 	confirmedStop bool
 
+	// rpcTLSConfig is the TLS configuration
+	// used to dial a secured RPC server
+	rpcTLSConfig *tls.Config
+
 	// onStopFunc is called when the
 	// console exists. Console caller
 	// use this to perform clean up etc
@@ -112,11 +117,23 @@ func (c *Console) SetRPCServerAddr(addr string, secured bool) {
 	defer c.Unlock()
 	c.executor.rpc = &RPCConfig{
 		Client: RPCClient{
-			Address: makeAddr(addr, secured),
+			Address:   makeAddr(addr, secured),
+			TLSConfig: c.rpcTLSConfig,
 		},
 	}
 }
 
+// SetRPCTLSConfig sets the TLS configuration
+// used to dial a secured RPC server
+func (c *Console) SetRPCTLSConfig(tlsConfig *tls.Config) {
+	c.Lock()
+	defer c.Unlock()
+	c.rpcTLSConfig = tlsConfig
+	if c.executor.rpc != nil {
+		c.executor.rpc.Client.SetTLSConfig(tlsConfig)
+	}
+}
+
 // SetRPCServer sets the rpc server
 // so we can start and stop it.
 // It will panic if this is called on
@@ -159,6 +159,9 @@ func (e *Executor) PrepareContext() ([]prompt.Suggest, error) {
 	nsObj["_system"]["balance"] = func() *TxBalanceBuilder {
 		return NewTxBuilder(e).Balance()
 	}
+	nsObj["_system"]["multiTransfer"] = func() *TxBalanceBuilder {
+		return NewTxBuilder(e).MultiTransfer()
+	}
 
 	defer func() {
 		for ns, objs := range nsObj {
@@ -186,6 +189,8 @@ func (e *Executor) PrepareContext() ([]prompt.Suggest, error) {
 		Description: "List accounts on this node"})
 	suggestions = append(suggestions, prompt.Suggest{Text: "ell.balance",
 		Description: "Create and send a balance transaction"})
+	suggestions = append(suggestions, prompt.Suggest{Text: "ell.multiTransfer",
+		Description: "Create and send a transaction that pays multiple recipients"})
 
 	// If the console is not in attach mode and
 	// the rpc server is not started, we cannot
@@ -205,6 +210,13 @@ func (e *Executor) PrepareContext() ([]prompt.Suggest, error) {
 	for _, r := range resp.Result.([]interface{}) {
 		var mInfo jsonrpc.MethodInfo
 		util.MapDecode(r, &mInfo)
+
+		// Streaming methods cannot be called
+		// through the request/response client
+		if mInfo.Stream {
+			continue
+		}
+
 		methodInfoParts := strings.Split(mInfo.Name, "_")
 		mName := methodInfoParts[1]
 		ns := methodInfoParts[0]
@@ -2,6 +2,7 @@ package console
 
 import (
 	"bytes"
+	"crypto/tls"
 	gojson "encoding/json"
 	"fmt"
 	"net/http"
@@ -15,6 +16,39 @@ import (
 // call methods of an rpc server.
 type RPCClient struct {
 	Address string
+
+	// TLSConfig is the TLS configuration used
+	// when the server address is secured
+	TLSConfig *tls.Config
+
+	// client is the http client used to send
+	// requests. It is created on first use.
+	client *http.Client
+}
+
+// SetTLSConfig sets the TLS configuration and
+// discards the http client created with the
+// previous configuration
+func (c *RPCClient) SetTLSConfig(tlsConfig *tls.Config) {
+	c.TLSConfig = tlsConfig
+	c.client = nil
+}
+
+// httpClient returns the http client, creating
+// it if it has not been created. The client is
+// reused so that connections are kept alive.
+func (c *RPCClient) httpClient() *http.Client {
+	if c.client == nil {
+		c.client = &http.Client{
+			Transport: &http.Transport{
+				Proxy:               http.ProxyFromEnvironment,
+				TLSClientConfig:     c.TLSConfig,
+				IdleConnTimeout:     90 * time.Second,
+				TLSHandshakeTimeout: 10 * time.Second,
+			},
+		}
+	}
+	return c.client
 }
 
 // RPCClientError creates an error describing
@@ -41,11 +75,11 @@ func (c *RPCClient) call(method string, params interface{}, authToken string) (*
 
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+authToken)
-	client := new(http.Client)
-	resp, err := client.Do(req)
+	resp, err := c.httpClient().Do(req)
 	if err != nil {
 		return nil, RPCClientError(err.Error())
 	}
+	defer resp.Body.Close()
 
 	// decode the result into a jsonrpc.Response
 	var result jsonrpc.Response
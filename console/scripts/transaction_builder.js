@@ -50,6 +50,21 @@ TxBalanceBuilder.prototype.fee = function(amount) {
 	return this;
 };
 
+TxBalanceBuilder.prototype.output = function(addr, amount) {
+	this.builder.Output(addr, amount);
+	return this;
+};
+
+TxBalanceBuilder.prototype.validHeights = function(notBefore, notAfter) {
+	this.builder.ValidHeights(notBefore || 0, notAfter || 0);
+	return this;
+};
+
+TxBalanceBuilder.prototype.validTimes = function(notBefore, notAfter) {
+	this.builder.ValidTimes(notBefore || 0, notAfter || 0);
+	return this;
+};
+
 TxBalanceBuilder.prototype.reset = function() {
 	this.builder.Reset();
 	return this;
@@ -59,3 +74,7 @@ TxBalanceBuilder.prototype.reset = function() {
 ell["balance"] = function() {
 	return new TxBalanceBuilder(_system.balance());
 };
+
+ell["multiTransfer"] = function() {
+	return new TxBalanceBuilder(_system.multiTransfer());
+};
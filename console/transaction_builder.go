@@ -9,8 +9,14 @@ import (
 
 	"github.com/ellcrys/elld/types/core"
 	"github.com/ellcrys/elld/util"
+	"github.com/shopspring/decimal"
 )
 
+// defaultFeeTargetBlocks is the number of blocks within
+// which a transaction whose fee was not set is expected
+// to be included.
+const defaultFeeTargetBlocks = 2
+
 // TxBuilder provides methods for building
 // and executing a transaction
 type TxBuilder struct {
@@ -49,6 +55,17 @@ func (o *TxBuilder) Balance() *TxBalanceBuilder {
 	}
 }
 
+// MultiTransfer creates a builder for a transaction
+// that pays multiple recipients. Recipients are
+// added using TxBalanceBuilder.Output
+func (o *TxBuilder) MultiTransfer() *TxBalanceBuilder {
+	b := o.Balance()
+	b.data["type"] = core.TxTypeMultiTransfer
+	b.data["outputs"] = []map[string]interface{}{}
+	b.data["value"] = "0"
+	return b
+}
+
 // Payload returns the transaction being built.
 // If finalize is true, the builder attempts
 // to compute the hash, sign and other fields
@@ -106,6 +123,12 @@ sign:
 	// Set the timestamp
 	o.data["timestamp"] = time.Now().Unix()
 
+	// If the fee has not been set, use the
+	// fee recommended by the fee estimator
+	if o.data["fee"] == nil {
+		o.setRecommendedFee()
+	}
+
 	// marshal into core.Transaction
 	var tx core.Transaction
 	_ = util.MapDecode(o.data, &tx)
@@ -124,6 +147,35 @@ sign:
 	return o.data
 }
 
+// setRecommendedFee requests the recommended fee
+// for the size of the transaction and sets it.
+func (o *TxBalanceBuilder) setRecommendedFee() {
+
+	// The signature is part of the size of the transaction,
+	// so we compute the hash and sign the fee-less transaction
+	// to determine the size. The size of the signature does
+	// not depend on the fee.
+	var tx core.Transaction
+	_ = util.MapDecode(o.data, &tx)
+	tx.Hash = tx.ComputeHash()
+	tx.Sig, _ = core.TxSign(&tx, o.e.coinbase.PrivKey().Base58())
+
+	result, err := o.e.callRPCMethod("ell_estimateFee", map[string]interface{}{
+		"targetBlocks": defaultFeeTargetBlocks,
+		"size":         tx.GetSizeNoFee(),
+	})
+	if err != nil {
+		panic(o.e.vm.MakeCustomError("BuilderError", err.Error()))
+	}
+
+	if result["error"] != nil {
+		errMsg := result["error"].(map[string]interface{})["message"].(string)
+		panic(o.e.vm.MakeCustomError("BuilderError", "failed to estimate fee: "+errMsg))
+	}
+
+	o.data["fee"] = result["result"].(map[string]interface{})["fee"].(string)
+}
+
 // Packed returns a base58check encode
 // equivalent of the signed payload.
 func (o *TxBalanceBuilder) Packed() string {
@@ -181,6 +233,57 @@ func (o *TxBalanceBuilder) Fee(amount string) *TxBalanceBuilder {
 	return o
 }
 
+// Output adds a recipient to a multi-recipient transfer
+// and adds the amount to the value of the transaction
+func (o *TxBalanceBuilder) Output(address, amount string) *TxBalanceBuilder {
+
+	outputs, ok := o.data["outputs"].([]map[string]interface{})
+	if !ok {
+		panic(o.e.vm.MakeCustomError("BuilderError",
+			"outputs are only allowed in multi-recipient transfers"))
+	}
+
+	value, err := decimal.NewFromString(amount)
+	if err != nil {
+		panic(o.e.vm.MakeCustomError("BuilderError", "output amount is not a valid number"))
+	}
+
+	total := util.String(o.data["value"].(string)).Decimal().Add(value)
+	o.data["value"] = total.String()
+	o.data["outputs"] = append(outputs, map[string]interface{}{
+		"to":    address,
+		"value": amount,
+	})
+	return o
+}
+
+// validity returns the validity window
+// of the transaction, creating it if unset
+func (o *TxBalanceBuilder) validity() map[string]interface{} {
+	if o.data["validity"] == nil {
+		o.data["validity"] = map[string]interface{}{}
+	}
+	return o.data["validity"].(map[string]interface{})
+}
+
+// ValidHeights sets the block heights within
+// which the transaction can be included.
+// A zero value leaves the bound unset.
+func (o *TxBalanceBuilder) ValidHeights(notBefore, notAfter uint64) *TxBalanceBuilder {
+	o.validity()["notBeforeHeight"] = notBefore
+	o.validity()["notAfterHeight"] = notAfter
+	return o
+}
+
+// ValidTimes sets the block timestamps within
+// which the transaction can be included.
+// A zero value leaves the bound unset.
+func (o *TxBalanceBuilder) ValidTimes(notBefore, notAfter int64) *TxBalanceBuilder {
+	o.validity()["notBeforeTime"] = notBefore
+	o.validity()["notAfterTime"] = notAfter
+	return o
+}
+
 // Reset the builder
 func (o *TxBalanceBuilder) Reset() {
 	o.data = make(map[string]interface{})
@@ -28,6 +28,7 @@ func (m *Miner) APIs() jsonrpc.APISet {
 		"start": {
 			Namespace:   types.NamespaceMiner,
 			Description: "Start the CPU miner",
+			Result:      jsonrpc.Any("True or the reason the miner was not started"),
 			Private:     true,
 			Func: func(arg interface{}) *jsonrpc.Response {
 
@@ -44,6 +45,7 @@ func (m *Miner) APIs() jsonrpc.APISet {
 		"stop": {
 			Namespace:   types.NamespaceMiner,
 			Description: "Stop the CPU miner",
+			Result:      jsonrpc.Boolean("Always true"),
 			Private:     true,
 			Func: func(params interface{}) *jsonrpc.Response {
 				m.Stop()
@@ -53,6 +55,7 @@ func (m *Miner) APIs() jsonrpc.APISet {
 		"isMining": {
 			Namespace:   types.NamespaceMiner,
 			Description: "Check miner status",
+			Result:      jsonrpc.Boolean("Whether the miner is running"),
 			Func: func(params interface{}) *jsonrpc.Response {
 				return jsonrpc.Success(m.isMining())
 			},
@@ -60,6 +63,7 @@ func (m *Miner) APIs() jsonrpc.APISet {
 		"getHashrate": {
 			Namespace:   types.NamespaceMiner,
 			Description: "Get current hashrate",
+			Result:      jsonrpc.Number("The hashrate"),
 			Func: func(params interface{}) *jsonrpc.Response {
 				return jsonrpc.Success(m.getHashrate())
 			},
@@ -67,6 +71,7 @@ func (m *Miner) APIs() jsonrpc.APISet {
 		"numThreads": {
 			Namespace:   types.NamespaceMiner,
 			Description: "Get the number of miner threads",
+			Result:      jsonrpc.Integer("The number of miner threads"),
 			Func: func(params interface{}) *jsonrpc.Response {
 				return jsonrpc.Success(m.numThreads)
 			},
@@ -75,6 +80,9 @@ func (m *Miner) APIs() jsonrpc.APISet {
 			Private:     true,
 			Namespace:   types.NamespaceMiner,
 			Description: "Set the number of miner threads",
+			Params:      jsonrpc.Integer("The number of miner threads"),
+			Result:      jsonrpc.Boolean("Always true"),
+			Errors:      []*jsonrpc.Err{rpc.ErrDescArgType},
 			Func:        m.apiSetThreads,
 		},
 	}
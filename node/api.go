@@ -5,10 +5,12 @@ import (
 	"encoding/json"
 	"runtime"
 	"strings"
+	"time"
 
 	"github.com/btcsuite/btcutil/base58"
 	"github.com/ellcrys/elld/config"
 
+	"github.com/ellcrys/elld/node/peermanager"
 	"github.com/ellcrys/elld/rpc"
 	"github.com/ellcrys/elld/rpc/jsonrpc"
 	"github.com/ellcrys/elld/types"
@@ -267,6 +269,91 @@ func (n *Node) apiGetActivePeers(arg interface{}) *jsonrpc.Response {
 	return jsonrpc.Success(peers)
 }
 
+// apiGetPeerScores fetches the misbehavior scores of peers
+func (n *Node) apiGetPeerScores(arg interface{}) *jsonrpc.Response {
+	var scores = []map[string]interface{}{}
+	for _, ps := range n.peerManager.GetPeerScores() {
+		scores = append(scores, map[string]interface{}{
+			"id":         ps.PeerID,
+			"score":      ps.Score,
+			"lastReason": ps.LastReason,
+			"numBans":    ps.NumBans,
+			"banEndTime": ps.BanEndTime,
+		})
+	}
+	return jsonrpc.Success(scores)
+}
+
+// apiBanPeer adds a peer ID, IP or subnet to the ban list.
+// The ban duration is in seconds; A zero or absent
+// duration creates a permanent ban.
+func (n *Node) apiBanPeer(arg interface{}) *jsonrpc.Response {
+
+	var target, reason string
+	var duration float64
+	switch v := arg.(type) {
+	case string:
+		target = v
+	case map[string]interface{}:
+		var ok bool
+		if target, ok = v["target"].(string); !ok {
+			return jsonrpc.Error(types.ErrCodeUnexpectedArgType,
+				"target is required and must be a string", nil)
+		}
+		if v["duration"] != nil {
+			if duration, ok = v["duration"].(float64); !ok {
+				return jsonrpc.Error(types.ErrCodeUnexpectedArgType,
+					"duration must be a number", nil)
+			}
+		}
+		if v["reason"] != nil {
+			if reason, ok = v["reason"].(string); !ok {
+				return jsonrpc.Error(types.ErrCodeUnexpectedArgType,
+					"reason must be a string", nil)
+			}
+		}
+	default:
+		return jsonrpc.Error(types.ErrCodeUnexpectedArgType,
+			rpc.ErrMethodArgType("String or JSON").Error(), nil)
+	}
+
+	if duration < 0 {
+		return jsonrpc.Error(types.ErrCodeQueryParamError,
+			"duration must not be negative", nil)
+	}
+
+	ban, err := n.peerManager.BanPeer(target,
+		time.Duration(duration)*time.Second, reason)
+	if err != nil {
+		return jsonrpc.Error(types.ErrCodeQueryParamError, err.Error(), nil)
+	}
+
+	return jsonrpc.Success(ban)
+}
+
+// apiUnbanPeer removes a peer ID, IP or subnet from the ban list
+func (n *Node) apiUnbanPeer(arg interface{}) *jsonrpc.Response {
+
+	target, ok := arg.(string)
+	if !ok {
+		return jsonrpc.Error(types.ErrCodeUnexpectedArgType,
+			rpc.ErrMethodArgType("String").Error(), nil)
+	}
+
+	if err := n.peerManager.UnbanPeer(target); err != nil {
+		return jsonrpc.Error(types.ErrCodeQueryParamError, err.Error(), nil)
+	}
+
+	return jsonrpc.Success(true)
+}
+
+// apiListBans fetches the active entries of the ban list
+func (n *Node) apiListBans(arg interface{}) *jsonrpc.Response {
+	var bans = []*peermanager.Ban{}
+	bans = append(bans, n.peerManager.GetBans()...)
+	return jsonrpc.Success(bans)
+}
+
 // apiGetPeers fetches all peers
 func (n *Node) apiGetPeers(arg interface{}) *jsonrpc.Response {
 	var peers = []map[string]interface{}{}
@@ -346,7 +433,8 @@ func (n *Node) processTx(txData map[string]interface{}) *jsonrpc.Response {
 	}
 
 	// Attempt to add the transaction to the pool
-	if err := n.txManager.AddTx(&tx); err != nil {
+	// as a local transaction
+	if err := n.txManager.AddLocalTx(&tx); err != nil {
 		return jsonrpc.Error(types.ErrCodeTxFailed, err.Error(), nil)
 	}
 
@@ -401,6 +489,59 @@ func (n *Node) apiSend(arg interface{}) *jsonrpc.Response {
 	return n.processTx(txData)
 }
 
+// apiEstimateFee returns the recommended fee rate
+// for a transaction to be included within a target
+// number of blocks. It accepts the target number of
+// blocks or a JSON object with `targetBlocks` and an
+// optional transaction `size` for which the
+// recommended fee is also computed.
+func (n *Node) apiEstimateFee(arg interface{}) *jsonrpc.Response {
+
+	if n.feeEstimator == nil {
+		return jsonrpc.Error(types.ErrCodeUnexpected,
+			"fee estimator is not available", nil)
+	}
+
+	var targetBlocks, size float64
+	switch v := arg.(type) {
+	case float64:
+		targetBlocks = v
+	case map[string]interface{}:
+		var ok bool
+		if targetBlocks, ok = v["targetBlocks"].(float64); !ok {
+			return jsonrpc.Error(types.ErrCodeUnexpectedArgType,
+				"targetBlocks is required and must be a number", nil)
+		}
+		if v["size"] != nil {
+			if size, ok = v["size"].(float64); !ok {
+				return jsonrpc.Error(types.ErrCodeUnexpectedArgType,
+					"size must be a number", nil)
+			}
+		}
+	default:
+		return jsonrpc.Error(types.ErrCodeUnexpectedArgType,
+			rpc.ErrMethodArgType("Number or JSON").Error(), nil)
+	}
+
+	if targetBlocks < 1 {
+		return jsonrpc.Error(types.ErrCodeQueryParamError,
+			"targetBlocks must be at least 1", nil)
+	}
+
+	feeRate := n.feeEstimator.EstimateFeeRate(int(targetBlocks))
+	result := map[string]interface{}{
+		"targetBlocks": int(targetBlocks),
+		"feeRate":      feeRate.String(),
+	}
+
+	if size > 0 {
+		result["size"] = int64(size)
+		result["fee"] = n.feeEstimator.EstimateFee(int(targetBlocks), int64(size)).String()
+	}
+
+	return jsonrpc.Success(result)
+}
+
 // apiFetchPool fetches transactions currently in the pool
 func (n *Node) apiFetchPool(arg interface{}) *jsonrpc.Response {
 	var txs = []types.Transaction{}
@@ -411,6 +552,70 @@ func (n *Node) apiFetchPool(arg interface{}) *jsonrpc.Response {
 	return jsonrpc.Success(txs)
 }
 
+// apiGetLocalTxs fetches local transactions currently in the pool
+func (n *Node) apiGetLocalTxs(arg interface{}) *jsonrpc.Response {
+	var txs = []types.Transaction{}
+	txs = append(txs, n.txManager.GetLocalTxs()...)
+	return jsonrpc.Success(txs)
+}
+
+// apiCancelLocalTx removes a local transaction from
+// the pool and stops it from being rebroadcast
+func (n *Node) apiCancelLocalTx(arg interface{}) *jsonrpc.Response {
+
+	txHash, ok := arg.(string)
+	if !ok {
+		return jsonrpc.Error(types.ErrCodeUnexpectedArgType,
+			rpc.ErrMethodArgType("String").Error(), nil)
+	}
+
+	if err := n.txManager.CancelLocalTx(txHash); err != nil {
+		return jsonrpc.Error(types.ErrCodeTransactionNotFound, err.Error(), nil)
+	}
+
+	return jsonrpc.Success(true)
+}
+
+// apiPoolSubscribe streams transaction pool events.
+// It optionally accepts a list of event types
+// (added, evicted, mined, expired) to
+// restrict the events that are sent.
+func (n *Node) apiPoolSubscribe(arg interface{}, send func(interface{}) error,
+	done <-chan struct{}) error {
+
+	var filter = map[string]struct{}{}
+	if arg != nil {
+		eventTypes, ok := arg.([]interface{})
+		if !ok {
+			return rpc.ErrMethodArgType("Array{String}")
+		}
+		for _, t := range eventTypes {
+			eventType, ok := t.(string)
+			if !ok {
+				return rpc.ErrMethodArgType("Array{String}")
+			}
+			filter[eventType] = struct{}{}
+		}
+	}
+
+	id, events := n.txManager.Feed().Subscribe()
+	defer n.txManager.Feed().Unsubscribe(id)
+
+	for {
+		select {
+		case <-done:
+			return nil
+		case evt := <-events:
+			if _, ok := filter[evt.Type]; len(filter) > 0 && !ok {
+				continue
+			}
+			if err := send(evt); err != nil {
+				return err
+			}
+		}
+	}
+}
+
 func (n *Node) apiBroadcastPeers(arg interface{}) *jsonrpc.Response {
 	var result = map[string][]string{
 		"broadcasters":       {},
@@ -433,6 +638,58 @@ func (n *Node) apiNoNetwork(arg interface{}) *jsonrpc.Response {
 	return jsonrpc.Success(true)
 }
 
+// apiSendParams describes the parameters of send
+var apiSendParams = jsonrpc.Object("The transaction", map[string]*jsonrpc.Schema{
+	"type":         jsonrpc.Integer("The type of the transaction"),
+	"nonce":        jsonrpc.Integer("The nonce of the sender account"),
+	"to":           jsonrpc.String("The address of the recipient").Opt(),
+	"from":         jsonrpc.String("The address of the sender"),
+	"senderPubKey": jsonrpc.String("The public key of the sender"),
+	"value":        jsonrpc.String("The amount to send"),
+	"fee":          jsonrpc.String("The fee to pay"),
+	"timestamp":    jsonrpc.Integer("The unix time of creation"),
+	"hash":         jsonrpc.String("The hash of the transaction").Opt(),
+	"sig":          jsonrpc.String("The signature of the transaction").Opt(),
+	"outputs":      jsonrpc.Array("The outputs of a multi-transfer transaction", nil).Opt(),
+	"validity":     jsonrpc.Object("The validity conditions of the transaction", nil).Opt(),
+})
+
+// apiEstimateFeeParams describes the parameters of estimateFee
+var apiEstimateFeeParams = jsonrpc.OneOf("The target number of blocks or the options",
+	jsonrpc.Integer("The number of blocks"),
+	jsonrpc.Object("The options", map[string]*jsonrpc.Schema{
+		"targetBlocks": jsonrpc.Integer("The number of blocks"),
+		"size":         jsonrpc.Integer("The size of the transaction").Opt(),
+	}))
+
+// apiStatsResult describes the result of stats
+var apiStatsResult = jsonrpc.Object("The number of connections", map[string]*jsonrpc.Schema{
+	"total":    jsonrpc.Integer("The number of connections"),
+	"inbound":  jsonrpc.Integer("The number of inbound connections"),
+	"outbound": jsonrpc.Integer("The number of outbound connections"),
+})
+
+// apiBanPeerParams describes the parameters of banPeer
+var apiBanPeerParams = jsonrpc.OneOf("The target or the options",
+	jsonrpc.String("The peer ID, IP or subnet to ban"),
+	jsonrpc.Object("The options", map[string]*jsonrpc.Schema{
+		"target":   jsonrpc.String("The peer ID, IP or subnet to ban"),
+		"duration": jsonrpc.Number("The duration of the ban in seconds").Opt(),
+		"reason":   jsonrpc.String("The reason for the ban").Opt(),
+	}))
+
+// apiBroadcastersResult describes the result of broadcasters
+var apiBroadcastersResult = jsonrpc.Object("The addresses of the broadcast peers", map[string]*jsonrpc.Schema{
+	"broadcasters":       jsonrpc.Array("The broadcasters", jsonrpc.String("An address")),
+	"randomBroadcasters": jsonrpc.Array("The random broadcasters", jsonrpc.String("An address")),
+})
+
+// apiGetSizeResult describes the result of getSize
+var apiGetSizeResult = jsonrpc.Object("The size of the pool", map[string]*jsonrpc.Schema{
+	"byteSize": jsonrpc.Integer("The size of the transactions in bytes"),
+	"numTxs":   jsonrpc.Integer("The number of transactions"),
+})
+
 // APIs returns all API handlers
 func (n *Node) APIs() jsonrpc.APISet {
 	return map[string]jsonrpc.APIInfo{
@@ -441,6 +698,7 @@ func (n *Node) APIs() jsonrpc.APISet {
 		"debug": {
 			Namespace:   types.NamespaceLogger,
 			Description: "Set log level to DEBUG",
+			Result:      jsonrpc.Boolean("Always true"),
 			Func: func(arg interface{}) *jsonrpc.Response {
 				n.log.SetToDebug()
 				return jsonrpc.Success(true)
@@ -450,6 +708,7 @@ func (n *Node) APIs() jsonrpc.APISet {
 		"default": {
 			Namespace:   types.NamespaceLogger,
 			Description: "Set log level to the default (INFO)",
+			Result:      jsonrpc.Boolean("Always true"),
 			Func: func(arg interface{}) *jsonrpc.Response {
 				n.log.SetToInfo()
 				return jsonrpc.Success(true)
@@ -460,45 +719,55 @@ func (n *Node) APIs() jsonrpc.APISet {
 		"config": {
 			Namespace:   types.NamespaceNode,
 			Description: "Get node configurations",
+			Result:      jsonrpc.Object("The configuration of the node", nil),
 			Private:     true,
 			Func:        n.apiGetConfig,
 		},
 		"info": {
 			Namespace:   types.NamespaceNode,
 			Description: "Get basic information of the node",
+			Result:      jsonrpc.Object("Information about the node", nil),
 			Private:     true,
+			Errors:      []*jsonrpc.Err{rpc.ErrDescBlockQuery},
 			Func:        n.apiBasicNodeInfo,
 		},
 		"basic": {
 			Namespace:   types.NamespaceNode,
 			Description: "Get basic public information of the node",
+			Result:      jsonrpc.Object("Public information about the node", nil),
+			Errors:      []*jsonrpc.Err{rpc.ErrDescBlockQuery},
 			Func:        n.apiBasicPublicNodeInfo,
 		},
 		"isSyncing": {
 			Namespace:   types.NamespaceNode,
 			Description: "Check whether blockchain synchronization is active",
+			Result:      jsonrpc.Boolean("Whether synchronization is active"),
 			Func:        n.apiIsSyncing,
 		},
 		"getSyncStat": {
 			Namespace:   types.NamespaceNode,
 			Description: "Get blockchain synchronization statistic",
+			Result:      jsonrpc.Object("The synchronization progress or null when not synchronizing", nil),
 			Func:        n.apiGetSyncStat,
 		},
 		"enableSync": {
 			Namespace:   types.NamespaceNode,
 			Private:     true,
 			Description: "Enable block synchronization",
+			Result:      jsonrpc.Boolean("Always true"),
 			Func:        n.apiSyncEnable,
 		},
 		"disableSync": {
 			Namespace:   types.NamespaceNode,
 			Private:     true,
 			Description: "Disable block synchronization",
+			Result:      jsonrpc.Boolean("Always true"),
 			Func:        n.apiSyncDisabled,
 		},
 		"isSyncEnabled": {
 			Namespace:   types.NamespaceNode,
 			Description: "Returns whether synchronization is enabled",
+			Result:      jsonrpc.Boolean("Whether synchronization is enabled"),
 			Func:        n.apiIsSyncEnabled,
 		},
 
@@ -506,58 +775,115 @@ func (n *Node) APIs() jsonrpc.APISet {
 		"send": {
 			Namespace:   types.NamespaceEll,
 			Description: "Send a balance transaction",
+			Params:      apiSendParams,
+			Result:      jsonrpc.Object("The ID of the transaction", map[string]*jsonrpc.Schema{"id": jsonrpc.String("The ID")}),
 			Private:     true,
+			Errors:      []*jsonrpc.Err{rpc.ErrDescArgType, rpc.ErrDescTxFailed},
 			Func:        n.apiSend,
 		},
 		"sendRaw": {
 			Namespace:   types.NamespaceEll,
 			Description: "Send a base58 encoded balance transaction",
+			Params:      jsonrpc.String("The base58 encoded transaction"),
+			Result:      jsonrpc.Object("The ID of the transaction", map[string]*jsonrpc.Schema{"id": jsonrpc.String("The ID")}),
+			Errors:      []*jsonrpc.Err{rpc.ErrDescArgType, rpc.ErrDescTxFailed},
 			Func:        n.apiSendRaw,
 		},
+		"estimateFee": {
+			Namespace:   types.NamespaceEll,
+			Description: "Get a recommended fee for a transaction",
+			Params:      apiEstimateFeeParams,
+			Result:      jsonrpc.Object("The fee rate and the fee of a transaction of the given size", nil),
+			Errors:      []*jsonrpc.Err{rpc.ErrDescArgType, rpc.ErrDescQueryParam, rpc.ErrDescUnexpected},
+			Func:        n.apiEstimateFee,
+		},
 
 		// namespace: "net"
 		"join": {
 			Namespace:   types.NamespaceNet,
 			Description: "Connect to a peer",
+			Params:      jsonrpc.OneOf("The address or addresses of the peers", jsonrpc.String("An address"), jsonrpc.Array("Addresses", jsonrpc.String("An address"))),
+			Result:      jsonrpc.Boolean("Always true"),
 			Private:     true,
+			Errors:      []*jsonrpc.Err{rpc.ErrDescArgType, rpc.ErrDescAddress},
 			Func:        n.apiJoin,
 		},
 		"addPeer": {
 			Namespace:   types.NamespaceNet,
 			Description: "Add a peer address",
+			Params:      jsonrpc.OneOf("The address or addresses of the peers", jsonrpc.String("An address"), jsonrpc.Array("Addresses", jsonrpc.String("An address"))),
+			Result:      jsonrpc.Boolean("Always true"),
 			Private:     true,
+			Errors:      []*jsonrpc.Err{rpc.ErrDescArgType, rpc.ErrDescAddress},
 			Func:        n.apiAddPeer,
 		},
 		"stats": {
 			Namespace:   types.NamespaceNet,
 			Description: "Get number connections and network nodes",
+			Result:      apiStatsResult,
 			Func:        n.apiNetStats,
 		},
 		"getPeers": {
 			Namespace:   types.NamespaceNet,
 			Description: "Get a list of all peers",
+			Result:      jsonrpc.Array("The peers", jsonrpc.Object("A peer", nil)),
 			Func:        n.apiGetPeers,
 		},
 		"getActivePeers": {
 			Namespace:   types.NamespaceNet,
 			Description: "Get a list of active peers",
+			Result:      jsonrpc.Array("The peers", jsonrpc.Object("A peer", nil)),
 			Func:        n.apiGetActivePeers,
 		},
+		"getPeerScores": {
+			Namespace:   types.NamespaceNet,
+			Description: "Get the misbehavior scores of peers",
+			Result:      jsonrpc.Array("The scores", jsonrpc.Object("The score of a peer", nil)),
+			Func:        n.apiGetPeerScores,
+		},
+		"banPeer": {
+			Namespace:   types.NamespaceNet,
+			Private:     true,
+			Description: "Add a peer ID, IP or subnet to the ban list",
+			Params:      apiBanPeerParams,
+			Result:      jsonrpc.Object("The ban", nil),
+			Errors:      []*jsonrpc.Err{rpc.ErrDescArgType, rpc.ErrDescQueryParam},
+			Func:        n.apiBanPeer,
+		},
+		"unbanPeer": {
+			Namespace:   types.NamespaceNet,
+			Private:     true,
+			Description: "Remove a peer ID, IP or subnet from the ban list",
+			Params:      jsonrpc.String("The peer ID, IP or subnet to unban"),
+			Result:      jsonrpc.Boolean("Always true"),
+			Errors:      []*jsonrpc.Err{rpc.ErrDescArgType, rpc.ErrDescQueryParam},
+			Func:        n.apiUnbanPeer,
+		},
+		"listBans": {
+			Namespace:   types.NamespaceNet,
+			Description: "Get the entries of the ban list",
+			Result:      jsonrpc.Array("The bans", jsonrpc.Object("A ban", nil)),
+			Private:     true,
+			Func:        n.apiListBans,
+		},
 		"dumpPeers": {
 			Namespace:   types.NamespaceNet,
 			Private:     true,
 			Description: "Delete all peers in memory and on disk",
+			Result:      jsonrpc.Boolean("Always true"),
 			Func:        n.apiForgetPeers,
 		},
 		"broadcasters": {
 			Namespace:   types.NamespaceNet,
 			Description: "Get broadcast peers",
+			Result:      apiBroadcastersResult,
 			Func:        n.apiBroadcastPeers,
 		},
 		"noNet": {
 			Namespace:   types.NamespaceNet,
 			Private:     true,
 			Description: "Close the host connection and prevent in/out connections",
+			Result:      jsonrpc.Boolean("Always true"),
 			Func:        n.apiNoNetwork,
 		},
 
@@ -565,12 +891,36 @@ func (n *Node) APIs() jsonrpc.APISet {
 		"getSize": {
 			Namespace:   types.NamespacePool,
 			Description: "Get size information of the transaction pool",
+			Result:      apiGetSizeResult,
 			Func:        n.apiTxPoolSizeInfo,
 		},
 		"getAll": {
 			Namespace:   types.NamespacePool,
 			Description: "Get transactions in the pool",
+			Result:      jsonrpc.Array("The transactions", jsonrpc.Object("A transaction", nil)),
 			Func:        n.apiFetchPool,
 		},
+		"subscribe": {
+			Namespace:   types.NamespacePool,
+			Description: "Stream transaction pool events",
+			Params:      jsonrpc.Array("The event types to send (added, evicted, mined, expired)", jsonrpc.String("An event type")).Opt(),
+			Result:      jsonrpc.Object("A pool event", nil),
+			Stream:      n.apiPoolSubscribe,
+		},
+		"getLocal": {
+			Namespace:   types.NamespacePool,
+			Description: "Get local transactions in the pool",
+			Result:      jsonrpc.Array("The transactions", jsonrpc.Object("A transaction", nil)),
+			Func:        n.apiGetLocalTxs,
+		},
+		"cancelLocal": {
+			Namespace:   types.NamespacePool,
+			Private:     true,
+			Description: "Remove a local transaction from the pool",
+			Params:      jsonrpc.String("The hash of the transaction"),
+			Result:      jsonrpc.Boolean("Always true"),
+			Errors:      []*jsonrpc.Err{rpc.ErrDescArgType, rpc.ErrDescTxNotFound},
+			Func:        n.apiCancelLocalTx,
+		},
 	}
 }
@@ -197,6 +197,12 @@ func (bm *BlockManager) handleProcessedBlocks() error {
 	atSyncTime := pb.(*processedBlock).atSyncTime
 
 	// Remove the blocks transactions from the pool.
+	// Announce the ones that were pooled as mined.
+	for _, tx := range b.(*core.Block).GetTransactions() {
+		if bm.engine.txsPool.Has(tx) {
+			go bm.evt.Emit(core.EventTransactionMined, tx)
+		}
+	}
 	bm.engine.txsPool.Remove(b.(*core.Block).GetTransactions()...)
 
 	// Restart miner workers if the block was created
@@ -415,13 +421,24 @@ func (bm *BlockManager) sync() error {
 			"TargetChainHeight", syncStatus.TargetChainHeight,
 			"CurChainHeight", syncStatus.CurrentChainHeight,
 			"Progress(%)", syncStatus.ProgressPercent)
+
+		// Wait for the status to be delivered so that it
+		// is never received after the end of synchronization
+		<-bm.evt.Emit(core.EventSyncStatus, syncStatus)
 	}
 
 resync:
 	bm.syncMtx.Lock()
 	bm.syncing = false
 	bm.bestSyncCandidate = nil
+	ended := len(bm.syncCandidate) == 0
 	bm.syncMtx.Unlock()
+
+	// Notify that synchronization has ended
+	// when no candidate is left
+	if ended {
+		<-bm.evt.Emit(core.EventSyncStatus, nil)
+	}
 	bm.sync()
 
 	return nil
@@ -0,0 +1,199 @@
+package node
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/ellcrys/elld/params"
+	"github.com/ellcrys/elld/types"
+	"github.com/ellcrys/elld/types/core"
+	"github.com/ellcrys/elld/util/logger"
+	"github.com/olebedev/emitter"
+	"github.com/shopspring/decimal"
+)
+
+// blockFeeRates holds the fee rates of the
+// transactions included in a block
+type blockFeeRates struct {
+	number uint64
+	rates  []decimal.Decimal
+}
+
+// FeeEstimator tracks the fee rates of transactions
+// included in recent main chain blocks and of the
+// transactions in the pool in order to recommend
+// fees for new transactions.
+type FeeEstimator struct {
+	mtx sync.RWMutex
+
+	// evt is the global event emitter
+	evt *emitter.Emitter
+
+	// log is the logger used by this module
+	log logger.Logger
+
+	// bChain is the blockchain manager
+	bChain types.Blockchain
+
+	// pool is the transaction pool
+	pool types.TxPool
+
+	// blocks holds the fee rates of recent main
+	// chain blocks, ordered from oldest to newest
+	blocks []*blockFeeRates
+}
+
+// NewFeeEstimator creates a FeeEstimator
+func NewFeeEstimator(n *Node) *FeeEstimator {
+	return &FeeEstimator{
+		evt:    n.event,
+		log:    n.log,
+		bChain: n.bChain,
+		pool:   n.txsPool,
+	}
+}
+
+// txFeeRate calculates the fee rate of a transaction
+// formula: tx fee / size
+func txFeeRate(tx types.Transaction) decimal.Decimal {
+	size := decimal.New(tx.GetSizeNoFee(), 0)
+	return tx.GetFee().Decimal().Div(size)
+}
+
+// Manage loads the fee rates of the most recent
+// main chain blocks and tracks new blocks
+func (fe *FeeEstimator) Manage() {
+
+	fe.loadRecentBlocks()
+
+	go func() {
+		for evt := range fe.evt.On(core.EventNewBlock) {
+			fe.addBlock(evt.Args[0].(types.Block))
+		}
+	}()
+}
+
+// loadRecentBlocks adds the most recent
+// blocks of the main chain
+func (fe *FeeEstimator) loadRecentBlocks() {
+
+	tip, err := fe.bChain.ChainReader().Current()
+	if err != nil {
+		fe.log.Debug("Fee estimator failed to get tip block", "Err", err.Error())
+		return
+	}
+
+	var blocks []types.Block
+	for n := tip.GetNumber(); n > 0 && len(blocks) < params.FeeEstimatorMaxBlocks; n-- {
+		block, err := fe.bChain.ChainReader().GetBlock(n)
+		if err != nil {
+			break
+		}
+		blocks = append(blocks, block)
+	}
+
+	for i := len(blocks) - 1; i >= 0; i-- {
+		fe.addBlock(blocks[i])
+	}
+}
+
+// addBlock records the fee rates of
+// a block's transactions.
+func (fe *FeeEstimator) addBlock(block types.Block) {
+	fe.mtx.Lock()
+	defer fe.mtx.Unlock()
+
+	bfr := &blockFeeRates{number: block.GetNumber()}
+	for _, tx := range block.GetTransactions() {
+		if tx.GetType() == core.TxTypeAlloc {
+			continue
+		}
+		bfr.rates = append(bfr.rates, txFeeRate(tx))
+	}
+
+	// A block replacing a block of the same or higher
+	// height (e.g after a re-org) invalidates the
+	// fee rates recorded for those heights
+	for len(fe.blocks) > 0 && fe.blocks[len(fe.blocks)-1].number >= bfr.number {
+		fe.blocks = fe.blocks[:len(fe.blocks)-1]
+	}
+
+	fe.blocks = append(fe.blocks, bfr)
+	if len(fe.blocks) > params.FeeEstimatorMaxBlocks {
+		fe.blocks = fe.blocks[len(fe.blocks)-params.FeeEstimatorMaxBlocks:]
+	}
+}
+
+// historicalFeeRate returns the fee rate that transactions
+// in recent blocks have paid to be included. The lower the
+// target, the higher the percentile of fee rates used.
+func (fe *FeeEstimator) historicalFeeRate(targetBlocks int) decimal.Decimal {
+	fe.mtx.RLock()
+	defer fe.mtx.RUnlock()
+
+	var rates []decimal.Decimal
+	for _, b := range fe.blocks {
+		rates = append(rates, b.rates...)
+	}
+
+	if len(rates) == 0 {
+		return decimal.Zero
+	}
+
+	sort.Slice(rates, func(i, j int) bool {
+		return rates[i].LessThan(rates[j])
+	})
+
+	percentile := 50 + 40/targetBlocks
+	idx := (len(rates) - 1) * percentile / 100
+	return rates[idx]
+}
+
+// poolFeeRate returns the fee rate a transaction must pay
+// to be placed ahead of enough pooled transactions to fit
+// in the next targetBlocks blocks. It returns zero if all
+// pooled transactions fit in the target blocks.
+func (fe *FeeEstimator) poolFeeRate(targetBlocks int) decimal.Decimal {
+
+	var rate = decimal.Zero
+	var capacity = params.MaxBlockTxsSize * int64(targetBlocks)
+	var size int64
+
+	fe.pool.Container().IFind(func(tx types.Transaction) bool {
+		size += tx.GetSizeNoFee()
+		if size > capacity {
+			rate = txFeeRate(tx)
+			return true
+		}
+		return false
+	})
+
+	return rate
+}
+
+// EstimateFeeRate returns the recommended fee rate
+// (fee per byte) for a transaction to be included
+// within the given number of blocks.
+func (fe *FeeEstimator) EstimateFeeRate(targetBlocks int) decimal.Decimal {
+
+	if targetBlocks < 1 {
+		targetBlocks = 1
+	}
+
+	rate := params.FeePerByte
+	if hRate := fe.historicalFeeRate(targetBlocks); hRate.GreaterThan(rate) {
+		rate = hRate
+	}
+	if pRate := fe.poolFeeRate(targetBlocks); pRate.GreaterThan(rate) {
+		rate = pRate
+	}
+
+	return rate
+}
+
+// EstimateFee returns the recommended fee for a
+// transaction of the given size to be included
+// within the given number of blocks.
+func (fe *FeeEstimator) EstimateFee(targetBlocks int, size int64) decimal.Decimal {
+	return fe.EstimateFeeRate(targetBlocks).Mul(decimal.New(size, 0))
+}
@@ -0,0 +1,73 @@
+package node
+
+import (
+	"time"
+
+	"github.com/ellcrys/elld/crypto"
+	"github.com/ellcrys/elld/params"
+	"github.com/ellcrys/elld/types/core"
+	"github.com/ellcrys/elld/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FeeEstimator", func() {
+
+	var lp *Node
+	var fe *FeeEstimator
+	var sender = crypto.NewKeyFromIntSeed(1)
+	var lpPort int
+
+	BeforeEach(func() {
+		lpPort = getPort()
+		lp = makeTestNode(lpPort)
+		Expect(lp.GetBlockchain().Up()).To(BeNil())
+		fe = NewFeeEstimator(lp)
+	})
+
+	AfterEach(func() {
+		closeNode(lp)
+	})
+
+	makeBlock := func(number uint64, fees ...util.String) *core.Block {
+		block := &core.Block{Header: &core.Header{Number: number}}
+		for i, fee := range fees {
+			tx := core.NewTx(core.TxTypeBalance, uint64(i+1), util.String(sender.Addr()),
+				sender, "1", fee, time.Now().Unix())
+			block.Transactions = append(block.Transactions, tx)
+		}
+		return block
+	}
+
+	Describe(".EstimateFeeRate", func() {
+		It("should return the minimum fee rate when no block or pool transaction exists", func() {
+			Expect(fe.EstimateFeeRate(1).Equal(params.FeePerByte)).To(BeTrue())
+		})
+
+		It("should return a fee rate higher than the minimum when recent blocks paid more", func() {
+			fe.addBlock(makeBlock(2, "100", "200"))
+			Expect(fe.EstimateFeeRate(1).GreaterThan(params.FeePerByte)).To(BeTrue())
+		})
+
+		It("should not recommend a higher fee rate for a larger target", func() {
+			fe.addBlock(makeBlock(2, "10", "50", "100", "200"))
+			Expect(fe.EstimateFeeRate(10).LessThanOrEqual(fe.EstimateFeeRate(1))).To(BeTrue())
+		})
+	})
+
+	Describe(".addBlock", func() {
+		It("should replace blocks of the same or higher height", func() {
+			fe.addBlock(makeBlock(2, "100"))
+			fe.addBlock(makeBlock(3, "100"))
+			fe.addBlock(makeBlock(2, "100"))
+			Expect(fe.blocks).To(HaveLen(1))
+		})
+
+		It("should keep at most FeeEstimatorMaxBlocks blocks", func() {
+			for i := 1; i <= params.FeeEstimatorMaxBlocks+5; i++ {
+				fe.addBlock(makeBlock(uint64(i), "100"))
+			}
+			Expect(fe.blocks).To(HaveLen(params.FeeEstimatorMaxBlocks))
+		})
+	})
+})
@@ -5,11 +5,22 @@ import (
 	"time"
 
 	"github.com/ellcrys/elld/config"
+	"github.com/ellcrys/elld/node/peermanager"
 	"github.com/ellcrys/elld/types/core"
+	"github.com/ellcrys/elld/util"
 	"github.com/ellcrys/elld/util/cache"
 	net "github.com/libp2p/go-libp2p-net"
 )
 
+// isExchangeable checks whether an address can be sent
+// to or accepted from peers. Outside test mode, only
+// routable addresses are exchanged. Senders and
+// receivers must use the same check so that honest
+// peers are not penalized for the addresses they send.
+func (g *Manager) isExchangeable(addr util.NodeAddr) bool {
+	return addr.IsValid() && (g.engine.TestMode() || addr.IsRoutable())
+}
+
 // onAddr processes core.Addr message
 func (g *Manager) onAddr(s net.Stream, rp core.Engine) ([]*core.Address, error) {
 
@@ -17,6 +28,7 @@ func (g *Manager) onAddr(s net.Stream, rp core.Engine) ([]*core.Address, error)
 
 	resp := &core.Addr{}
 	if err := ReadStream(s, resp); err != nil {
+		g.penalizeReadErr(rp, err, "malformed Addr message")
 		return nil, g.logErr(err, rp, "[OnAddr] Failed to read stream")
 	}
 
@@ -26,6 +38,7 @@ func (g *Manager) onAddr(s net.Stream, rp core.Engine) ([]*core.Address, error)
 		g.log.Debug("Too many addresses received. Ignoring addresses",
 			"PeerID", rp.ShortID(),
 			"NumAddrReceived", len(resp.Addresses))
+		g.PM().Penalize(rp, peermanager.PenaltyTooManyAddrs, "too many addresses")
 		return nil, fmt.Errorf("too many addresses received. Ignoring addresses")
 	}
 
@@ -39,8 +52,7 @@ func (g *Manager) onAddr(s net.Stream, rp core.Engine) ([]*core.Address, error)
 		// We need to check to see whether the address
 		// format or syntax is valid and verify that it
 		// is a routable address.
-		if !addr.Address.IsValid() || (!g.engine.TestMode() &&
-			!addr.Address.IsRoutable()) {
+		if !g.isExchangeable(addr.Address) {
 			invalidAddrs++
 			continue
 		}
@@ -48,7 +60,6 @@ func (g *Manager) onAddr(s net.Stream, rp core.Engine) ([]*core.Address, error)
 		// Check whether we know this node as a peer that
 		// we had banned recently.
 		if g.PM().IsBanned(p) {
-			invalidAddrs++
 			continue
 		}
 
@@ -61,7 +72,13 @@ func (g *Manager) onAddr(s net.Stream, rp core.Engine) ([]*core.Address, error)
 
 		// At this point the address is considered valid
 		// so we add it to our list of known peers.
-		g.PM().AddOrUpdateNode(rp)
+		g.PM().AddOrUpdateNodeFrom(p, rp)
+	}
+
+	// The sender is penalized once per message
+	// regardless of the number of invalid addresses
+	if invalidAddrs > 0 {
+		g.PM().Penalize(rp, peermanager.PenaltyInvalidAddr, "invalid address")
 	}
 
 	g.log.Debug("Received addresses",
@@ -97,13 +114,13 @@ func (g *Manager) OnAddr(s net.Stream, rp core.Engine) error {
 
 // RelayAddresses relays core.Address under
 // the following rules:
-// * core.Address message must contain not more
-//   than 10 addrs.
-// * all addresses must be valid and
-//   different from the local peer address
-// * Only addresses within 60 minutes from
-//   the current time.
-// * Only routable addresses are allowed.
+//   - core.Address message must contain not more
+//     than 10 addrs.
+//   - all addresses must be valid and
+//     different from the local peer address
+//   - Only addresses within 60 minutes from
+//     the current time.
+//   - Only routable addresses are allowed.
 func (g *Manager) RelayAddresses(addrs []*core.Address) []error {
 
 	var errs []error
@@ -139,10 +156,9 @@ func (g *Manager) RelayAddresses(addrs []*core.Address) []error {
 			continue
 		}
 
-		// In non-production mode, we are allowed
-		// to relay non-routable addresses.
-		// But we can't allow them in production
-		if g.engine.ProdMode() && !addr.Address.IsRoutable() {
+		// Outside test mode, non-routable addresses
+		// are not relayed as receivers reject them
+		if !g.isExchangeable(addr.Address) {
 			errs = append(errs, fmt.Errorf("address {%s} is not routable",
 				addr.Address))
 			continue
@@ -195,7 +211,7 @@ func (g *Manager) RelayAddresses(addrs []*core.Address) []error {
 			msg.Addresses = append(msg.Addresses, p)
 		}
 
-		s, c, err := g.NewStream(rp, config.GetVersions().Addr)
+		s, c, err := g.NewStream(rp, g.ProtocolID(rp, config.MsgAddr))
 		if err != nil {
 			err := g.logConnectErr(err, rp, "[RelayAddresses] Failed to connect to peer")
 			errs = append(errs, err)
@@ -8,6 +8,7 @@ import (
 	"github.com/ellcrys/elld/crypto"
 	"github.com/ellcrys/elld/node"
 	"github.com/ellcrys/elld/node/gossip"
+	"github.com/ellcrys/elld/node/peermanager"
 	"github.com/ellcrys/elld/types/core"
 	"github.com/ellcrys/elld/util"
 	"github.com/olebedev/emitter"
@@ -202,6 +203,27 @@ var _ = Describe("TestAddr", func() {
 			// }, 5)
 		})
 
+		Context("when addresses are not routable outside test mode", func() {
+
+			It("should penalize the sender once per message", func(done Done) {
+				rp.GetCfg().Node.Mode = config.ModeProd
+				processed := rp.GetEventEmitter().Once(gossip.EventAddrProcessed)
+
+				stream, c, err := lp.Gossip().NewStream(rp, config.GetVersions().Addr)
+				Expect(err).To(BeNil())
+				defer c()
+				defer stream.Close()
+
+				err = gossip.WriteStream(stream, addrMsg)
+				Expect(err).To(BeNil())
+
+				<-processed
+				Expect(rp.PM().GetScore(lp)).To(Equal(peermanager.PenaltyInvalidAddr))
+				Expect(rp.PM().PeerExist(p.StringID())).To(BeFalse())
+				close(done)
+			}, 5)
+		})
+
 		Context("when an address has same peer ID as the local peer", func() {
 
 			BeforeEach(func() {
@@ -3,7 +3,9 @@ package gossip
 import (
 	"fmt"
 
+	"github.com/ellcrys/elld/blockchain"
 	"github.com/ellcrys/elld/node/common"
+	"github.com/ellcrys/elld/node/peermanager"
 	"github.com/ellcrys/elld/util/cache"
 	"github.com/jinzhu/copier"
 
@@ -16,7 +18,8 @@ import (
 )
 
 // BroadcastBlock sends a given block to remote peers.
-// The block is encapsulated in a BlockBody message.
+// The block is encapsulated in a BlockBody message or
+// a CompactBlock message if the peer supports it.
 func (g *Manager) BroadcastBlock(block types.Block, remotePeers []core.Engine) []error {
 
 	var sent int
@@ -45,7 +48,18 @@ func (g *Manager) BroadcastBlock(block types.Block, remotePeers []core.Engine) [
 			"BlockHash", block.GetHash().SS(),
 			"NumPeers", len(remotePeers))
 
-		s, c, err := g.NewStream(peer, config.GetVersions().BlockInfo)
+		// Peers that support compact blocks are sent
+		// the block header and short transaction IDs.
+		if g.PM().HasFeature(peer, config.FeatureCompactBlocks) {
+			if err := g.SendCompactBlock(peer, block); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			sent++
+			continue
+		}
+
+		s, c, err := g.NewStream(peer, g.ProtocolID(peer, config.MsgBlockInfo))
 		if err != nil {
 			errs = append(errs, err)
 			g.logConnectErr(err, peer, "[BroadcastBlock] Failed to connect")
@@ -87,7 +101,7 @@ func (g *Manager) BroadcastBlock(block types.Block, remotePeers []core.Engine) [
 		// At this point, we can send the block to the peer.
 		// First we need to create a new stream targeting the
 		// BlockBody handler
-		s2, c2, err := g.NewStream(peer, config.GetVersions().BlockBody)
+		s2, c2, err := g.NewStream(peer, g.ProtocolID(peer, config.MsgBlockBody))
 		if err != nil {
 			errs = append(errs, err)
 			g.logConnectErr(err, peer, "[BroadcastBlock] Failed to connect to peer")
@@ -166,6 +180,7 @@ func (g *Manager) OnBlockBody(s net.Stream, rp core.Engine) error {
 	blockBody := &core.BlockBody{}
 	if err := ReadStream(s, &blockBody); err != nil {
 		s.Reset()
+		g.penalizeReadErr(rp, err, "malformed block body message")
 		return g.logErr(err, rp, "[OnBlockBody] Failed to read")
 	}
 
@@ -173,6 +188,23 @@ func (g *Manager) OnBlockBody(s net.Stream, rp core.Engine) error {
 	copier.Copy(&block, blockBody)
 	block.SetBroadcaster(rp)
 
+	return g.processReceivedBlock(&block, rp)
+}
+
+// processReceivedBlock validates the fields of a block
+// received from a remote peer and has it processed by
+// the block manager. The sender of an invalid block
+// is penalized.
+func (g *Manager) processReceivedBlock(block *core.Block, rp core.Engine) error {
+
+	// A block whose fields are not valid is
+	// rejected and the sender is penalized
+	if errs := blockchain.NewBlockValidator(block, g.engine.GetTxPool(),
+		g.GetBlockchain(), g.engine.GetCfg(), g.log).CheckFields(); len(errs) > 0 {
+		g.PM().Penalize(rp, peermanager.PenaltyInvalidBlock, "invalid block")
+		return g.logErr(errs[0], rp, "Received an invalid block")
+	}
+
 	g.log.Info("Received a block",
 		"BlockNo", block.GetNumber(),
 		"BlockHash", block.GetHash().SS(),
@@ -186,7 +218,7 @@ func (g *Manager) OnBlockBody(s net.Stream, rp core.Engine) error {
 
 	// Emit core.EventRelayedBlock to have the block
 	// processed by the block manager.
-	go g.engine.GetEventEmitter().Emit(core.EventProcessBlock, &block)
+	go g.engine.GetEventEmitter().Emit(core.EventProcessBlock, block)
 
 	return nil
 }
@@ -205,7 +237,7 @@ func (g *Manager) RequestBlock(rp core.Engine, blockHash util.Hash) error {
 		return nil
 	}
 
-	s, c, err := g.NewStream(rp, config.GetVersions().RequestBlock)
+	s, c, err := g.NewStream(rp, g.ProtocolID(rp, config.MsgRequestBlock))
 	if err != nil {
 		return g.logConnectErr(err, rp, "[RequestBlock] Failed to connect to peer")
 	}
@@ -309,7 +341,7 @@ func (g *Manager) SendGetBlockHashes(rp core.Engine,
 	rpID := rp.ShortID()
 	g.log.Debug("Requesting block headers", "PeerID", rpID)
 
-	s, c, err := g.NewStream(rp, config.GetVersions().GetBlockHashes)
+	s, c, err := g.NewStream(rp, g.ProtocolID(rp, config.MsgGetBlockHashes))
 	if err != nil {
 		return nil, g.logConnectErr(err, rp, "[SendGetBlockHashes] Failed to connect")
 	}
@@ -369,9 +401,18 @@ func (g *Manager) OnGetBlockHashes(s net.Stream, rp core.Engine) error {
 	// Read the message
 	msg := &core.GetBlockHashes{}
 	if err := ReadStream(s, msg); err != nil {
+		g.penalizeReadErr(rp, err, "malformed GetBlockHashes message")
 		return g.logErr(err, rp, "[OnGetBlockHashes] Failed to read")
 	}
 
+	// A peer must not request more block
+	// hashes than the allowed maximum
+	if msg.MaxBlocks > params.MaxGetBlockHashes {
+		g.PM().Penalize(rp, peermanager.PenaltyBadRequest, "too many block hashes requested")
+		return g.logErr(fmt.Errorf("max blocks exceeded"), rp,
+			"[OnGetBlockHashes] Invalid request")
+	}
+
 	var blockHashes = core.BlockHashes{}
 	var startBlock types.Block
 	var blockCursor uint64
@@ -462,7 +503,7 @@ func (g *Manager) SendGetBlockBodies(rp core.Engine, hashes []util.Hash) (*core.
 	rpID := rp.ShortID()
 	g.log.Debug("Requesting block bodies", "PeerID", rpID, "NumHashes", len(hashes))
 
-	s, c, err := g.NewStream(rp, config.GetVersions().GetBlockBodies)
+	s, c, err := g.NewStream(rp, g.ProtocolID(rp, config.MsgGetBlockBodies))
 	if err != nil {
 		return nil, g.logConnectErr(err, rp, "[SendGetBlockBodies] Failed to connect")
 	}
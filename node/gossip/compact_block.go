@@ -0,0 +1,259 @@
+package gossip
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/ellcrys/elld/blockchain/common"
+	"github.com/ellcrys/elld/config"
+	"github.com/ellcrys/elld/node/peermanager"
+	"github.com/ellcrys/elld/params"
+	"github.com/ellcrys/elld/types"
+	"github.com/ellcrys/elld/types/core"
+	"github.com/ellcrys/elld/util"
+	net "github.com/libp2p/go-libp2p-net"
+)
+
+// shortTxID returns the short ID of a transaction.
+// It is the first 8 bytes of the transaction hash.
+func shortTxID(hash util.Hash) uint64 {
+	return binary.BigEndian.Uint64(hash[:8])
+}
+
+// makeCompactBlock creates a CompactBlock message from a
+// block. Allocation transactions are never in the pool
+// of the receiver so they are prefilled.
+func makeCompactBlock(block types.Block) *core.CompactBlock {
+	cb := &core.CompactBlock{
+		Header: block.GetHeader().(*core.Header),
+		Hash:   block.GetHash(),
+		Sig:    block.GetSignature(),
+	}
+	for i, tx := range block.GetTransactions() {
+		cb.ShortIDs = append(cb.ShortIDs, shortTxID(tx.GetHash()))
+		if tx.GetType() == core.TxTypeAlloc {
+			cb.Prefilled = append(cb.Prefilled, &core.PrefilledTx{
+				Index: i,
+				Tx:    tx.(*core.Transaction),
+			})
+		}
+	}
+	return cb
+}
+
+// SendCompactBlock sends a block to a remote peer as a
+// CompactBlock message. The remote peer responds with
+// a GetBlockTxs message requesting the transactions it
+// could not find in its pool.
+func (g *Manager) SendCompactBlock(rp core.Engine, block types.Block) error {
+
+	s, c, err := g.NewStream(rp, g.ProtocolID(rp, config.MsgCompactBlock))
+	if err != nil {
+		return g.logConnectErr(err, rp, "[SendCompactBlock] Failed to connect to peer")
+	}
+	defer c()
+	defer s.Close()
+
+	if err := WriteStream(s, makeCompactBlock(block)); err != nil {
+		s.Reset()
+		return g.logErr(err, rp, "[SendCompactBlock] Failed to write CompactBlock")
+	}
+
+	req := &core.GetBlockTxs{}
+	if err := ReadStream(s, req); err != nil {
+		s.Reset()
+		return g.logErr(err, rp, "[SendCompactBlock] Failed to read GetBlockTxs")
+	}
+
+	// The remote peer has all the transactions
+	// or does not need the block
+	if len(req.Indexes) == 0 {
+		return nil
+	}
+
+	txs := block.GetTransactions()
+	resp := &core.BlockTxs{Hash: block.GetHash()}
+	for _, i := range req.Indexes {
+		if i < 0 || i >= len(txs) {
+			s.Reset()
+			g.PM().Penalize(rp, peermanager.PenaltyBadRequest, "invalid block transaction index")
+			return g.logErr(fmt.Errorf("invalid transaction index"), rp,
+				"[SendCompactBlock] Invalid GetBlockTxs request")
+		}
+		resp.Txs = append(resp.Txs, txs[i].(*core.Transaction))
+	}
+
+	if err := WriteStream(s, resp); err != nil {
+		s.Reset()
+		return g.logErr(err, rp, "[SendCompactBlock] Failed to write BlockTxs")
+	}
+
+	g.log.Debug("Sent missing block transactions",
+		"PeerID", rp.ShortID(),
+		"BlockHash", block.GetHash().SS(),
+		"NumTxs", len(resp.Txs))
+
+	return nil
+}
+
+// OnCompactBlock handles incoming CompactBlock messages.
+// It reconstructs the block using transactions in the
+// pool and requests the missing ones from the sender.
+func (g *Manager) OnCompactBlock(s net.Stream, rp core.Engine) error {
+
+	defer s.Close()
+
+	msg := &core.CompactBlock{}
+	if err := ReadStream(s, msg); err != nil {
+		s.Reset()
+		g.penalizeReadErr(rp, err, "malformed compact block message")
+		return g.logErr(err, rp, "[OnCompactBlock] Failed to read")
+	}
+
+	// A compact block must have a header, must not contain
+	// more transactions than a block can hold and its
+	// prefilled transactions must be correctly positioned.
+	if err := checkCompactBlock(msg); err != nil {
+		s.Reset()
+		g.PM().Penalize(rp, peermanager.PenaltyMalformedMsg, "malformed compact block message")
+		return g.logErr(err, rp, "[OnCompactBlock] Received an invalid compact block")
+	}
+
+	// Respond with an empty request if we do
+	// not need the block so the sender stops
+	if g.engine.GetSyncMode().IsDisabled() {
+		return g.writeGetBlockTxs(s, rp, &core.GetBlockTxs{Hash: msg.Hash})
+	}
+	if exist, _ := g.engine.GetBlockchain().HaveBlock(msg.Hash); exist {
+		return g.writeGetBlockTxs(s, rp, &core.GetBlockTxs{Hash: msg.Hash})
+	}
+
+	txs := make([]*core.Transaction, len(msg.ShortIDs))
+	for _, p := range msg.Prefilled {
+		txs[p.Index] = p.Tx
+	}
+
+	// Index the pooled transactions by their short ID
+	pooled := make(map[uint64]*core.Transaction)
+	g.engine.GetTxPool().Container().IFind(func(tx types.Transaction) bool {
+		pooled[shortTxID(tx.GetHash())] = tx.(*core.Transaction)
+		return false
+	})
+
+	req := &core.GetBlockTxs{Hash: msg.Hash}
+	for i, id := range msg.ShortIDs {
+		if txs[i] != nil {
+			continue
+		}
+		if tx, ok := pooled[id]; ok {
+			txs[i] = tx
+			continue
+		}
+		req.Indexes = append(req.Indexes, i)
+	}
+
+	if err := g.writeGetBlockTxs(s, rp, req); err != nil {
+		return err
+	}
+
+	// Read the transactions that were not found in the pool
+	if len(req.Indexes) > 0 {
+		resp := &core.BlockTxs{}
+		if err := ReadStream(s, resp); err != nil {
+			s.Reset()
+			return g.logErr(err, rp, "[OnCompactBlock] Failed to read BlockTxs")
+		}
+
+		// A sender that responds with transactions of another
+		// block or with missing transactions is penalized and
+		// the whole block is requested from it instead.
+		if err := checkBlockTxs(resp, req); err != nil {
+			g.PM().Penalize(rp, peermanager.PenaltyMalformedMsg, "malformed block transactions message")
+			go g.RequestBlock(rp, msg.Hash)
+			return g.logErr(err, rp, "[OnCompactBlock] Received invalid BlockTxs")
+		}
+
+		for i, idx := range req.Indexes {
+			txs[idx] = resp.Txs[i]
+		}
+	}
+
+	block := &core.Block{
+		Header:       msg.Header,
+		Transactions: txs,
+		Hash:         msg.Hash,
+		Sig:          msg.Sig,
+	}
+	block.SetBroadcaster(rp)
+
+	// Short ID collisions produce a block whose transactions
+	// do not match its transactions root. Request the whole
+	// block from the sender when this happens.
+	if !msg.Header.GetTransactionsRoot().Equal(common.ComputeTxsRoot(block.GetTransactions())) {
+		g.log.Debug("Failed to reconstruct compact block. Requesting full block",
+			"PeerID", rp.ShortID(), "BlockHash", msg.Hash.SS())
+		go g.RequestBlock(rp, msg.Hash)
+		return nil
+	}
+
+	g.log.Debug("Reconstructed compact block",
+		"PeerID", rp.ShortID(),
+		"BlockHash", msg.Hash.SS(),
+		"NumTxs", len(txs),
+		"NumRequested", len(req.Indexes))
+
+	return g.processReceivedBlock(block, rp)
+}
+
+// writeGetBlockTxs writes a GetBlockTxs message to a stream
+func (g *Manager) writeGetBlockTxs(s net.Stream, rp core.Engine, msg *core.GetBlockTxs) error {
+	if err := WriteStream(s, msg); err != nil {
+		s.Reset()
+		return g.logErr(err, rp, "[OnCompactBlock] Failed to write GetBlockTxs")
+	}
+	return nil
+}
+
+// checkCompactBlock performs sanity checks on a CompactBlock message
+func checkCompactBlock(msg *core.CompactBlock) error {
+
+	if msg.Header == nil {
+		return fmt.Errorf("header is required")
+	}
+
+	if int64(len(msg.ShortIDs)) > params.MaxBlockTxs {
+		return fmt.Errorf("too many transactions")
+	}
+
+	for _, p := range msg.Prefilled {
+		if p == nil || p.Tx == nil {
+			return fmt.Errorf("prefilled transaction is required")
+		}
+		if p.Index < 0 || p.Index >= len(msg.ShortIDs) {
+			return fmt.Errorf("invalid prefilled transaction index")
+		}
+	}
+
+	return nil
+}
+
+// checkBlockTxs checks that a BlockTxs message contains
+// a transaction for every index requested in a GetBlockTxs
+func checkBlockTxs(msg *core.BlockTxs, req *core.GetBlockTxs) error {
+
+	if !msg.Hash.Equal(req.Hash) {
+		return fmt.Errorf("unexpected block hash")
+	}
+
+	if len(msg.Txs) != len(req.Indexes) {
+		return fmt.Errorf("unexpected number of transactions")
+	}
+
+	for _, tx := range msg.Txs {
+		if tx == nil {
+			return fmt.Errorf("transaction is required")
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,183 @@
+package gossip_test
+
+import (
+	"encoding/binary"
+
+	. "github.com/ellcrys/elld/blockchain/testutil"
+	"github.com/ellcrys/elld/config"
+	"github.com/ellcrys/elld/crypto"
+	"github.com/ellcrys/elld/node"
+	"github.com/ellcrys/elld/node/gossip"
+	"github.com/ellcrys/elld/node/peermanager"
+	"github.com/ellcrys/elld/types"
+	"github.com/ellcrys/elld/types/core"
+	"github.com/ellcrys/elld/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CompactBlock", func() {
+
+	var lp, rp *node.Node
+	var sender, _ = crypto.NewKey(nil)
+	var block types.Block
+
+	BeforeEach(func() {
+		lp = makeTestNode(getPort())
+		Expect(lp.GetBlockchain().Up()).To(BeNil())
+
+		rp = makeTestNode(getPort())
+		Expect(rp.GetBlockchain().Up()).To(BeNil())
+
+		for _, n := range []*node.Node{lp, rp} {
+			Expect(n.GetBlockchain().CreateAccount(1, n.GetBlockchain().GetBestChain(), &core.Account{
+				Type:    core.AccountTypeBalance,
+				Address: util.String(sender.Addr()),
+				Balance: "100",
+			})).To(BeNil())
+		}
+
+		block = MakeBlockWithTx(lp.GetBlockchain(), lp.GetBlockchain().GetBestChain(),
+			sender, 1)
+	})
+
+	AfterEach(func() {
+		closeNode(lp)
+		closeNode(rp)
+	})
+
+	Describe(".SendHandshake", func() {
+		It("should negotiate the compact blocks feature", func() {
+			Expect(lp.Gossip().SendHandshake(rp)).To(BeNil())
+			Expect(lp.PM().HasFeature(rp, config.FeatureCompactBlocks)).To(BeTrue())
+		})
+	})
+
+	Describe(".SendCompactBlock", func() {
+
+		When("the remote peer has the block transactions in its pool", func() {
+			BeforeEach(func() {
+				Expect(rp.GetTxPool().Put(block.GetTransactions()[0])).To(BeNil())
+			})
+
+			It("should reconstruct the block on the remote peer", func(done Done) {
+				wait := make(chan bool)
+
+				go func() {
+					defer GinkgoRecover()
+					evt := <-rp.GetEventEmitter().Once(core.EventProcessBlock)
+					received := evt.Args[0].(*core.Block)
+					Expect(received.GetHash()).To(Equal(block.GetHash()))
+					Expect(received.GetTransactions()).To(HaveLen(len(block.GetTransactions())))
+					close(wait)
+				}()
+
+				Expect(lp.Gossip().SendCompactBlock(rp, block)).To(BeNil())
+				<-wait
+				close(done)
+			})
+		})
+
+		When("the remote peer does not have the block transactions", func() {
+			It("should request the missing transactions and reconstruct the block", func(done Done) {
+				wait := make(chan bool)
+
+				go func() {
+					defer GinkgoRecover()
+					evt := <-rp.GetEventEmitter().Once(core.EventProcessBlock)
+					received := evt.Args[0].(*core.Block)
+					Expect(received.GetHash()).To(Equal(block.GetHash()))
+					Expect(received.GetTransactions()[0].GetHash()).
+						To(Equal(block.GetTransactions()[0].GetHash()))
+					close(wait)
+				}()
+
+				Expect(lp.Gossip().SendCompactBlock(rp, block)).To(BeNil())
+				<-wait
+				close(done)
+			})
+		})
+	})
+
+	Describe(".OnCompactBlock", func() {
+
+		// sendCompactBlock writes the block to the remote peer as
+		// a CompactBlock and answers its GetBlockTxs using reply
+		var sendCompactBlock = func(reply func(req *core.GetBlockTxs) *core.BlockTxs) {
+			cb := &core.CompactBlock{
+				Header: block.GetHeader().(*core.Header),
+				Hash:   block.GetHash(),
+				Sig:    block.GetSignature(),
+			}
+			for _, tx := range block.GetTransactions() {
+				h := tx.GetHash()
+				cb.ShortIDs = append(cb.ShortIDs, binary.BigEndian.Uint64(h[:8]))
+			}
+
+			s, c, err := lp.Gossip().NewStream(rp, lp.Gossip().ProtocolID(rp, config.MsgCompactBlock))
+			Expect(err).To(BeNil())
+			defer c()
+			defer s.Close()
+
+			Expect(gossip.WriteStream(s, cb)).To(BeNil())
+			req := &core.GetBlockTxs{}
+			Expect(gossip.ReadStream(s, req)).To(BeNil())
+			Expect(req.Indexes).ToNot(BeEmpty())
+			Expect(gossip.WriteStream(s, reply(req))).To(BeNil())
+		}
+
+		BeforeEach(func() {
+			_, err := lp.GetBlockchain().ProcessBlock(block)
+			Expect(err).To(BeNil())
+		})
+
+		// expectFullBlockRequest checks that the remote peer penalized
+		// the local peer and processed the block it requested in full
+		var expectFullBlockRequest = func(send func()) {
+			wait := make(chan bool)
+
+			go func() {
+				defer GinkgoRecover()
+				evt := <-rp.GetEventEmitter().Once(core.EventProcessBlock)
+				received := evt.Args[0].(*core.Block)
+				Expect(received.GetHash()).To(Equal(block.GetHash()))
+				Expect(received.GetTransactions()[0].GetHash()).
+					To(Equal(block.GetTransactions()[0].GetHash()))
+				close(wait)
+			}()
+
+			send()
+			<-wait
+			Expect(rp.PM().GetScore(lp)).To(Equal(peermanager.PenaltyMalformedMsg))
+		}
+
+		When("the sender responds with a nil transaction", func() {
+			It("should penalize the sender and request the full block", func(done Done) {
+				expectFullBlockRequest(func() {
+					sendCompactBlock(func(req *core.GetBlockTxs) *core.BlockTxs {
+						return &core.BlockTxs{
+							Hash: req.Hash,
+							Txs:  make([]*core.Transaction, len(req.Indexes)),
+						}
+					})
+				})
+				close(done)
+			})
+		})
+
+		When("the sender responds with the transactions of another block", func() {
+			It("should penalize the sender and request the full block", func(done Done) {
+				expectFullBlockRequest(func() {
+					sendCompactBlock(func(req *core.GetBlockTxs) *core.BlockTxs {
+						resp := &core.BlockTxs{Hash: util.StrToHash("other_block")}
+						for _, i := range req.Indexes {
+							resp.Txs = append(resp.Txs, block.GetTransactions()[i].(*core.Transaction))
+						}
+						return resp
+					})
+				})
+				close(done)
+			})
+		})
+	})
+})
@@ -0,0 +1,271 @@
+package gossip
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ellcrys/elld/config"
+	"github.com/ellcrys/elld/node/peermanager"
+	"github.com/ellcrys/elld/params"
+	"github.com/ellcrys/elld/types/core"
+	"github.com/ellcrys/elld/util"
+	net "github.com/libp2p/go-libp2p-net"
+)
+
+// dhtKey returns the position of a peer in the
+// key space of the distributed hash table
+func dhtKey(p core.Engine) []byte {
+	return util.Blake2b256([]byte(p.ID()))
+}
+
+// xorDistance returns the XOR distance between two keys
+func xorDistance(a, b []byte) []byte {
+	d := make([]byte, len(a))
+	for i := range a {
+		if i < len(b) {
+			d[i] = a[i] ^ b[i]
+		}
+	}
+	return d
+}
+
+// sortByDistance sorts peers by the distance
+// of their keys to the target key
+func sortByDistance(target []byte, peers []core.Engine) {
+	sort.Slice(peers, func(i, j int) bool {
+		di := xorDistance(dhtKey(peers[i]), target)
+		dj := xorDistance(dhtKey(peers[j]), target)
+		return bytes.Compare(di, dj) < 0
+	})
+}
+
+// closestPeers returns up to n of the given
+// peers that are closest to the target key
+func closestPeers(target []byte, peers []core.Engine, n int) []core.Engine {
+	sortByDistance(target, peers)
+	if len(peers) > n {
+		peers = peers[:n]
+	}
+	return peers
+}
+
+// FindPeers performs an iterative lookup of the peers
+// closest to the target key. It starts with the closest
+// active peers and repeatedly queries the closest peers
+// not yet queried until the closest peers found have
+// all been queried. Discovered peers are added to the
+// peer manager.
+func (g *Manager) FindPeers(target []byte) []core.Engine {
+
+	shortlist := closestPeers(target, g.knownDHTPeers(), params.DHTBucketSize)
+	queried := make(map[string]struct{})
+
+	for {
+
+		// Select the closest peers that have not been queried
+		var toQuery []core.Engine
+		for _, p := range shortlist {
+			if len(toQuery) >= params.DHTConcurrency {
+				break
+			}
+			if _, ok := queried[p.StringID()]; !ok {
+				toQuery = append(toQuery, p)
+				queried[p.StringID()] = struct{}{}
+			}
+		}
+
+		if len(toQuery) == 0 {
+			break
+		}
+
+		var mtx sync.Mutex
+		var wg sync.WaitGroup
+		found := make(map[string]core.Engine)
+		for _, p := range shortlist {
+			found[p.StringID()] = p
+		}
+
+		for _, p := range toQuery {
+			wg.Add(1)
+			go func(rp core.Engine) {
+				defer wg.Done()
+				peers, err := g.SendFindPeers(rp, target)
+				if err != nil {
+					return
+				}
+				mtx.Lock()
+				for _, p := range peers {
+					if _, ok := found[p.StringID()]; !ok {
+						found[p.StringID()] = p
+					}
+				}
+				mtx.Unlock()
+			}(p)
+		}
+		wg.Wait()
+
+		var candidates []core.Engine
+		for _, p := range found {
+			candidates = append(candidates, p)
+		}
+		shortlist = closestPeers(target, candidates, params.DHTBucketSize)
+	}
+
+	g.log.Debug("Completed peer lookup",
+		"Target", util.ToHex(target)[:10],
+		"NumQueried", len(queried),
+		"NumFound", len(shortlist))
+
+	return shortlist
+}
+
+// knownDHTPeers returns the active peers
+// that can be queried during a lookup
+func (g *Manager) knownDHTPeers() (peers []core.Engine) {
+	for _, p := range g.PM().GetActivePeers(0) {
+		if g.PM().IsLocalNode(p) || g.PM().IsBanListed(p.GetAddress()) {
+			continue
+		}
+		peers = append(peers, p)
+	}
+	return
+}
+
+// SendFindPeers sends a FindPeers message to a remote
+// peer. The remote peer responds with an Addr message
+// containing the peers it knows that are closest to
+// the target key.
+func (g *Manager) SendFindPeers(rp core.Engine, target []byte) ([]core.Engine, error) {
+
+	s, c, err := g.NewStream(rp, g.ProtocolID(rp, config.MsgFindPeers))
+	if err != nil {
+		return nil, g.logConnectErr(err, rp, "[SendFindPeers] Failed to connect")
+	}
+	defer c()
+	defer s.Close()
+
+	if err := WriteStream(s, &core.FindPeers{Target: target}); err != nil {
+		s.Reset()
+		return nil, g.logErr(err, rp, "[SendFindPeers] Failed to write")
+	}
+
+	resp := &core.Addr{}
+	if err := ReadStream(s, resp); err != nil {
+		s.Reset()
+		g.penalizeReadErr(rp, err, "malformed Addr message")
+		return nil, g.logErr(err, rp, "[SendFindPeers] Failed to read")
+	}
+
+	if len(resp.Addresses) > params.DHTBucketSize {
+		g.PM().Penalize(rp, peermanager.PenaltyTooManyAddrs, "too many addresses")
+		return nil, g.logErr(fmt.Errorf("too many addresses received"), rp,
+			"[SendFindPeers] Invalid response")
+	}
+
+	var peers []core.Engine
+	invalidAddrs := 0
+	for _, addr := range resp.Addresses {
+
+		if !g.isExchangeable(addr.Address) {
+			invalidAddrs++
+			continue
+		}
+
+		// Ignore the local peer
+		if addr.Address.ID() == g.engine.ID() {
+			continue
+		}
+
+		p := g.engine.NewRemoteNode(addr.Address)
+		if g.PM().IsBanned(p) || g.PM().IsBanListed(p.GetAddress()) {
+			continue
+		}
+
+		g.PM().AddOrUpdateNodeFrom(p, rp)
+		peers = append(peers, p)
+	}
+
+	// Penalize the peer once regardless of
+	// the number of invalid addresses
+	if invalidAddrs > 0 {
+		g.PM().Penalize(rp, peermanager.PenaltyInvalidAddr, "invalid address")
+	}
+
+	g.log.Debug("Received closest peers", "PeerID", rp.ShortID(),
+		"NumPeers", len(peers))
+
+	return peers, nil
+}
+
+// OnFindPeers handles incoming FindPeers messages.
+// It responds with the addresses of the active peers
+// closest to the target key.
+func (g *Manager) OnFindPeers(s net.Stream, rp core.Engine) error {
+
+	defer s.Close()
+
+	msg := &core.FindPeers{}
+	if err := ReadStream(s, msg); err != nil {
+		s.Reset()
+		g.penalizeReadErr(rp, err, "malformed FindPeers message")
+		return g.logErr(err, rp, "[OnFindPeers] Failed to read")
+	}
+
+	if len(msg.Target) != 32 {
+		s.Reset()
+		g.PM().Penalize(rp, peermanager.PenaltyMalformedMsg, "malformed FindPeers message")
+		return g.logErr(fmt.Errorf("invalid target key"), rp, "[OnFindPeers] Invalid message")
+	}
+
+	var candidates []core.Engine
+	for _, p := range g.knownDHTPeers() {
+		if p.IsSame(rp) || p.IsHardcodedSeed() {
+			continue
+		}
+
+		// Do not send addresses the
+		// remote peer cannot accept
+		if !g.isExchangeable(p.GetAddress()) {
+			continue
+		}
+
+		candidates = append(candidates, p)
+	}
+
+	addr := &core.Addr{}
+	for _, p := range closestPeers(msg.Target, candidates, params.DHTBucketSize) {
+		addr.Addresses = append(addr.Addresses, &core.Address{
+			Address:   p.GetAddress(),
+			Timestamp: p.GetLastSeen().Unix(),
+		})
+	}
+
+	if err := WriteStream(s, addr); err != nil {
+		s.Reset()
+		return g.logErr(err, rp, "[OnFindPeers] Failed to write")
+	}
+
+	return nil
+}
+
+// doDHTLookup looks up the peers closest to the local
+// peer and then periodically looks up random keys
+// to discover new peers.
+func (g *Manager) doDHTLookup(done chan bool) {
+	g.FindPeers(dhtKey(g.engine))
+	ticker := time.NewTicker(params.DHTLookupInterval)
+	for {
+		select {
+		case <-ticker.C:
+			if g.PM().RequirePeers() {
+				g.FindPeers(util.Blake2b256([]byte(util.RandString(32))))
+			}
+		case <-done:
+			ticker.Stop()
+			return
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package gossip_test
+
+import (
+	"github.com/ellcrys/elld/config"
+	"github.com/ellcrys/elld/node"
+	"github.com/ellcrys/elld/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DHT", func() {
+
+	var n1, n2, n3, n4 *node.Node
+	var target = util.Blake2b256([]byte("target"))
+
+	BeforeEach(func() {
+		n1 = makeTestNode(getPort())
+		n2 = makeTestNode(getPort())
+		n3 = makeTestNode(getPort())
+		n4 = makeTestNode(getPort())
+	})
+
+	AfterEach(func() {
+		closeNode(n1)
+		closeNode(n2)
+		closeNode(n3)
+		closeNode(n4)
+	})
+
+	Describe(".SendFindPeers", func() {
+
+		BeforeEach(func() {
+			n2.PM().AddOrUpdateNode(n1)
+			n2.PM().AddOrUpdateNode(n3)
+		})
+
+		It("should return the peers known by the remote peer except the sender", func() {
+			peers, err := n1.Gossip().SendFindPeers(n2, target)
+			Expect(err).To(BeNil())
+			Expect(peers).To(HaveLen(1))
+			Expect(peers[0].StringID()).To(Equal(n3.StringID()))
+			Expect(n1.PM().PeerExist(n3.StringID())).To(BeTrue())
+		})
+
+		Context("when the known peers are not routable outside test mode", func() {
+			It("should not return them", func() {
+				n2.GetCfg().Node.Mode = config.ModeProd
+				peers, err := n1.Gossip().SendFindPeers(n2, target)
+				Expect(err).To(BeNil())
+				Expect(peers).To(BeEmpty())
+				Expect(n1.PM().GetScore(n2)).To(BeZero())
+			})
+		})
+	})
+
+	Describe(".FindPeers", func() {
+
+		BeforeEach(func() {
+			n1.PM().AddOrUpdateNode(n2)
+			n2.PM().AddOrUpdateNode(n3)
+			n3.PM().AddOrUpdateNode(n4)
+		})
+
+		It("should discover peers by iteratively querying the closest peers", func() {
+			peers := n1.Gossip().FindPeers(target)
+			Expect(peers).To(HaveLen(3))
+			Expect(n1.PM().PeerExist(n3.StringID())).To(BeTrue())
+			Expect(n1.PM().PeerExist(n4.StringID())).To(BeTrue())
+		})
+	})
+})
@@ -11,7 +11,7 @@ import (
 // must be processed using the OnAddr handler and return the response.
 func (g *Manager) SendGetAddrToPeer(rp core.Engine) ([]*core.Address, error) {
 
-	s, c, err := g.NewStream(rp, config.GetVersions().GetAddr)
+	s, c, err := g.NewStream(rp, g.ProtocolID(rp, config.MsgGetAddr))
 	if err != nil {
 		return nil, g.logConnectErr(err, rp, "[SendGetAddrToPeer] Failed to connect")
 	}
@@ -94,8 +94,9 @@ func (g *Manager) OnGetAddr(s net.Stream, rp core.Engine) error {
 	for _, peer := range activePeers {
 		// Ignore an address if it is the same with the local node
 		// and if it is an hardcoded seed address
+		// Also ignore addresses the remote peer would reject
 		if g.PM().IsLocalNode(peer) || peer.IsSame(rp) ||
-			peer.IsHardcodedSeed() {
+			peer.IsHardcodedSeed() || !g.isExchangeable(peer.GetAddress()) {
 			continue
 		}
 		addr.Addresses = append(addr.Addresses, &core.Address{
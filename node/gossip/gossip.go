@@ -3,6 +3,9 @@ package gossip
 import (
 	"bufio"
 	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
 	"math/big"
 	"sort"
 	"sync"
@@ -14,6 +17,7 @@ import (
 	"github.com/vmihailenco/msgpack"
 
 	"github.com/ellcrys/elld/node/peermanager"
+	"github.com/ellcrys/elld/params"
 	"github.com/ellcrys/elld/types"
 	"github.com/ellcrys/elld/types/core"
 	"github.com/ellcrys/elld/util/logger"
@@ -46,6 +50,10 @@ var (
 	// StreamReadDelay is the amount of time to
 	// wait for data from a stream
 	StreamReadDelay = time.Duration(1) * time.Minute
+
+	// ErrMsgTooLarge is returned when a message is larger
+	// than the maximum size allowed by its protocol
+	ErrMsgTooLarge = fmt.Errorf("message too large")
 )
 
 // Manager represents the peer protocol
@@ -71,6 +79,21 @@ type Manager struct {
 
 	// pm is the peer manager
 	pm *peermanager.Manager
+
+	// txInvMtx is the mutex for txInv
+	txInvMtx sync.Mutex
+
+	// txInv contains the transaction hashes
+	// waiting to be announced to each peer
+	txInv map[string]*txInvQueue
+
+	// tickersDone is closed to stop
+	// background routines
+	tickersDone chan bool
+
+	// rateLimiter limits the rate of
+	// messages received from peers
+	rateLimiter *rateLimiter
 }
 
 // NewGossip creates a new instance of the Gossip protocol
@@ -81,6 +104,28 @@ func NewGossip(p core.Engine, log logger.Logger) *Manager {
 		mtx:              sync.RWMutex{},
 		broadcasters:     core.NewBroadcastPeers(),
 		randBroadcasters: core.NewBroadcastPeers(),
+		txInv:            make(map[string]*txInvQueue),
+		tickersDone:      make(chan bool),
+		rateLimiter:      newRateLimiter(params.MsgRateLimit, params.MsgRateBurst),
+	}
+}
+
+// Manage starts the routines of the gossip manager
+func (g *Manager) Manage() {
+	go g.doTxInv(g.tickersDone)
+	if g.engine.GetCfg().Node.EnableDHT {
+		go g.doDHTLookup(g.tickersDone)
+	}
+}
+
+// Stop stops the routines of the gossip manager
+func (g *Manager) Stop() {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+	select {
+	case <-g.tickersDone:
+	default:
+		close(g.tickersDone)
 	}
 }
 
@@ -182,14 +227,31 @@ func (g *Manager) PickBroadcastersFromPeers(cache *core.BroadcastPeers,
 	return g.PickBroadcasters(cache, peerAddrs, n)
 }
 
-// NewStream creates a stream for a given protocol
-// ID and between the local peer and the given remote peer.
-func (g *Manager) NewStream(remotePeer core.Engine, msgVersion string) (net.Stream,
+// ProtocolID returns the ID of the protocol to use when
+// sending a message type to a remote peer. It uses the
+// version negotiated during handshake, falling back to
+// the first version if none was negotiated.
+func (g *Manager) ProtocolID(rp core.Engine, msgType string) string {
+	version := g.PM().GetMsgVersion(rp, msgType)
+	if version == 0 {
+		version = 1
+	}
+	return config.GetVersions().ProtocolID(msgType, version)
+}
+
+// NewStream creates a stream between the local peer and
+// the given remote peer. The first of the given protocol
+// IDs supported by the remote peer is used.
+func (g *Manager) NewStream(remotePeer core.Engine, msgVersions ...string) (net.Stream,
 	context.CancelFunc, error) {
 	ctxDur := time.Second * time.Duration(g.engine.GetCfg().Node.MessageTimeout)
 	ctx, cf := context.WithTimeout(context.TODO(), ctxDur)
 	g.engine.AddToPeerStore(remotePeer)
-	s, err := g.engine.GetHost().NewStream(ctx, remotePeer.ID(), protocol.ID(msgVersion))
+	pids := make([]protocol.ID, len(msgVersions))
+	for i, v := range msgVersions {
+		pids[i] = protocol.ID(v)
+	}
+	s, err := g.engine.GetHost().NewStream(ctx, remotePeer.ID(), pids...)
 	if err != nil {
 		cf()
 	}
@@ -202,17 +264,23 @@ func (g *Manager) CheckRemotePeer(ws *core.WrappedStream, rp core.Engine) error
 	s := ws.Stream
 	skipAcquaintanceCheck := false
 
+	// Reject all messages from peers in the ban list
+	if g.PM().IsBanListed(rp.GetAddress()) {
+		return fmt.Errorf("peer is in the ban list")
+	}
+
 	// Perform no checks for handshake messages
-	if s.Protocol() == protocol.ID(config.GetVersions().Handshake) {
+	msgType, _ := config.ParseProtocolID(string(s.Protocol()))
+	if msgType == config.MsgHandshake {
 		return nil
 	}
 
-	// If we receive an Addr message from an unknown peer,
+	// If we receive an Addr or FindPeers message from an unknown peer,
 	// temporarily skip acquaintance check and allow
 	// message to be processed.
 	// We need to accept this unsolicited message so
 	// that peer discovery will be more effective.
-	if s.Protocol() == protocol.ID(config.GetVersions().Addr) &&
+	if (msgType == config.MsgAddr || msgType == config.MsgFindPeers) &&
 		!g.PM().PeerExist(rp.StringID()) {
 		skipAcquaintanceCheck = true
 	}
@@ -249,6 +317,17 @@ func (g *Manager) Handle(handler func(s net.Stream, remotePeer core.Engine) erro
 			return
 		}
 
+		// Check whether the peer has exceeded
+		// the rate limit of the message type
+		msgType, _ := config.ParseProtocolID(string(s.Protocol()))
+		if !g.rateLimiter.Allow(rp.StringID() + "/" + msgType) {
+			g.PM().Penalize(rp, peermanager.PenaltyRateLimited, "message rate limit exceeded")
+			g.logErr(fmt.Errorf("rate limit exceeded"), rp,
+				"message ("+string(s.Protocol())+") unaccepted")
+			s.Reset()
+			return
+		}
+
 		// Update the last seen time of this peer
 		g.PM().AddOrUpdateNode(rp)
 
@@ -257,19 +336,123 @@ func (g *Manager) Handle(handler func(s net.Stream, remotePeer core.Engine) erro
 	}
 }
 
-// ReadStream reads the content of a steam into dest
+// maxMsgSize returns the maximum size of a message
+// of a protocol. Messages that carry blocks or many
+// transactions are allowed to be as large as a block.
+func maxMsgSize(pid protocol.ID) int64 {
+	maxBlockSize := params.MaxBlockTxsSize + params.MaxBlockNonTxsSize
+	msgType, _ := config.ParseProtocolID(string(pid))
+	switch msgType {
+	case config.MsgBlockBody, config.MsgRequestBlock, config.MsgCompactBlock,
+		config.MsgTxInv:
+		return maxBlockSize
+	case config.MsgGetBlockBodies:
+		return maxBlockSize * (params.MaxGetBlockHashes + 1)
+	default:
+		return params.MaxMsgSize
+	}
+}
+
+// isFramed checks whether the messages of a protocol are
+// framed by a length prefix. Framing was introduced in the
+// second version of every message type. Messages of the
+// first version are written as is.
+func isFramed(pid protocol.ID) bool {
+	_, version := config.ParseProtocolID(string(pid))
+	return version >= 2
+}
+
+// limitedReader reads from r until n bytes are read
+// and returns ErrMsgTooLarge afterwards
+type limitedReader struct {
+	r io.Reader
+	n int64
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.n <= 0 {
+		return 0, ErrMsgTooLarge
+	}
+	if int64(len(p)) > l.n {
+		p = p[:l.n]
+	}
+	n, err := l.r.Read(p)
+	l.n -= int64(n)
+	return n, err
+}
+
+// ReadStream reads a message from a stream into dest.
+// Messages larger than the maximum message size of the
+// stream's protocol are rejected. If the protocol frames
+// messages, a message is preceded by its size as a 4
+// bytes, big-endian integer.
 func ReadStream(s net.Stream, dest interface{}) error {
-	return msgpack.NewDecoder(bufio.NewReader(s)).Decode(dest)
+
+	_ = s.SetReadDeadline(time.Now().Add(StreamReadDelay))
+
+	maxSize := maxMsgSize(s.Protocol())
+	if !isFramed(s.Protocol()) {
+		r := &limitedReader{r: s, n: maxSize}
+		err := msgpack.NewDecoder(bufio.NewReader(r)).Decode(dest)
+		if err != nil && r.n <= 0 {
+			return ErrMsgTooLarge
+		}
+		return err
+	}
+
+	var size uint32
+	if err := binary.Read(s, binary.BigEndian, &size); err != nil {
+		return err
+	}
+
+	if int64(size) > maxSize {
+		return ErrMsgTooLarge
+	}
+
+	bs := make([]byte, size)
+	if _, err := io.ReadFull(s, bs); err != nil {
+		return err
+	}
+
+	return msgpack.Unmarshal(bs, dest)
 }
 
-// WriteStream writes msg to the given stream
+// WriteStream writes msg to the given stream. The
+// message is length-prefixed if the stream's
+// protocol frames messages.
 func WriteStream(s net.Stream, msg interface{}) error {
-	w := bufio.NewWriter(s)
-	if err := msgpack.NewEncoder(w).Encode(msg); err != nil {
+
+	bs, err := msgpack.Marshal(msg)
+	if err != nil {
 		return err
 	}
-	w.Flush()
-	return nil
+
+	if int64(len(bs)) > maxMsgSize(s.Protocol()) {
+		return ErrMsgTooLarge
+	}
+
+	if !isFramed(s.Protocol()) {
+		_, err = s.Write(bs)
+		return err
+	}
+
+	frame := make([]byte, 4+len(bs))
+	binary.BigEndian.PutUint32(frame, uint32(len(bs)))
+	copy(frame[4:], bs)
+
+	_, err = s.Write(frame)
+	return err
+}
+
+// penalizeReadErr penalizes a peer whose message could
+// not be read. Messages larger than the allowed maximum
+// are penalized more than other malformed messages.
+func (g *Manager) penalizeReadErr(rp core.Engine, err error, reason string) {
+	if err == ErrMsgTooLarge {
+		g.PM().Penalize(rp, peermanager.PenaltyMsgTooLarge, "message too large")
+		return
+	}
+	g.PM().Penalize(rp, peermanager.PenaltyMalformedMsg, reason)
 }
 
 func (g *Manager) logErr(err error, rp core.Engine, msg string) error {
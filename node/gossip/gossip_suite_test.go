@@ -92,6 +92,9 @@ func makeTestNodeWith(port int, seed int) *node.Node {
 }
 
 func closeNode(n *node.Node) {
+	if tm := n.GetTxManager(); tm != nil {
+		tm.Stop()
+	}
 	go n.GetHost().Close()
 	err := os.RemoveAll(n.GetCfg().DataDir())
 	Expect(err).To(BeNil())
@@ -5,6 +5,8 @@ import (
 
 	"github.com/ellcrys/elld/config"
 	"github.com/ellcrys/elld/node"
+	"github.com/ellcrys/elld/node/gossip"
+	"github.com/ellcrys/elld/params"
 	"github.com/ellcrys/elld/types/core"
 	"github.com/ellcrys/elld/util"
 	net "github.com/libp2p/go-libp2p-net"
@@ -190,4 +192,17 @@ var _ = Describe("Gossip", func() {
 		})
 	})
 
+	Describe(".WriteStream", func() {
+		It("should return ErrMsgTooLarge when message exceeds the maximum size of the protocol", func() {
+			stream, cc, err := lp.Gossip().NewStream(rp, config.GetVersions().Ping)
+			Expect(err).To(BeNil())
+			defer cc()
+			defer stream.Close()
+			addr := util.NodeAddr(make([]byte, params.MaxMsgSize))
+			msg := &core.Addr{Addresses: []*core.Address{{Address: addr}}}
+			err = gossip.WriteStream(stream, msg)
+			Expect(err).To(Equal(gossip.ErrMsgTooLarge))
+		})
+	})
+
 })
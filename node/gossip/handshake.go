@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/ellcrys/elld/config"
+	"github.com/ellcrys/elld/node/peermanager"
 	"github.com/ellcrys/elld/types"
 	"github.com/ellcrys/elld/types/core"
 	"github.com/ellcrys/elld/util/logger"
@@ -28,15 +29,84 @@ func createHandshakeMsg(msg *core.Handshake, bestChain types.ChainReaderFactory,
 	msg.BestBlockHash = bestBlock.GetHash()
 	msg.BestBlockTotalDifficulty = bestBlock.GetHeader().GetTotalDifficulty()
 	msg.BestBlockNumber = bestBlock.GetNumber()
+	msg.MsgVersions = config.GetMsgVersions()
+	msg.Features = config.GetFeatures()
+
+	// Add the genesis block hash so that peers
+	// can detect nodes of a different network
+	genesisBlock, err := bestChain.GetBlock(1)
+	if err != nil {
+		return nil, fmt.Errorf("handshake failed: failed to "+
+			"get genesis block: %s", err.Error())
+	}
+	msg.GenesisHash = genesisBlock.GetHash()
 
 	return msg, nil
 }
 
+// handshakeMsg returns msg in the encoding of the
+// handshake version of a stream. The first version
+// carries only the fields known to peers that
+// predate message versioning.
+func handshakeMsg(s net.Stream, msg *core.Handshake) interface{} {
+	if !isFramed(s.Protocol()) {
+		return (*core.HandshakeV1)(msg)
+	}
+	return msg
+}
+
+// acceptHandshake checks that a remote peer's handshake
+// message belongs to the same network and genesis block
+// as the local peer and selects the message versions and
+// features to use with the peer. The selection is stored
+// in the peer manager. It returns a reject describing why
+// the handshake is not acceptable. Peers that predate
+// message versioning send neither a network ID nor a
+// genesis hash; these checks are skipped for them.
+func (g *Manager) acceptHandshake(rp core.Engine, msg *core.Handshake,
+	localMsg *core.Handshake) *core.Reject {
+
+	if msg.NetworkID != "" && msg.NetworkID != localMsg.NetworkID {
+		return &core.Reject{
+			Message: config.MsgHandshake,
+			Code:    core.RejectCodeNetworkMismatch,
+			Reason: fmt.Sprintf("network mismatch: expected %s, got %s",
+				localMsg.NetworkID, msg.NetworkID),
+		}
+	}
+
+	if !msg.GenesisHash.IsEmpty() && !msg.GenesisHash.Equal(localMsg.GenesisHash) {
+		return &core.Reject{
+			Message: config.MsgHandshake,
+			Code:    core.RejectCodeGenesisMismatch,
+			Reason: fmt.Sprintf("genesis block mismatch: expected %s, got %s",
+				localMsg.GenesisHash.SS(), msg.GenesisHash.SS()),
+		}
+	}
+
+	msgVersions, err := config.NegotiateMsgVersions(msg.MsgVersions)
+	if err != nil {
+		return &core.Reject{
+			Message: config.MsgHandshake,
+			Code:    core.RejectCodeIncompatibleProtocol,
+			Reason:  err.Error(),
+		}
+	}
+
+	g.PM().SetProtocol(rp, &peermanager.Protocol{
+		MsgVersions: msgVersions,
+		Features:    config.NegotiateFeatures(msg.Features),
+	})
+
+	return nil
+}
+
 // SendHandshake sends an introductory message to a peer
 func (g *Manager) SendHandshake(rp core.Engine) error {
 
 	rpIDShort := rp.ShortID()
-	s, c, err := g.NewStream(rp, config.GetVersions().Handshake)
+	// Use the latest handshake version the peer supports
+	s, c, err := g.NewStream(rp, config.GetVersions().ProtocolIDs(config.MsgHandshake)...)
 	if err != nil {
 		return g.logConnectErr(err, rp, "[SendHandshake] Failed to connect to peer")
 	}
@@ -46,14 +116,15 @@ func (g *Manager) SendHandshake(rp core.Engine) error {
 	g.log.Debug("Sent handshake to peer", "PeerID", rpIDShort)
 
 	nodeMsg, err := createHandshakeMsg(&core.Handshake{
-		Version: g.engine.GetCfg().VersionInfo.BuildVersion,
-		Name:    g.engine.GetName(),
+		Version:   g.engine.GetCfg().VersionInfo.BuildVersion,
+		Name:      g.engine.GetName(),
+		NetworkID: g.engine.GetCfg().Node.NetworkID,
 	}, g.GetBlockchain().ChainReader(), g.log)
 	if err != nil {
 		return err
 	}
 
-	if err := WriteStream(s, nodeMsg); err != nil {
+	if err := WriteStream(s, handshakeMsg(s, nodeMsg)); err != nil {
 		return g.logErr(err, rp, "[SendHandshake] Failed to write to stream")
 	}
 
@@ -62,10 +133,28 @@ func (g *Manager) SendHandshake(rp core.Engine) error {
 		nodeMsg.BestBlockTotalDifficulty)
 
 	resp := &core.Handshake{}
-	if err := ReadStream(s, resp); err != nil {
+	if err := ReadStream(s, handshakeMsg(s, resp)); err != nil {
 		return g.logErr(err, rp, "[SendHandshake] Failed to read from stream")
 	}
 
+	// Disconnect the peer if it rejected our handshake
+	if resp.Reject != nil {
+		g.log.Info("Handshake rejected by peer. Disconnecting",
+			"PeerID", rpIDShort, "Reason", resp.Reject.Reason)
+		g.engine.GetHost().Network().ClosePeer(rp.ID())
+		return fmt.Errorf("handshake rejected: %s", resp.Reject.Reason)
+	}
+
+	// Verify the peer's network and select the message
+	// versions and features to use with it. Disconnect
+	// the peer if it is not compatible.
+	if reject := g.acceptHandshake(rp, resp, nodeMsg); reject != nil {
+		g.log.Info("Disconnecting incompatible peer",
+			"PeerID", rpIDShort, "Reason", reject.Reason)
+		g.engine.GetHost().Network().ClosePeer(rp.ID())
+		return fmt.Errorf("handshake rejected: %s", reject.Reason)
+	}
+
 	rp.SetName(resp.Name)
 
 	// Add or update peer 'last seen' timestamp
@@ -94,7 +183,7 @@ func (g *Manager) SendHandshake(rp core.Engine) error {
 func (g *Manager) OnHandshake(s net.Stream, rp core.Engine) error {
 
 	msg := &core.Handshake{}
-	if err := ReadStream(s, msg); err != nil {
+	if err := ReadStream(s, handshakeMsg(s, msg)); err != nil {
 		return g.logErr(err, rp, "[OnHandshake] Failed to read message")
 	}
 
@@ -105,18 +194,32 @@ func (g *Manager) OnHandshake(s net.Stream, rp core.Engine) error {
 		"PeerName", msg.Name)
 
 	nodeMsg, err := createHandshakeMsg(&core.Handshake{
-		Version: g.engine.GetCfg().VersionInfo.BuildVersion,
-		Name:    g.engine.GetName(),
+		Version:   g.engine.GetCfg().VersionInfo.BuildVersion,
+		Name:      g.engine.GetName(),
+		NetworkID: g.engine.GetCfg().Node.NetworkID,
 	}, g.GetBlockchain().ChainReader(), g.log)
 	if err != nil {
 		return err
 	}
 
+	// Verify the peer's network and select the message
+	// versions and features to use with it. Incompatible
+	// peers are informed of the rejection in the response
+	// and do not become acquainted.
+	reject := g.acceptHandshake(rp, msg, nodeMsg)
+	nodeMsg.Reject = reject
+
 	// send back a Handshake as response
-	if err := WriteStream(s, nodeMsg); err != nil {
+	if err := WriteStream(s, handshakeMsg(s, nodeMsg)); err != nil {
 		return g.logErr(err, rp, "[OnHandshake] Failed to send response")
 	}
 
+	if reject != nil {
+		g.log.Info("Rejected handshake of incompatible peer",
+			"PeerID", rp.ShortID(), "Reason", reject.Reason)
+		return fmt.Errorf("handshake rejected: %s", reject.Reason)
+	}
+
 	rp.SetName(msg.Name)
 
 	// Set new peer as acquainted so that it will
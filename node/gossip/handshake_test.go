@@ -4,8 +4,10 @@ import (
 	"math/big"
 
 	. "github.com/ellcrys/elld/blockchain/testutil"
+	"github.com/ellcrys/elld/config"
 	"github.com/ellcrys/elld/crypto"
 	"github.com/ellcrys/elld/node"
+	"github.com/ellcrys/elld/node/gossip"
 	"github.com/ellcrys/elld/types"
 	"github.com/ellcrys/elld/types/core"
 	"github.com/ellcrys/elld/util"
@@ -75,6 +77,31 @@ var _ = Describe("Handshake", func() {
 				activePeerLp := lp.PM().GetActivePeers(0)
 				Expect(activePeerLp).To(HaveLen(1))
 			})
+
+			Specify("local peer should store the negotiated message versions", func() {
+				Expect(lp.PM().GetMsgVersion(rp, config.MsgTx)).To(Equal(uint32(2)))
+				Expect(lp.PM().GetMsgVersion(rp, config.MsgBlockBody)).To(Equal(uint32(2)))
+			})
+		})
+
+		Context("when the remote peer belongs to a different network", func() {
+
+			var err error
+
+			BeforeEach(func() {
+				rp.GetCfg().Node.NetworkID = "testnet"
+				err = lp.Gossip().SendHandshake(rp)
+			})
+
+			It("should return a handshake rejected error", func() {
+				Expect(err).ToNot(BeNil())
+				Expect(err.Error()).To(ContainSubstring("handshake rejected: network mismatch"))
+			})
+
+			Specify("local and remote peer should not be acquainted", func() {
+				Expect(lp.PM().IsAcquainted(rp)).To(BeFalse())
+				Expect(rp.PM().IsAcquainted(lp)).To(BeFalse())
+			})
 		})
 
 		Context("check that core.EventPeerChainInfo is emitted", func() {
@@ -131,4 +158,114 @@ var _ = Describe("Handshake", func() {
 		})
 	})
 
+	Describe(".OnHandshake", func() {
+
+		var resp *core.Handshake
+
+		sendHandshake := func(modify func(msg *core.Handshake)) {
+			bestBlock, err := lp.GetBlockchain().ChainReader().GetBlock(0)
+			Expect(err).To(BeNil())
+			genesis, err := lp.GetBlockchain().ChainReader().GetBlock(1)
+			Expect(err).To(BeNil())
+
+			msg := &core.Handshake{
+				Version:                  "1",
+				Name:                     lp.GetName(),
+				BestBlockHash:            bestBlock.GetHash(),
+				BestBlockNumber:          bestBlock.GetNumber(),
+				BestBlockTotalDifficulty: bestBlock.GetHeader().GetTotalDifficulty(),
+				MsgVersions:              config.GetMsgVersions(),
+				GenesisHash:              genesis.GetHash(),
+				NetworkID:                lp.GetCfg().Node.NetworkID,
+			}
+			modify(msg)
+
+			stream, cc, err := lp.Gossip().NewStream(rp, config.GetVersions().Handshake)
+			Expect(err).To(BeNil())
+			defer cc()
+			defer stream.Close()
+
+			Expect(gossip.WriteStream(stream, msg)).To(BeNil())
+			resp = &core.Handshake{}
+			Expect(gossip.ReadStream(stream, resp)).To(BeNil())
+		}
+
+		When("the genesis block hash does not match", func() {
+			BeforeEach(func() {
+				sendHandshake(func(msg *core.Handshake) {
+					msg.GenesisHash = util.StrToHash("unknown")
+				})
+			})
+
+			It("should respond with a genesis mismatch reject", func() {
+				Expect(resp.Reject).ToNot(BeNil())
+				Expect(resp.Reject.Code).To(Equal(core.RejectCodeGenesisMismatch))
+				Expect(rp.PM().IsAcquainted(lp)).To(BeFalse())
+			})
+		})
+
+		When("the network ID does not match", func() {
+			BeforeEach(func() {
+				sendHandshake(func(msg *core.Handshake) {
+					msg.NetworkID = "unknown"
+				})
+			})
+
+			It("should respond with a network mismatch reject", func() {
+				Expect(resp.Reject).ToNot(BeNil())
+				Expect(resp.Reject.Code).To(Equal(core.RejectCodeNetworkMismatch))
+			})
+		})
+
+		When("the handshake is compatible", func() {
+			BeforeEach(func() {
+				sendHandshake(func(msg *core.Handshake) {})
+			})
+
+			It("should respond without a reject", func() {
+				Expect(resp.Reject).To(BeNil())
+				Expect(resp.GenesisHash).ToNot(Equal(util.EmptyHash))
+			})
+		})
+
+		When("the handshake is of the first version", func() {
+
+			var resp *core.HandshakeV1
+
+			BeforeEach(func() {
+				bestBlock, err := lp.GetBlockchain().ChainReader().GetBlock(0)
+				Expect(err).To(BeNil())
+
+				msg := &core.HandshakeV1{
+					Version:                  "1",
+					Name:                     lp.GetName(),
+					BestBlockHash:            bestBlock.GetHash(),
+					BestBlockNumber:          bestBlock.GetNumber(),
+					BestBlockTotalDifficulty: bestBlock.GetHeader().GetTotalDifficulty(),
+				}
+
+				pid := config.GetVersions().ProtocolID(config.MsgHandshake, 1)
+				stream, cc, err := lp.Gossip().NewStream(rp, pid)
+				Expect(err).To(BeNil())
+				defer cc()
+				defer stream.Close()
+
+				Expect(gossip.WriteStream(stream, msg)).To(BeNil())
+				resp = &core.HandshakeV1{}
+				Expect(gossip.ReadStream(stream, resp)).To(BeNil())
+			})
+
+			It("should respond with a handshake of the first version", func() {
+				Expect(resp.Name).To(Equal(rp.GetName()))
+				Expect(resp.MsgVersions).To(BeNil())
+				Expect(resp.Reject).To(BeNil())
+			})
+
+			It("should select the first version of every message type", func() {
+				Eventually(func() bool { return rp.PM().IsAcquainted(lp) }).Should(BeTrue())
+				Expect(rp.PM().GetMsgVersion(lp, config.MsgTx)).To(Equal(uint32(1)))
+				Expect(rp.PM().GetMsgVersion(lp, config.MsgBlockBody)).To(Equal(uint32(1)))
+			})
+		})
+	})
 })
@@ -0,0 +1,80 @@
+package gossip
+
+import (
+	"sync"
+	"time"
+)
+
+// tokenBucket holds the tokens available to a key
+type tokenBucket struct {
+	tokens   float64
+	lastFill time.Time
+}
+
+// rateLimiter is a token bucket rate limiter. Each
+// key has a bucket of tokens that is refilled at a
+// fixed rate. A message consumes a token and is
+// rejected when the bucket is empty.
+type rateLimiter struct {
+	mtx     sync.Mutex
+	rate    float64
+	burst   float64
+	buckets map[string]*tokenBucket
+	pruned  time.Time
+	now     func() time.Time
+}
+
+// newRateLimiter creates a rateLimiter that allows rate
+// messages per second and bursts of up to burst messages
+func newRateLimiter(rate float64, burst int) *rateLimiter {
+	return &rateLimiter{
+		rate:    rate,
+		burst:   float64(burst),
+		buckets: make(map[string]*tokenBucket),
+		now:     time.Now,
+	}
+}
+
+// Allow consumes a token from the bucket of
+// key. It returns false if no token is left.
+func (r *rateLimiter) Allow(key string) bool {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	now := r.now()
+	r.prune(now)
+
+	b, ok := r.buckets[key]
+	if !ok {
+		b = &tokenBucket{tokens: r.burst, lastFill: now}
+		r.buckets[key] = b
+	}
+
+	b.tokens += now.Sub(b.lastFill).Seconds() * r.rate
+	if b.tokens > r.burst {
+		b.tokens = r.burst
+	}
+	b.lastFill = now
+
+	if b.tokens < 1 {
+		return false
+	}
+
+	b.tokens--
+	return true
+}
+
+// prune removes buckets that have refilled completely
+// since they were last used. They are equivalent to
+// the new bucket created for an unknown key.
+func (r *rateLimiter) prune(now time.Time) {
+	if now.Sub(r.pruned) < time.Minute {
+		return
+	}
+	r.pruned = now
+	for key, b := range r.buckets {
+		if b.tokens+now.Sub(b.lastFill).Seconds()*r.rate >= r.burst {
+			delete(r.buckets, key)
+		}
+	}
+}
@@ -1,8 +1,10 @@
 package gossip
 
 import (
+	"github.com/ellcrys/elld/blockchain"
 	"github.com/ellcrys/elld/config"
 	"github.com/ellcrys/elld/node/common"
+	"github.com/ellcrys/elld/node/peermanager"
 	"github.com/ellcrys/elld/types"
 	"github.com/ellcrys/elld/types/core"
 	"github.com/ellcrys/elld/util"
@@ -13,13 +15,12 @@ import (
 // OnTx handles incoming transaction message
 func (g *Manager) OnTx(s net.Stream, rp core.Engine) error {
 
-	var txID string
 	tx := &core.Transaction{}
-	var hk []interface{}
 
 	msg := &core.TxInfo{}
 	if err := ReadStream(s, msg); err != nil {
 		s.Reset()
+		g.penalizeReadErr(rp, err, "malformed TxInfo message")
 		return g.logErr(err, rp, "[OnTx] Failed to read TxInfo message")
 	}
 
@@ -46,17 +47,11 @@ func (g *Manager) OnTx(s net.Stream, rp core.Engine) error {
 	// At this point, we expect the peer to send the transaction
 	if err := ReadStream(s, tx); err != nil {
 		s.Reset()
+		g.penalizeReadErr(rp, err, "malformed transaction message")
 		return g.logErr(err, rp, "[OnTx] Failed to read")
 	}
 
-	txID = util.String(tx.GetID()).SS()
-	g.log.Info("Received a new transaction", "PeerID", rp.ShortID(), "TxID", txID)
-	go g.engine.GetEventEmitter().Emit(core.EventTransactionReceived, tx)
-
-	// Keep a record of us receiving this transaction,
-	// so that we won't rebroadcast it to the sender
-	hk = common.KeyTx(tx, rp)
-	g.engine.GetHistory().AddMulti(cache.Sec(600), hk...)
+	g.processReceivedTx(tx, rp)
 
 tx_not_ok:
 	if err := WriteStream(s, &core.TxOk{Ok: false}); err != nil {
@@ -67,6 +62,34 @@ tx_not_ok:
 	return s.Close()
 }
 
+// processReceivedTx validates the fields of a transaction
+// received from a remote peer and emits an event to have
+// it added to the pool. The sender of an invalid
+// transaction is penalized.
+func (g *Manager) processReceivedTx(tx *core.Transaction, rp core.Engine) bool {
+
+	// A transaction whose fields are not valid
+	// is rejected and the sender is penalized
+	if errs := blockchain.NewTxValidator(tx, g.engine.GetTxPool(),
+		g.GetBlockchain()).CheckFields(tx); len(errs) > 0 {
+		g.PM().Penalize(rp, peermanager.PenaltyInvalidTx, "invalid transaction")
+		go g.engine.GetEventEmitter().Emit(core.EventTransactionInvalid, tx, errs[0])
+		g.logErr(errs[0], rp, "Received an invalid transaction")
+		return false
+	}
+
+	txID := util.String(tx.GetID()).SS()
+	g.log.Info("Received a new transaction", "PeerID", rp.ShortID(), "TxID", txID)
+	go g.engine.GetEventEmitter().Emit(core.EventTransactionReceived, tx)
+
+	// Keep a record of us receiving this transaction,
+	// so that we won't rebroadcast it to the sender
+	hk := common.KeyTx(tx, rp)
+	g.engine.GetHistory().AddMulti(cache.Sec(600), hk...)
+
+	return true
+}
+
 // BroadcastTx broadcast transactions to selected peers
 func (g *Manager) BroadcastTx(tx types.Transaction, remotePeers []core.Engine) error {
 
@@ -94,7 +117,16 @@ func (g *Manager) BroadcastTx(tx types.Transaction, remotePeers []core.Engine) e
 			continue
 		}
 
-		s, c, err := g.NewStream(peer, config.GetVersions().Tx)
+		// Peers that support transaction inventories
+		// will have the transaction announced in the
+		// next batch.
+		if g.PM().HasFeature(peer, config.FeatureTxInv) {
+			g.queueTxInv(peer, tx.GetHash())
+			sent++
+			continue
+		}
+
+		s, c, err := g.NewStream(peer, g.ProtocolID(peer, config.MsgTx))
 		if err != nil {
 			g.logConnectErr(err, peer, "[BroadcastTx] Failed to connect")
 			continue
@@ -0,0 +1,247 @@
+package gossip
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ellcrys/elld/config"
+	"github.com/ellcrys/elld/node/peermanager"
+	"github.com/ellcrys/elld/params"
+	"github.com/ellcrys/elld/types/core"
+	"github.com/ellcrys/elld/util"
+	net "github.com/libp2p/go-libp2p-net"
+)
+
+// txInvQueue holds transaction hashes
+// waiting to be announced to a peer
+type txInvQueue struct {
+	peer    core.Engine
+	hashes  []util.Hash
+	sending bool // true while a TxInv is being sent to the peer
+}
+
+// queueTxInv adds a transaction hash to the queue of
+// hashes to announce to a peer. The hash is dropped
+// if the queue of the peer is full.
+func (g *Manager) queueTxInv(peer core.Engine, hash util.Hash) {
+	g.txInvMtx.Lock()
+	defer g.txInvMtx.Unlock()
+
+	q, ok := g.txInv[peer.StringID()]
+	if !ok {
+		q = &txInvQueue{peer: peer}
+		g.txInv[peer.StringID()] = q
+	}
+
+	if len(q.hashes) >= params.MaxTxInvQueueSize {
+		g.log.Debug("Transaction inventory queue is full. Dropping hash",
+			"PeerID", peer.ShortID(), "TxHash", hash.SS())
+		return
+	}
+
+	q.hashes = append(q.hashes, hash)
+}
+
+// takeTxInvs removes and returns up to params.MaxTxInvSize
+// hashes from the queue of every peer. Peers that are still
+// being sent a previous TxInv are skipped; their hashes
+// remain queued until the send completes.
+func (g *Manager) takeTxInvs() map[core.Engine][]util.Hash {
+	g.txInvMtx.Lock()
+	defer g.txInvMtx.Unlock()
+
+	invs := make(map[core.Engine][]util.Hash)
+	for _, q := range g.txInv {
+		if q.sending || len(q.hashes) == 0 {
+			continue
+		}
+		n := len(q.hashes)
+		if n > params.MaxTxInvSize {
+			n = params.MaxTxInvSize
+		}
+		invs[q.peer] = q.hashes[:n]
+		q.hashes = q.hashes[n:]
+		q.sending = true
+	}
+
+	return invs
+}
+
+// txInvSent marks the TxInv sent to a peer as
+// complete. The queue of the peer is removed
+// if no hashes are waiting to be announced.
+func (g *Manager) txInvSent(peer core.Engine) {
+	g.txInvMtx.Lock()
+	defer g.txInvMtx.Unlock()
+
+	q, ok := g.txInv[peer.StringID()]
+	if !ok {
+		return
+	}
+
+	q.sending = false
+	if len(q.hashes) == 0 {
+		delete(g.txInv, peer.StringID())
+	}
+}
+
+// doTxInv periodically sends the queued
+// transaction hashes to peers
+func (g *Manager) doTxInv(done chan bool) {
+	ticker := time.NewTicker(params.TxInvInterval)
+	for {
+		select {
+		case <-ticker.C:
+			for peer, hashes := range g.takeTxInvs() {
+				go func(peer core.Engine, hashes []util.Hash) {
+					g.SendTxInv(peer, hashes)
+					g.txInvSent(peer)
+				}(peer, hashes)
+			}
+		case <-done:
+			ticker.Stop()
+			return
+		}
+	}
+}
+
+// SendTxInv announces transaction hashes to a remote
+// peer. The remote peer responds with a GetTxs message
+// requesting the transactions it does not have.
+func (g *Manager) SendTxInv(rp core.Engine, hashes []util.Hash) error {
+
+	s, c, err := g.NewStream(rp, g.ProtocolID(rp, config.MsgTxInv))
+	if err != nil {
+		return g.logConnectErr(err, rp, "[SendTxInv] Failed to connect to peer")
+	}
+	defer c()
+	defer s.Close()
+
+	if err := WriteStream(s, &core.TxInv{Hashes: hashes}); err != nil {
+		s.Reset()
+		return g.logErr(err, rp, "[SendTxInv] Failed to write TxInv")
+	}
+
+	req := &core.GetTxs{}
+	if err := ReadStream(s, req); err != nil {
+		s.Reset()
+		return g.logErr(err, rp, "[SendTxInv] Failed to read GetTxs")
+	}
+
+	if len(req.Hashes) == 0 {
+		return nil
+	}
+
+	// Only transactions that were announced can be
+	// requested. Transactions that left the pool since
+	// they were announced are skipped.
+	announced := make(map[util.Hash]struct{}, len(hashes))
+	for _, h := range hashes {
+		announced[h] = struct{}{}
+	}
+
+	resp := &core.Txs{}
+	for _, h := range req.Hashes {
+		if _, ok := announced[h]; !ok {
+			s.Reset()
+			g.PM().Penalize(rp, peermanager.PenaltyBadRequest, "unannounced transaction requested")
+			return g.logErr(fmt.Errorf("unannounced transaction requested"), rp,
+				"[SendTxInv] Invalid GetTxs request")
+		}
+		if tx := g.engine.GetTxPool().GetByHash(h.HexStr()); tx != nil {
+			resp.Txs = append(resp.Txs, tx.(*core.Transaction))
+		}
+	}
+
+	if err := WriteStream(s, resp); err != nil {
+		s.Reset()
+		return g.logErr(err, rp, "[SendTxInv] Failed to write Txs")
+	}
+
+	g.log.Debug("Sent announced transactions",
+		"PeerID", rp.ShortID(),
+		"NumAnnounced", len(hashes),
+		"NumSent", len(resp.Txs))
+
+	return nil
+}
+
+// OnTxInv handles incoming TxInv messages. It requests
+// the announced transactions that are neither in the
+// pool nor on the main chain.
+func (g *Manager) OnTxInv(s net.Stream, rp core.Engine) error {
+
+	defer s.Close()
+
+	msg := &core.TxInv{}
+	if err := ReadStream(s, msg); err != nil {
+		s.Reset()
+		g.penalizeReadErr(rp, err, "malformed TxInv message")
+		return g.logErr(err, rp, "[OnTxInv] Failed to read TxInv")
+	}
+
+	if len(msg.Hashes) > params.MaxTxInvSize {
+		s.Reset()
+		g.PM().Penalize(rp, peermanager.PenaltyBadRequest, "too many transactions announced")
+		return g.logErr(fmt.Errorf("too many transactions announced"), rp,
+			"[OnTxInv] Invalid TxInv message")
+	}
+
+	req := &core.GetTxs{}
+	requested := make(map[util.Hash]struct{})
+	for _, h := range msg.Hashes {
+		if _, ok := requested[h]; ok {
+			continue
+		}
+		if g.engine.GetTxPool().HasByHash(h.HexStr()) {
+			continue
+		}
+		if existingTx, _ := g.engine.GetBlockchain().GetTransaction(h); existingTx != nil {
+			continue
+		}
+		requested[h] = struct{}{}
+		req.Hashes = append(req.Hashes, h)
+	}
+
+	if err := WriteStream(s, req); err != nil {
+		s.Reset()
+		return g.logErr(err, rp, "[OnTxInv] Failed to write GetTxs")
+	}
+
+	if len(req.Hashes) == 0 {
+		return nil
+	}
+
+	resp := &core.Txs{}
+	if err := ReadStream(s, resp); err != nil {
+		s.Reset()
+		g.penalizeReadErr(rp, err, "malformed Txs message")
+		return g.logErr(err, rp, "[OnTxInv] Failed to read Txs")
+	}
+
+	malformed := false
+	for _, tx := range resp.Txs {
+
+		// The peer must only send
+		// the requested transactions
+		if tx == nil {
+			malformed = true
+			continue
+		}
+		if _, ok := requested[tx.GetHash()]; !ok {
+			malformed = true
+			continue
+		}
+		delete(requested, tx.GetHash())
+
+		g.processReceivedTx(tx, rp)
+	}
+
+	// Penalize the peer once regardless of
+	// the number of invalid transactions
+	if malformed {
+		g.PM().Penalize(rp, peermanager.PenaltyMalformedMsg, "malformed Txs message")
+	}
+
+	return nil
+}
@@ -0,0 +1,121 @@
+package gossip_test
+
+import (
+	"time"
+
+	"github.com/ellcrys/elld/config"
+	"github.com/ellcrys/elld/crypto"
+	"github.com/ellcrys/elld/node"
+	"github.com/ellcrys/elld/node/gossip"
+	"github.com/ellcrys/elld/node/peermanager"
+	"github.com/ellcrys/elld/types/core"
+	"github.com/ellcrys/elld/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("TxInv", func() {
+
+	var lp, rp *node.Node
+	var sender, _ = crypto.NewKey(nil)
+	var receiver, _ = crypto.NewKey(nil)
+	var tx *core.Transaction
+
+	BeforeEach(func() {
+		lp = makeTestNode(getPort())
+		Expect(lp.GetBlockchain().Up()).To(BeNil())
+
+		rp = makeTestNode(getPort())
+		Expect(rp.GetBlockchain().Up()).To(BeNil())
+
+		tx = core.NewTransaction(core.TxTypeBalance, 1, util.String(receiver.Addr()),
+			util.String(sender.PubKey().Base58()), "1", "2.4", time.Now().Unix())
+		tx.From = util.String(sender.Addr())
+		tx.Hash = tx.ComputeHash()
+		tx.Sig, _ = core.TxSign(tx, sender.PrivKey().Base58())
+		Expect(lp.GetTxPool().Put(tx)).To(BeNil())
+	})
+
+	AfterEach(func() {
+		closeNode(lp)
+		closeNode(rp)
+	})
+
+	Describe(".SendHandshake", func() {
+		It("should negotiate the transaction inventory feature", func() {
+			Expect(lp.Gossip().SendHandshake(rp)).To(BeNil())
+			Expect(lp.PM().HasFeature(rp, config.FeatureTxInv)).To(BeTrue())
+		})
+	})
+
+	Describe(".SendTxInv", func() {
+
+		When("the remote peer does not have the transaction", func() {
+			It("should request and receive the transaction", func(done Done) {
+				wait := make(chan bool)
+
+				go func() {
+					defer GinkgoRecover()
+					evt := <-rp.GetEventEmitter().Once(core.EventTransactionReceived)
+					received := evt.Args[0].(*core.Transaction)
+					Expect(received.GetHash()).To(Equal(tx.GetHash()))
+					close(wait)
+				}()
+
+				err := lp.Gossip().SendTxInv(rp, []util.Hash{tx.GetHash()})
+				Expect(err).To(BeNil())
+				<-wait
+				close(done)
+			})
+		})
+
+		When("the remote peer already has the transaction", func() {
+			BeforeEach(func() {
+				Expect(rp.GetTxPool().Put(tx)).To(BeNil())
+			})
+
+			It("should not request the transaction", func() {
+				received := false
+				go func() {
+					<-rp.GetEventEmitter().Once(core.EventTransactionReceived)
+					received = true
+				}()
+
+				err := lp.Gossip().SendTxInv(rp, []util.Hash{tx.GetHash()})
+				Expect(err).To(BeNil())
+				time.Sleep(100 * time.Millisecond)
+				Expect(received).To(BeFalse())
+			})
+		})
+	})
+
+	Describe(".OnTxInv", func() {
+
+		When("the sender responds with a nil and an unrequested transaction", func() {
+			It("should penalize the sender once", func() {
+				s, c, err := lp.Gossip().NewStream(rp, lp.Gossip().ProtocolID(rp, config.MsgTxInv))
+				Expect(err).To(BeNil())
+				defer c()
+				defer s.Close()
+
+				Expect(gossip.WriteStream(s, &core.TxInv{Hashes: []util.Hash{tx.GetHash()}})).To(BeNil())
+				req := &core.GetTxs{}
+				Expect(gossip.ReadStream(s, req)).To(BeNil())
+				Expect(req.Hashes).To(Equal([]util.Hash{tx.GetHash()}))
+
+				unrequested := core.NewTransaction(core.TxTypeBalance, 2, util.String(receiver.Addr()),
+					util.String(sender.PubKey().Base58()), "1", "2.4", time.Now().Unix())
+				unrequested.Hash = unrequested.ComputeHash()
+				resp := &core.Txs{Txs: []*core.Transaction{nil, unrequested}}
+				Expect(gossip.WriteStream(s, resp)).To(BeNil())
+
+				Eventually(func() int {
+					return rp.PM().GetScore(lp)
+				}).Should(Equal(peermanager.PenaltyMalformedMsg))
+				Consistently(func() int {
+					return rp.PM().GetScore(lp)
+				}, 200*time.Millisecond).Should(Equal(peermanager.PenaltyMalformedMsg))
+			})
+		})
+	})
+})
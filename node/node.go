@@ -66,6 +66,7 @@ type Node struct {
 	inbound             bool                // Indicates this that this node initiated the connection with the local node
 	blockManager        *BlockManager       // Block manager for handling block events
 	txManager           *TxManager          // Transaction manager for handling transaction events
+	feeEstimator        *FeeEstimator       // Fee estimator for recommending transaction fees
 	noNet               bool                // Indicates whether the host is listening for connections
 	Name                string              // Random name for this node
 	hardcodedPeers      map[string]struct{} // A collection of seed peers that were manually provided
@@ -130,22 +131,33 @@ func newNode(db elldb.DB, cfg *config.EngineConfig, address string,
 	g := gossip.NewGossip(node, log)
 	g.SetPeerManager(node.peerManager)
 	node.SetGossipManager(g)
-	node.SetProtocolHandler(config.GetVersions().Handshake, g.Handle(g.OnHandshake))
-	node.SetProtocolHandler(config.GetVersions().Ping, g.Handle(g.OnPing))
-	node.SetProtocolHandler(config.GetVersions().GetAddr, g.Handle(g.OnGetAddr))
-	node.SetProtocolHandler(config.GetVersions().Addr, g.Handle(g.OnAddr))
-	node.SetProtocolHandler(config.GetVersions().Tx, g.Handle(g.OnTx))
-	node.SetProtocolHandler(config.GetVersions().BlockInfo, g.Handle(g.OnBlockInfo))
-	node.SetProtocolHandler(config.GetVersions().BlockBody, g.Handle(g.OnBlockBody))
-	node.SetProtocolHandler(config.GetVersions().RequestBlock, g.Handle(g.OnRequestBlock))
-	node.SetProtocolHandler(config.GetVersions().GetBlockHashes, g.Handle(g.OnGetBlockHashes))
-	node.SetProtocolHandler(config.GetVersions().GetBlockBodies, g.Handle(g.OnGetBlockBodies))
+	node.setMsgHandler(config.MsgHandshake, g.Handle(g.OnHandshake))
+	node.setMsgHandler(config.MsgPing, g.Handle(g.OnPing))
+	node.setMsgHandler(config.MsgGetAddr, g.Handle(g.OnGetAddr))
+	node.setMsgHandler(config.MsgAddr, g.Handle(g.OnAddr))
+	node.setMsgHandler(config.MsgTx, g.Handle(g.OnTx))
+	node.setMsgHandler(config.MsgBlockInfo, g.Handle(g.OnBlockInfo))
+	node.setMsgHandler(config.MsgBlockBody, g.Handle(g.OnBlockBody))
+	node.setMsgHandler(config.MsgRequestBlock, g.Handle(g.OnRequestBlock))
+	node.setMsgHandler(config.MsgGetBlockHashes, g.Handle(g.OnGetBlockHashes))
+	node.setMsgHandler(config.MsgGetBlockBodies, g.Handle(g.OnGetBlockBodies))
+	node.setMsgHandler(config.MsgCompactBlock, g.Handle(g.OnCompactBlock))
+	node.setMsgHandler(config.MsgTxInv, g.Handle(g.OnTxInv))
+	node.setMsgHandler(config.MsgFindPeers, g.Handle(g.OnFindPeers))
 
 	log.Info("Opened local database", "Backend", "LevelDB")
 
 	return node, nil
 }
 
+// setMsgHandler sets the handler of every
+// supported version of a message type
+func (n *Node) setMsgHandler(msgType string, handler inet.StreamHandler) {
+	for _, version := range config.GetMsgVersions()[msgType] {
+		n.SetProtocolHandler(config.GetVersions().ProtocolID(msgType, version), handler)
+	}
+}
+
 // GetListenAddresses gets the address at which the node listens
 func (n *Node) GetListenAddresses() (addrs []util.NodeAddr) {
 	lAddrs, _ := n.host.Network().InterfaceListenAddresses()
@@ -421,6 +433,16 @@ func (n *Node) SetTxManager(tm *TxManager) {
 	n.txManager = tm
 }
 
+// GetTxManager returns the transaction manager
+func (n *Node) GetTxManager() *TxManager {
+	return n.txManager
+}
+
+// SetFeeEstimator sets the fee estimator
+func (n *Node) SetFeeEstimator(fe *FeeEstimator) {
+	n.feeEstimator = fe
+}
+
 // SetLocalNode sets the node as the
 // local node to n which makes n the "remote" node
 func (n *Node) SetLocalNode(node *Node) {
@@ -596,6 +618,9 @@ func (n *Node) Start() {
 	// Start the peer manager
 	n.PM().Manage()
 
+	// Start the gossip manager
+	n.gossipMgr.Manage()
+
 	if n.noNet {
 		return
 	}
@@ -632,6 +657,16 @@ func (n *Node) Stop() {
 		pm.Stop()
 	}
 
+	// stop the gossip manager's routines
+	if n.gossipMgr != nil {
+		n.gossipMgr.Stop()
+	}
+
+	// stop the transaction manager's routines
+	if n.txManager != nil {
+		n.txManager.Stop()
+	}
+
 	// Shut down the host
 	if n.host != nil {
 		n.host.Close()
@@ -2,10 +2,13 @@ package node
 
 import (
 	"context"
+	"encoding/json"
 
 	"github.com/ellcrys/elld/config"
 	"github.com/ellcrys/elld/crypto"
+	"github.com/ellcrys/elld/rpc"
 	"github.com/ellcrys/elld/testutil"
+	"github.com/ellcrys/elld/types"
 	"github.com/ellcrys/elld/util"
 	host "github.com/libp2p/go-libp2p-host"
 	pstore "github.com/libp2p/go-libp2p-peerstore"
@@ -203,4 +206,36 @@ var _ = Describe("NodeUnitTest", func() {
 		})
 	})
 
+	Describe(".apiGetConfig", func() {
+
+		BeforeEach(func() {
+			cfg.RPC = &config.RPCConfig{
+				Password:         "admin-pass",
+				SessionSecretKey: "session-secret",
+				Users: []*config.RPCUser{
+					{Username: "reader", Password: "reader-pass", Role: rpc.RoleReadOnly},
+				},
+			}
+		})
+
+		It("should not be callable by non-admin roles", func() {
+			roles := rpc.Roles()
+			for _, role := range []string{rpc.RoleReadOnly, rpc.RoleWallet, rpc.RoleMinerOperator} {
+				Expect(roles[role].Permits(types.NamespaceNode+"_config", types.NamespaceNode)).To(BeFalse())
+			}
+			Expect(roles[rpc.RoleAdmin].Permits(types.NamespaceNode+"_config", types.NamespaceNode)).To(BeTrue())
+		})
+
+		It("should not include secrets in the result", func() {
+			resp := n.apiGetConfig(nil)
+			Expect(resp.Err).To(BeNil())
+			data, err := json.Marshal(resp.Result)
+			Expect(err).To(BeNil())
+			Expect(string(data)).ToNot(ContainSubstring("admin-pass"))
+			Expect(string(data)).ToNot(ContainSubstring("session-secret"))
+			Expect(string(data)).ToNot(ContainSubstring("reader-pass"))
+			Expect(string(data)).To(ContainSubstring("reader"))
+		})
+	})
+
 })
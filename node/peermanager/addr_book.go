@@ -0,0 +1,392 @@
+package peermanager
+
+import (
+	"encoding/binary"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/ellcrys/elld/util"
+)
+
+const (
+	// newBucketCount is the number of buckets
+	// holding addresses that have not been tried
+	newBucketCount = 1024
+
+	// newBucketSize is the maximum number
+	// of addresses in a new bucket
+	newBucketSize = 64
+
+	// newBucketsPerGroup is the number of new buckets
+	// the addresses from a source group can occupy
+	newBucketsPerGroup = 64
+
+	// triedBucketCount is the number of buckets holding
+	// addresses we have successfully connected to
+	triedBucketCount = 64
+
+	// triedBucketSize is the maximum number
+	// of addresses in a tried bucket
+	triedBucketSize = 256
+
+	// triedBucketsPerGroup is the number of tried buckets
+	// the addresses of a network group can occupy
+	triedBucketsPerGroup = 8
+
+	// maxPickTries is the number of random picks to
+	// try before picking an address by a linear scan
+	maxPickTries = 1000
+)
+
+// knownAddress describes an address in the address book
+type knownAddress struct {
+	addr        util.NodeAddr
+	src         util.NodeAddr
+	addedAt     time.Time
+	attempts    int
+	lastAttempt time.Time
+	lastSuccess time.Time
+	tried       bool
+}
+
+// AddrBook is an address book that stores the addresses
+// of known peers in "new" and "tried" buckets. Addresses
+// that have not been connected to are placed in a new
+// bucket determined by the network group of the address
+// and of the peer that sent it. Addresses that have been
+// connected to are moved to a tried bucket determined by
+// the network group of the address. Since a network group
+// can only occupy a few buckets, a peer controlling many
+// addresses in a few network groups cannot fill the book.
+type AddrBook struct {
+	mtx          sync.RWMutex
+	key          []byte
+	index        map[string]*knownAddress
+	newBuckets   [newBucketCount]map[string]*knownAddress
+	triedBuckets [triedBucketCount]map[string]*knownAddress
+	nNew         int
+	nTried       int
+}
+
+// NewAddrBook creates an AddrBook. The key is
+// used to randomize the placement of addresses
+// in buckets and must be kept secret.
+func NewAddrBook(key []byte) *AddrBook {
+	return &AddrBook{
+		key:   key,
+		index: make(map[string]*knownAddress),
+	}
+}
+
+// hashMod hashes the key of the address book and the given
+// values and returns the result modulo n.
+func (b *AddrBook) hashMod(n uint64, values ...[]byte) uint64 {
+	data := append([]byte{}, b.key...)
+	for _, v := range values {
+		data = append(data, v...)
+	}
+	return binary.BigEndian.Uint64(util.Blake2b256(data)[:8]) % n
+}
+
+// uint64ToBytes returns the big-endian encoding of n
+func uint64ToBytes(n uint64) []byte {
+	bs := make([]byte, 8)
+	binary.BigEndian.PutUint64(bs, n)
+	return bs
+}
+
+// newBucketIndex returns the index of the new bucket of
+// an address. It is determined by the network groups of
+// the address and of its source.
+func (b *AddrBook) newBucketIndex(addr, src util.NodeAddr) int {
+	addrGroup := []byte(util.GroupKey(addr.IP()))
+	srcGroup := []byte(util.GroupKey(src.IP()))
+	h := b.hashMod(newBucketsPerGroup, addrGroup, srcGroup)
+	return int(b.hashMod(newBucketCount, srcGroup, uint64ToBytes(h)))
+}
+
+// triedBucketIndex returns the index of the tried bucket
+// of an address. It is determined by the address and
+// its network group.
+func (b *AddrBook) triedBucketIndex(addr util.NodeAddr) int {
+	addrGroup := []byte(util.GroupKey(addr.IP()))
+	h := b.hashMod(triedBucketsPerGroup, []byte(addr.StringID()))
+	return int(b.hashMod(triedBucketCount, addrGroup, uint64ToBytes(h)))
+}
+
+// Add adds an address received from src to a new bucket.
+// If the bucket is full, its worst address is evicted.
+// It returns false if the address is already known and
+// the ID of the evicted address, if any.
+func (b *AddrBook) Add(addr, src util.NodeAddr) (added bool, evicted string) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	id := addr.StringID()
+	if _, ok := b.index[id]; ok {
+		return false, ""
+	}
+
+	ka := &knownAddress{addr: addr, src: src, addedAt: time.Now()}
+	evicted = b.addNew(ka)
+	b.index[id] = ka
+
+	return true, evicted
+}
+
+// addNew places an address in its new bucket.
+// It returns the ID of the evicted address, if any.
+func (b *AddrBook) addNew(ka *knownAddress) (evicted string) {
+
+	idx := b.newBucketIndex(ka.addr, ka.src)
+	if b.newBuckets[idx] == nil {
+		b.newBuckets[idx] = make(map[string]*knownAddress)
+	}
+
+	bucket := b.newBuckets[idx]
+	if len(bucket) >= newBucketSize {
+		worst := worstAddress(bucket)
+		delete(bucket, worst.addr.StringID())
+		delete(b.index, worst.addr.StringID())
+		b.nNew--
+		evicted = worst.addr.StringID()
+	}
+
+	ka.tried = false
+	bucket[ka.addr.StringID()] = ka
+	b.nNew++
+
+	return
+}
+
+// worstAddress returns the address in a bucket with the most
+// failed connection attempts. The oldest address is
+// returned when addresses have equal attempts.
+func worstAddress(bucket map[string]*knownAddress) (worst *knownAddress) {
+	for _, ka := range bucket {
+		if worst == nil || ka.attempts > worst.attempts ||
+			(ka.attempts == worst.attempts && ka.addedAt.Before(worst.addedAt)) {
+			worst = ka
+		}
+	}
+	return
+}
+
+// Good marks an address as successfully connected to and
+// moves it to a tried bucket. If the tried bucket is full,
+// its least recently successful address is moved back to
+// a new bucket. It returns the ID of an address evicted
+// from the book, if any.
+func (b *AddrBook) Good(addr util.NodeAddr) (evicted string) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	ka, ok := b.index[addr.StringID()]
+	if !ok {
+		return ""
+	}
+
+	ka.attempts = 0
+	ka.lastSuccess = time.Now()
+	if ka.tried {
+		return ""
+	}
+
+	delete(b.newBuckets[b.newBucketIndex(ka.addr, ka.src)], ka.addr.StringID())
+	b.nNew--
+
+	idx := b.triedBucketIndex(ka.addr)
+	if b.triedBuckets[idx] == nil {
+		b.triedBuckets[idx] = make(map[string]*knownAddress)
+	}
+
+	bucket := b.triedBuckets[idx]
+	if len(bucket) >= triedBucketSize {
+		var oldest *knownAddress
+		for _, t := range bucket {
+			if oldest == nil || t.lastSuccess.Before(oldest.lastSuccess) {
+				oldest = t
+			}
+		}
+		delete(bucket, oldest.addr.StringID())
+		b.nTried--
+		evicted = b.addNew(oldest)
+	}
+
+	ka.tried = true
+	bucket[ka.addr.StringID()] = ka
+	b.nTried++
+
+	return evicted
+}
+
+// get returns a copy of the address of a peer
+func (b *AddrBook) get(peerID string) (knownAddress, bool) {
+	b.mtx.RLock()
+	defer b.mtx.RUnlock()
+	ka, ok := b.index[peerID]
+	if !ok {
+		return knownAddress{}, false
+	}
+	return *ka, true
+}
+
+// restore places an address loaded from
+// the database in a new or tried bucket
+func (b *AddrBook) restore(ka *knownAddress) (evicted string) {
+
+	tried, attempts, lastSuccess := ka.tried, ka.attempts, ka.lastSuccess
+
+	b.mtx.Lock()
+	if _, ok := b.index[ka.addr.StringID()]; ok {
+		b.mtx.Unlock()
+		return ""
+	}
+	evicted = b.addNew(ka)
+	b.index[ka.addr.StringID()] = ka
+	b.mtx.Unlock()
+
+	if tried {
+		if e := b.Good(ka.addr); e != "" {
+			evicted = e
+		}
+	}
+
+	b.mtx.Lock()
+	ka.attempts, ka.lastSuccess = attempts, lastSuccess
+	b.mtx.Unlock()
+
+	return
+}
+
+// Attempt records a connection attempt to an address
+func (b *AddrBook) Attempt(addr util.NodeAddr) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	if ka, ok := b.index[addr.StringID()]; ok {
+		ka.attempts++
+		ka.lastAttempt = time.Now()
+	}
+}
+
+// Remove removes the address of a peer
+func (b *AddrBook) Remove(peerID string) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	ka, ok := b.index[peerID]
+	if !ok {
+		return
+	}
+
+	if ka.tried {
+		delete(b.triedBuckets[b.triedBucketIndex(ka.addr)], peerID)
+		b.nTried--
+	} else {
+		delete(b.newBuckets[b.newBucketIndex(ka.addr, ka.src)], peerID)
+		b.nNew--
+	}
+
+	delete(b.index, peerID)
+}
+
+// Has checks whether the address of a peer is in the book
+func (b *AddrBook) Has(peerID string) bool {
+	b.mtx.RLock()
+	defer b.mtx.RUnlock()
+	_, ok := b.index[peerID]
+	return ok
+}
+
+// IsTried checks whether the address
+// of a peer is in a tried bucket
+func (b *AddrBook) IsTried(peerID string) bool {
+	b.mtx.RLock()
+	defer b.mtx.RUnlock()
+	ka, ok := b.index[peerID]
+	return ok && ka.tried
+}
+
+// Size returns the number of new
+// and tried addresses in the book
+func (b *AddrBook) Size() (nNew, nTried int) {
+	b.mtx.RLock()
+	defer b.mtx.RUnlock()
+	return b.nNew, b.nTried
+}
+
+// Pick returns a random address. Tried and new addresses
+// are equally likely to be picked. Addresses with many
+// recent failed attempts are less likely to be picked.
+// It returns an empty address if the book is empty.
+func (b *AddrBook) Pick() util.NodeAddr {
+	b.mtx.RLock()
+	defer b.mtx.RUnlock()
+
+	if b.nNew+b.nTried == 0 {
+		return ""
+	}
+
+	buckets := b.newBuckets[:]
+	if b.nTried > 0 && (b.nNew == 0 || rand.Intn(2) == 0) {
+		buckets = b.triedBuckets[:]
+	}
+
+	// Increase the chance factor after every
+	// rejected address so that addresses with
+	// low chances are eventually picked
+	factor := 1.0
+	for n := 0; n < maxPickTries; n++ {
+		bucket := buckets[rand.Intn(len(buckets))]
+		if len(bucket) == 0 {
+			continue
+		}
+		i := rand.Intn(len(bucket))
+		for _, ka := range bucket {
+			if i > 0 {
+				i--
+				continue
+			}
+			if rand.Float64() < factor*ka.chance() {
+				return ka.addr
+			}
+			break
+		}
+		factor *= 1.2
+	}
+
+	// Pick the address with the highest chance
+	// when random picks keep landing on empty
+	// buckets of a sparse book
+	var best *knownAddress
+	for _, bucket := range buckets {
+		for _, ka := range bucket {
+			if best == nil || ka.chance() > best.chance() {
+				best = ka
+			}
+		}
+	}
+	if best == nil {
+		return ""
+	}
+	return best.addr
+}
+
+// chance returns the relative chance of an address being
+// picked. It is reduced for every failed attempt and for
+// addresses that were attempted in the last 10 minutes.
+func (ka *knownAddress) chance() float64 {
+	c := 1.0
+	if time.Since(ka.lastAttempt) < 10*time.Minute {
+		c *= 0.01
+	}
+	attempts := ka.attempts
+	if attempts > 8 {
+		attempts = 8
+	}
+	for i := 0; i < attempts; i++ {
+		c /= 1.5
+	}
+	return c
+}
@@ -0,0 +1,137 @@
+package peermanager_test
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ellcrys/elld/crypto"
+	"github.com/ellcrys/elld/node"
+	"github.com/ellcrys/elld/node/peermanager"
+	"github.com/ellcrys/elld/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func makeAddr(ip string, seed int) util.NodeAddr {
+	id := crypto.NewKeyFromIntSeed(seed).PeerID()
+	return util.NodeAddr(fmt.Sprintf("/ip4/%s/tcp/9000/ipfs/%s", ip, id))
+}
+
+var _ = Describe("AddrBook", func() {
+
+	var book *peermanager.AddrBook
+	var src = makeAddr("12.1.2.3", 1)
+
+	BeforeEach(func() {
+		book = peermanager.NewAddrBook(util.RandBytes(32))
+	})
+
+	Describe(".Add", func() {
+		It("should add an address to a new bucket", func() {
+			added, evicted := book.Add(makeAddr("13.1.2.3", 2), src)
+			Expect(added).To(BeTrue())
+			Expect(evicted).To(BeEmpty())
+			nNew, nTried := book.Size()
+			Expect(nNew).To(Equal(1))
+			Expect(nTried).To(Equal(0))
+		})
+
+		It("should not add a known address", func() {
+			book.Add(makeAddr("13.1.2.3", 2), src)
+			added, _ := book.Add(makeAddr("13.1.2.3", 2), src)
+			Expect(added).To(BeFalse())
+		})
+
+		It("should limit the number of addresses from a network group", func() {
+			for i := 0; i < 4200; i++ {
+				book.Add(makeAddr(fmt.Sprintf("13.1.%d.%d", i/250, i%250+1), i+2), src)
+			}
+			nNew, _ := book.Size()
+			Expect(nNew).To(BeNumerically("<=", 64*64))
+		})
+	})
+
+	Describe(".Good", func() {
+		It("should move the address to a tried bucket", func() {
+			addr := makeAddr("13.1.2.3", 2)
+			book.Add(addr, src)
+			book.Good(addr)
+			Expect(book.IsTried(addr.StringID())).To(BeTrue())
+			nNew, nTried := book.Size()
+			Expect(nNew).To(Equal(0))
+			Expect(nTried).To(Equal(1))
+		})
+	})
+
+	Describe(".Remove", func() {
+		It("should remove the address", func() {
+			addr := makeAddr("13.1.2.3", 2)
+			book.Add(addr, src)
+			book.Good(addr)
+			book.Remove(addr.StringID())
+			Expect(book.Has(addr.StringID())).To(BeFalse())
+			nNew, nTried := book.Size()
+			Expect(nNew + nTried).To(Equal(0))
+		})
+	})
+
+	Describe(".Pick", func() {
+		It("should return empty address when the book is empty", func() {
+			Expect(book.Pick()).To(BeEmpty())
+		})
+
+		It("should return a known address", func() {
+			addr := makeAddr("13.1.2.3", 2)
+			addr2 := makeAddr("14.1.2.3", 3)
+			book.Add(addr, src)
+			book.Add(addr2, src)
+			book.Good(addr2)
+			Expect(book.Pick()).To(Or(Equal(addr), Equal(addr2)))
+		})
+	})
+
+	Describe("Manager", func() {
+
+		var lp, rp *node.Node
+		var mgr *peermanager.Manager
+
+		BeforeEach(func() {
+			lp = makeTestNode(getPort())
+			rp = makeTestNode(getPort())
+			mgr = rp.PM()
+			mgr.SetLocalNode(rp)
+		})
+
+		AfterEach(func() {
+			closeNode(lp)
+			closeNode(rp)
+		})
+
+		It("should move an acquainted peer to a tried bucket", func() {
+			mgr.AddPeer(lp)
+			Expect(mgr.AddrBook().IsTried(lp.StringID())).To(BeFalse())
+			mgr.AddAcquainted(lp)
+			Expect(mgr.AddrBook().IsTried(lp.StringID())).To(BeTrue())
+		})
+
+		It("should pick unconnected peers to connect to", func() {
+			mgr.AddPeer(lp)
+			peers := mgr.PickPeersToConnect(2)
+			Expect(peers).To(HaveLen(1))
+			Expect(peers[0].StringID()).To(Equal(lp.StringID()))
+		})
+
+		It("should restore tried addresses from the database", func() {
+			lp.SetCreatedAt(time.Now().Add(-21 * time.Minute))
+			lp.SetLastSeen(time.Now())
+			mgr.AddPeer(lp)
+			mgr.AddAcquainted(lp)
+			Expect(mgr.SavePeers()).To(BeNil())
+
+			mgr2 := peermanager.NewManager(rp.GetCfg(), rp, log)
+			Expect(mgr2.LoadPeers()).To(BeNil())
+			Expect(mgr2.PeerExist(lp.StringID())).To(BeTrue())
+			Expect(mgr2.AddrBook().IsTried(lp.StringID())).To(BeTrue())
+		})
+	})
+})
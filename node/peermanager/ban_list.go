@@ -0,0 +1,198 @@
+package peermanager
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/ellcrys/elld/elldb"
+	"github.com/ellcrys/elld/util"
+	peer "github.com/libp2p/go-libp2p-peer"
+)
+
+// banPrefix is the key prefix of ban list entries
+var banPrefix = []byte("ban")
+
+// Ban list entry kinds
+const (
+	BanKindPeerID = "peerId"
+	BanKindIP     = "ip"
+	BanKindSubnet = "subnet"
+)
+
+// Ban describes an entry in the ban list
+type Ban struct {
+	Target    string `json:"target" msgpack:"target"`
+	Kind      string `json:"kind" msgpack:"kind"`
+	Reason    string `json:"reason" msgpack:"reason"`
+	CreatedAt int64  `json:"createdAt" msgpack:"createdAt"`
+	EndTime   int64  `json:"endTime" msgpack:"endTime"`
+
+	subnet *net.IPNet
+}
+
+// IsActive checks whether the ban is still in
+// effect. A ban with zero end time is permanent.
+func (b *Ban) IsActive(now time.Time) bool {
+	return b.EndTime == 0 || b.EndTime > now.Unix()
+}
+
+// matches checks whether the ban applies to an address
+func (b *Ban) matches(addr util.NodeAddr) bool {
+	switch b.Kind {
+	case BanKindPeerID:
+		return addr.StringID() == b.Target
+	case BanKindIP:
+		ip := addr.IP()
+		return ip != nil && ip.Equal(net.ParseIP(b.Target))
+	case BanKindSubnet:
+		ip := addr.IP()
+		return ip != nil && b.subnet != nil && b.subnet.Contains(ip)
+	}
+	return false
+}
+
+// newBan creates a ban for a target. The target
+// can be a peer ID, an IP address or a subnet
+// in CIDR notation.
+func newBan(target string, dur time.Duration, reason string) (*Ban, error) {
+
+	now := time.Now()
+	ban := &Ban{Target: target, Reason: reason, CreatedAt: now.Unix()}
+	if dur > 0 {
+		ban.EndTime = now.Add(dur).Unix()
+	}
+
+	if _, subnet, err := net.ParseCIDR(target); err == nil {
+		ban.Kind, ban.Target, ban.subnet = BanKindSubnet, subnet.String(), subnet
+	} else if ip := net.ParseIP(target); ip != nil {
+		ban.Kind, ban.Target = BanKindIP, ip.String()
+	} else if _, err := peer.IDB58Decode(target); err == nil {
+		ban.Kind = BanKindPeerID
+	} else {
+		return nil, fmt.Errorf("target must be a peer ID, an IP or a subnet")
+	}
+
+	return ban, nil
+}
+
+// banKey returns the database key of a ban target.
+// The target is hashed so that no key is the
+// prefix of another.
+func banKey(target string) []byte {
+	return []byte(util.ToHex(util.Blake2b256([]byte(target))))
+}
+
+// BanPeer adds a target to the ban list and stores it
+// on disk. A zero duration creates a permanent ban.
+// Connected peers matching the ban are disconnected.
+func (m *Manager) BanPeer(target string, dur time.Duration, reason string) (*Ban, error) {
+
+	ban, err := newBan(target, dur, reason)
+	if err != nil {
+		return nil, err
+	}
+
+	obj := elldb.NewKVObject(banKey(ban.Target), util.ObjectToBytes(ban), banPrefix)
+	if err := m.localNode.DB().Put([]*elldb.KVObject{obj}); err != nil {
+		return nil, err
+	}
+
+	m.cacheMtx.Lock()
+	m.bans[ban.Target] = ban
+	m.cacheMtx.Unlock()
+
+	for _, p := range m.GetConnectedPeers() {
+		if ban.matches(p.GetAddress()) {
+			m.RemoveAcquainted(p)
+			m.localNode.GetHost().Network().ClosePeer(p.ID())
+		}
+	}
+
+	m.log.Info("Added ban list entry", "Target", ban.Target, "Kind", ban.Kind,
+		"Reason", reason)
+
+	return ban, nil
+}
+
+// UnbanPeer removes a target from the ban list
+func (m *Manager) UnbanPeer(target string) error {
+
+	ban, err := newBan(target, 0, "")
+	if err != nil {
+		return err
+	}
+
+	m.cacheMtx.Lock()
+	defer m.cacheMtx.Unlock()
+
+	if _, ok := m.bans[ban.Target]; !ok {
+		return fmt.Errorf("target is not in the ban list")
+	}
+
+	key := elldb.MakeKey(banKey(ban.Target), banPrefix)
+	if err := m.localNode.DB().DeleteByPrefix(key); err != nil {
+		return err
+	}
+
+	delete(m.bans, ban.Target)
+	return nil
+}
+
+// GetBans returns the active entries of the ban list
+func (m *Manager) GetBans() (bans []*Ban) {
+	m.cacheMtx.RLock()
+	defer m.cacheMtx.RUnlock()
+	now := time.Now()
+	for _, ban := range m.bans {
+		if ban.IsActive(now) {
+			bans = append(bans, ban)
+		}
+	}
+	return
+}
+
+// IsBanListed checks whether an address matches
+// an active entry of the ban list
+func (m *Manager) IsBanListed(addr util.NodeAddr) bool {
+	m.cacheMtx.RLock()
+	defer m.cacheMtx.RUnlock()
+	now := time.Now()
+	for _, ban := range m.bans {
+		if ban.IsActive(now) && ban.matches(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadBans loads the ban list stored in the
+// local database. Expired entries are deleted.
+func (m *Manager) LoadBans() error {
+
+	now := time.Now()
+	kvObjs := m.localNode.DB().GetByPrefix(banPrefix)
+
+	for _, o := range kvObjs {
+
+		var ban Ban
+		if err := util.BytesToObject(o.Value, &ban); err != nil {
+			return err
+		}
+
+		if !ban.IsActive(now) {
+			m.localNode.DB().DeleteByPrefix(o.GetKey())
+			continue
+		}
+
+		if ban.Kind == BanKindSubnet {
+			_, ban.subnet, _ = net.ParseCIDR(ban.Target)
+		}
+
+		m.cacheMtx.Lock()
+		m.bans[ban.Target] = &ban
+		m.cacheMtx.Unlock()
+	}
+
+	return nil
+}
@@ -0,0 +1,99 @@
+package peermanager_test
+
+import (
+	"time"
+
+	"github.com/ellcrys/elld/node"
+	"github.com/ellcrys/elld/node/peermanager"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("BanList", func() {
+
+	var lp, rp *node.Node
+	var mgr *peermanager.Manager
+
+	BeforeEach(func() {
+		lp = makeTestNodeWith(getPort(), 1)
+		Expect(lp.GetBlockchain().Up()).To(BeNil())
+
+		rp = makeTestNodeWith(getPort(), 2)
+		Expect(rp.GetBlockchain().Up()).To(BeNil())
+		mgr = rp.PM()
+		mgr.SetLocalNode(rp)
+	})
+
+	AfterEach(func() {
+		closeNode(lp)
+		closeNode(rp)
+	})
+
+	Describe(".BanPeer", func() {
+		It("should return error when target is not valid", func() {
+			_, err := mgr.BanPeer("not_valid", 0, "")
+			Expect(err).ToNot(BeNil())
+			Expect(err.Error()).To(Equal("target must be a peer ID, an IP or a subnet"))
+		})
+
+		It("should ban by peer ID", func() {
+			ban, err := mgr.BanPeer(lp.StringID(), 0, "spam")
+			Expect(err).To(BeNil())
+			Expect(ban.Kind).To(Equal(peermanager.BanKindPeerID))
+			Expect(ban.EndTime).To(BeZero())
+			Expect(mgr.IsBanListed(lp.GetAddress())).To(BeTrue())
+		})
+
+		It("should ban by IP", func() {
+			ban, err := mgr.BanPeer("127.0.0.1", time.Hour, "")
+			Expect(err).To(BeNil())
+			Expect(ban.Kind).To(Equal(peermanager.BanKindIP))
+			Expect(mgr.IsBanListed(lp.GetAddress())).To(BeTrue())
+		})
+
+		It("should ban by subnet", func() {
+			ban, err := mgr.BanPeer("127.0.0.0/8", time.Hour, "")
+			Expect(err).To(BeNil())
+			Expect(ban.Kind).To(Equal(peermanager.BanKindSubnet))
+			Expect(mgr.IsBanListed(lp.GetAddress())).To(BeTrue())
+		})
+
+		It("should store the ban on disk", func() {
+			_, err := mgr.BanPeer(lp.StringID(), 0, "spam")
+			Expect(err).To(BeNil())
+			Expect(rp.DB().GetByPrefix([]byte("ban"))).To(HaveLen(1))
+		})
+	})
+
+	Describe(".UnbanPeer", func() {
+		It("should return error when target is not in the ban list", func() {
+			err := mgr.UnbanPeer("127.0.0.1")
+			Expect(err).ToNot(BeNil())
+			Expect(err.Error()).To(Equal("target is not in the ban list"))
+		})
+
+		It("should remove the ban from memory and disk", func() {
+			_, err := mgr.BanPeer("127.0.0.1", 0, "")
+			Expect(err).To(BeNil())
+			Expect(mgr.UnbanPeer("127.0.0.1")).To(BeNil())
+			Expect(mgr.IsBanListed(lp.GetAddress())).To(BeFalse())
+			Expect(rp.DB().GetByPrefix([]byte("ban"))).To(BeEmpty())
+		})
+	})
+
+	Describe(".LoadBans", func() {
+		It("should load active bans and delete expired bans", func() {
+			_, err := mgr.BanPeer(lp.StringID(), time.Hour, "")
+			Expect(err).To(BeNil())
+			_, err = mgr.BanPeer("10.0.0.0/8", time.Nanosecond, "")
+			Expect(err).To(BeNil())
+
+			mgr2 := peermanager.NewManager(rp.GetCfg(), rp, log)
+			time.Sleep(1 * time.Second)
+			Expect(mgr2.LoadBans()).To(BeNil())
+			Expect(mgr2.GetBans()).To(HaveLen(1))
+			Expect(mgr2.IsBanListed(lp.GetAddress())).To(BeTrue())
+			Expect(rp.DB().GetByPrefix([]byte("ban"))).To(HaveLen(1))
+		})
+	})
+})
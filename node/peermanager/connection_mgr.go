@@ -120,22 +120,20 @@ func (m *ConnectionManager) makeConnections(done chan bool) {
 				continue
 			}
 
-			// Get unconnected/unacquainted peers
-			peers := m.pm.GetLonelyPeers()
+			// Pick unconnected/unacquainted peers
+			// from the address book to fill the
+			// available outbound connection slots
+			_, outbound := m.GetConnsCount().Info()
+			slots := int(m.pm.config.Node.MaxOutboundConnections) - outbound
+			peers := m.pm.PickPeersToConnect(slots)
 			if len(peers) == 0 {
 				continue
 			}
 
-			m.log.Debug("Establishing connection with more peers", "UnconnectedPeers",
+			m.log.Debug("Establishing connection with more peers", "PickedPeers",
 				len(peers))
 
 			for _, p := range peers {
-
-				// Do not establish connection with banned peers
-				if m.pm.IsBanned(p) {
-					continue
-				}
-
 				m.pm.ConnectToPeer(p.StringID())
 			}
 		case <-done:
@@ -184,6 +182,13 @@ func (m *ConnectionManager) Connected(n net.Network, conn net.Conn) {
 	// Reset connection failure count
 	rnAddr := util.RemoteAddrFromConn(conn)
 	m.pm.ClearConnFailCount(rnAddr)
+
+	// Close connections of peers in the ban list
+	if m.pm.IsBanListed(rnAddr) {
+		m.log.Debug("Closed connection. Peer is in the ban list",
+			"PeerID", rnAddr.StringID())
+		conn.Close()
+	}
 }
 
 // Disconnected is called when a connection is closed.
@@ -0,0 +1,103 @@
+package peermanager
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ellcrys/elld/config"
+	"github.com/ellcrys/elld/params"
+	"github.com/ellcrys/elld/util"
+	pstore "github.com/libp2p/go-libp2p-peerstore"
+	"github.com/libp2p/go-libp2p/p2p/discovery"
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+// mdnsServiceTag returns the mDNS service tag. It includes
+// the network version so that only nodes on the same
+// network discover each other.
+func mdnsServiceTag() string {
+	return fmt.Sprintf("_elld-%s._udp", config.GetVersions().Protocol)
+}
+
+// startMDNS starts the mDNS service which advertises the
+// local node and discovers other nodes on the local network
+func (m *Manager) startMDNS() error {
+
+	svc, err := discovery.NewMdnsService(context.Background(),
+		m.localNode.GetHost(), params.MDNSInterval, mdnsServiceTag())
+	if err != nil {
+		return err
+	}
+
+	svc.RegisterNotifee(m)
+
+	m.mtx.Lock()
+	m.mdns = svc
+	m.mtx.Unlock()
+
+	m.log.Info("Started local network discovery", "ServiceTag", mdnsServiceTag())
+	return nil
+}
+
+// stopMDNS stops the mDNS service
+func (m *Manager) stopMDNS() {
+	m.mtx.Lock()
+	svc := m.mdns
+	m.mdns = nil
+	m.mtx.Unlock()
+
+	if svc != nil {
+		svc.Close()
+	}
+}
+
+// HandlePeerFound is called by the mDNS service when a node
+// is found on the local network. The node is added to the
+// known peers and a connection is attempted if more
+// peers are required.
+func (m *Manager) HandlePeerFound(pi pstore.PeerInfo) {
+
+	if pi.ID == m.localNode.ID() || len(pi.Addrs) == 0 {
+		return
+	}
+
+	ipfsAddr, err := ma.NewMultiaddr(fmt.Sprintf("/ipfs/%s", pi.ID.Pretty()))
+	if err != nil {
+		return
+	}
+
+	addr := util.NodeAddr(mdnsPickAddr(pi.Addrs).Encapsulate(ipfsAddr).String())
+	if !addr.IsValid() {
+		return
+	}
+
+	p := m.localNode.NewRemoteNode(addr)
+	if m.IsBanned(p) || m.IsBanListed(addr) {
+		return
+	}
+
+	m.log.Debug("Found peer on the local network", "PeerID", p.ShortID(),
+		"Address", addr.String())
+
+	exist := m.PeerExist(p.StringID())
+	m.AddOrUpdateNode(p)
+	if !exist && m.RequirePeers() {
+		go m.ConnectToPeer(p.StringID())
+	}
+}
+
+// mdnsPickAddr selects the address to use from the
+// advertised addresses of a node. Non-loopback
+// addresses are preferred.
+func mdnsPickAddr(addrs []ma.Multiaddr) ma.Multiaddr {
+	for _, addr := range addrs {
+		ip, err := addr.ValueForProtocol(ma.P_IP4)
+		if err != nil {
+			continue
+		}
+		if ip != "127.0.0.1" {
+			return addr
+		}
+	}
+	return addrs[0]
+}
@@ -0,0 +1,47 @@
+package peermanager_test
+
+import (
+	"github.com/ellcrys/elld/node"
+	"github.com/ellcrys/elld/node/peermanager"
+	pstore "github.com/libp2p/go-libp2p-peerstore"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("MDNS", func() {
+
+	var lp, rp *node.Node
+	var mgr *peermanager.Manager
+
+	BeforeEach(func() {
+		lp = makeTestNode(getPort())
+		rp = makeTestNode(getPort())
+		mgr = rp.PM()
+		mgr.SetLocalNode(rp)
+	})
+
+	AfterEach(func() {
+		closeNode(lp)
+		closeNode(rp)
+	})
+
+	Describe(".HandlePeerFound", func() {
+		It("should add the found peer", func() {
+			mgr.HandlePeerFound(pstore.PeerInfo{ID: lp.ID(), Addrs: lp.GetHost().Addrs()})
+			Expect(mgr.PeerExist(lp.StringID())).To(BeTrue())
+			Expect(mgr.GetPeer(lp.StringID()).GetAddress()).To(Equal(lp.GetAddress()))
+		})
+
+		It("should ignore the local peer", func() {
+			mgr.HandlePeerFound(pstore.PeerInfo{ID: rp.ID(), Addrs: rp.GetHost().Addrs()})
+			Expect(mgr.PeerExist(rp.StringID())).To(BeFalse())
+		})
+
+		It("should ignore peers in the ban list", func() {
+			_, err := mgr.BanPeer(lp.StringID(), 0, "")
+			Expect(err).To(BeNil())
+			mgr.HandlePeerFound(pstore.PeerInfo{ID: lp.ID(), Addrs: lp.GetHost().Addrs()})
+			Expect(mgr.PeerExist(lp.StringID())).To(BeFalse())
+		})
+	})
+})
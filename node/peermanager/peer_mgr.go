@@ -1,6 +1,7 @@
 package peermanager
 
 import (
+	crand "crypto/rand"
 	"fmt"
 	"math/rand"
 	"sync"
@@ -13,6 +14,7 @@ import (
 	"github.com/ellcrys/elld/config"
 
 	"github.com/ellcrys/elld/util"
+	"github.com/libp2p/go-libp2p/p2p/discovery"
 )
 
 // Manager manages known peers connected to the local peer.
@@ -31,6 +33,11 @@ type Manager struct {
 	timeBan          map[string]time.Time   // Stores the time where time banned peers are free
 	acquainted       map[string]struct{}    // Store peers that sent and acknowledged handshake messages
 	connectFailCount map[string]int         // Keeps count of connection attempt failure
+	scores           map[string]*peerScore  // Stores the misbehavior scores of peers
+	bans             map[string]*Ban        // Stores the ban list entries
+	protocols        map[string]*Protocol   // Stores the protocol versions and features negotiated with peers
+	mdns             discovery.Service      // Local network discovery service
+	addrBook         *AddrBook              // Stores the addresses of known peers in new and tried buckets
 	tickersDone      chan bool
 }
 
@@ -54,8 +61,12 @@ func NewManager(cfg *config.EngineConfig, localPeer core.Engine, log logger.Logg
 		acquainted:       make(map[string]struct{}),
 		timeBan:          make(map[string]time.Time),
 		connectFailCount: make(map[string]int),
+		scores:           make(map[string]*peerScore),
+		bans:             make(map[string]*Ban),
+		protocols:        make(map[string]*Protocol),
 	}
 
+	m.addrBook = NewAddrBook(m.loadAddrBookKey())
 	m.connMgr = NewConnMrg(m, log)
 	m.localNode.GetHost().Network().Notify(m.connMgr)
 	return m
@@ -156,11 +167,13 @@ func (m *Manager) GetPeer(peerID string) core.Engine {
 }
 
 // AddAcquainted marks a peer has haven gone passed the
-// handshake step
+// handshake step. The address of the peer is moved
+// to a tried bucket of the address book.
 func (m *Manager) AddAcquainted(peer core.Engine) {
 	m.cacheMtx.Lock()
-	defer m.cacheMtx.Unlock()
 	m.acquainted[peer.StringID()] = struct{}{}
+	m.cacheMtx.Unlock()
+	m.evictPeer(m.addrBook.Good(peer.GetAddress()))
 }
 
 // RemoveAcquainted makes a peer unacquainted
@@ -168,6 +181,7 @@ func (m *Manager) RemoveAcquainted(peer core.Engine) {
 	m.cacheMtx.Lock()
 	defer m.cacheMtx.Unlock()
 	delete(m.acquainted, peer.StringID())
+	delete(m.protocols, peer.StringID())
 }
 
 // IsAcquainted checks whether the peer passed through
@@ -181,9 +195,61 @@ func (m *Manager) IsAcquainted(peer core.Engine) bool {
 
 // AddPeer adds a peer
 func (m *Manager) AddPeer(peer core.Engine) {
+	m.AddPeerFrom(peer, peer)
+}
+
+// AddPeerFrom adds a peer whose address was received
+// from src. The address is added to a new bucket of the
+// address book. The peer whose address was evicted from
+// the bucket to make room is forgotten.
+func (m *Manager) AddPeerFrom(peer, src core.Engine) {
+	_, evicted := m.addrBook.Add(peer.GetAddress(), src.GetAddress())
 	m.ptx.Lock()
 	m.peers[peer.StringID()] = peer
 	m.ptx.Unlock()
+	m.evictPeer(evicted)
+}
+
+// evictPeer forgets a peer whose address was evicted
+// from the address book unless it is connected
+func (m *Manager) evictPeer(peerID string) {
+	if peerID == "" {
+		return
+	}
+	m.ptx.Lock()
+	defer m.ptx.Unlock()
+	if p, ok := m.peers[peerID]; ok && !p.Connected() {
+		delete(m.peers, peerID)
+	}
+}
+
+// AddrBook returns the address book
+func (m *Manager) AddrBook() *AddrBook {
+	return m.addrBook
+}
+
+// PickPeersToConnect picks up to n peers from the address
+// book that are not connected or not acquainted and are
+// not banned.
+func (m *Manager) PickPeersToConnect(n int) (peers []core.Engine) {
+	picked := make(map[string]struct{})
+	for i := 0; i < n*10 && len(peers) < n; i++ {
+		addr := m.addrBook.Pick()
+		if addr == "" {
+			return
+		}
+		if _, ok := picked[addr.StringID()]; ok {
+			continue
+		}
+		picked[addr.StringID()] = struct{}{}
+		p := m.GetPeer(addr.StringID())
+		if p == nil || (p.Connected() && m.IsAcquainted(p)) ||
+			m.IsBanned(p) || m.IsBanListed(p.GetAddress()) {
+			continue
+		}
+		peers = append(peers, p)
+	}
+	return
 }
 
 // LocalPeer returns the local peer
@@ -203,6 +269,8 @@ func (m *Manager) ConnectToPeer(peerID string) error {
 	m.log.Debug("Attempting to connect to peer",
 		"PeerID", peer.ShortID())
 
+	m.addrBook.Attempt(peer.GetAddress())
+
 	gsp := m.localNode.Gossip()
 	err := gsp.SendHandshake(peer)
 	if err != nil {
@@ -279,11 +347,21 @@ func (m *Manager) Manage() {
 		m.log.Error("failed to load peer addresses from database", "Err", err.Error())
 	}
 
+	if err := m.LoadBans(); err != nil {
+		m.log.Error("failed to load ban list from database", "Err", err.Error())
+	}
+
 	go m.connMgr.Manage()
 	go m.doSelfAdvert(m.tickersDone)
 	go m.doCleanUp(m.tickersDone)
 	go m.doPingMsgs(m.tickersDone)
 	go m.doGetAddrMsg(m.tickersDone)
+
+	if m.config.Node.EnableMDNS && !m.localNode.IsNetworkDisabled() {
+		if err := m.startMDNS(); err != nil {
+			m.log.Error("failed to start local network discovery", "Err", err.Error())
+		}
+	}
 }
 
 // doGetAddrMsg periodically sends wire.GetAddr
@@ -393,6 +471,12 @@ func (m *Manager) CanAcceptNode(node core.Engine, opts ...bool) (bool, error) {
 		return false, fmt.Errorf("currently serving ban time")
 	}
 
+	// When a remote peer has been banned for
+	// misbehaving, we cannot receive messages from it.
+	if m.HasMisbehaviorBan(node) {
+		return false, fmt.Errorf("currently serving misbehavior ban time")
+	}
+
 	return true, nil
 }
 
@@ -400,14 +484,19 @@ func (m *Manager) CanAcceptNode(node core.Engine, opts ...bool) (bool, error) {
 // it hasn't been added. It updates the timestamp
 // of existing peers.
 func (m *Manager) AddOrUpdateNode(n core.Engine) {
+	m.AddOrUpdateNodeFrom(n, n)
+}
+
+// AddOrUpdateNodeFrom is like AddOrUpdateNode but
+// for a peer whose address was received from src
+func (m *Manager) AddOrUpdateNodeFrom(n, src core.Engine) {
 	defer m.CleanPeers()
-	defer m.SavePeers()
 
 	peer := m.GetPeer(n.StringID())
 	// For unknown peers, set 'last seen' time to an hour ago
 	if peer == nil {
 		n.SetLastSeen(time.Now().Add(-1 * time.Hour))
-		m.AddPeer(n)
+		m.AddPeerFrom(n, src)
 		return
 	}
 
@@ -531,6 +620,7 @@ func (m *Manager) CleanPeers() int {
 		}
 
 		delete(m.acquainted, p.StringID())
+		m.addrBook.Remove(p.StringID())
 	}
 
 	after := len(clean)
@@ -600,16 +690,66 @@ func (m *Manager) GetRandomActivePeers(limit int) []core.Engine {
 	return peers[:limit]
 }
 
+// addrBookPrefix is the key prefix of address book records
+var addrBookPrefix = elldb.MakePrefix([]byte("addrbook"), []byte("addr"))
+
+// legacyAddrPrefix is the key prefix of peer records
+// stored before the address book was introduced
+var legacyAddrPrefix = []byte("address")
+
+// addrBookKeyPrefix is the key prefix of the address book key
+var addrBookKeyPrefix = elldb.MakePrefix([]byte("addrbook"), []byte("key"))
+
+// addrRecord is the stored representation
+// of an address in the address book
+type addrRecord struct {
+	Address     util.NodeAddr `msgpack:"address"`
+	Source      util.NodeAddr `msgpack:"source"`
+	CreatedAt   int64         `msgpack:"createdAt"`
+	LastSeen    int64         `msgpack:"lastSeen"`
+	BanTime     int64         `msgpack:"banTime,omitempty"`
+	Tried       bool          `msgpack:"tried"`
+	Attempts    int           `msgpack:"attempts"`
+	LastSuccess int64         `msgpack:"lastSuccess"`
+}
+
+// loadAddrBookKey returns the key of the address book.
+// A new key is created and stored if none exists.
+func (m *Manager) loadAddrBookKey() []byte {
+
+	db := m.localNode.DB()
+	if db != nil {
+		if objs := db.GetByPrefix(addrBookKeyPrefix); len(objs) > 0 {
+			return objs[0].Value
+		}
+	}
+
+	key := make([]byte, 32)
+	crand.Read(key)
+
+	if db != nil {
+		db.Put([]*elldb.KVObject{elldb.NewKVObject([]byte("key"), key, addrBookKeyPrefix)})
+	}
+
+	return key
+}
+
 // ForgetPeers deletes peers in memory and on disk
 func (m *Manager) ForgetPeers() {
+	for _, p := range m.GetPeers() {
+		m.addrBook.Remove(p.StringID())
+	}
 	m.SetPeers(map[string]core.Engine{})
-	m.localNode.DB().DeleteByPrefix([]byte("address"))
+	m.localNode.DB().DeleteByPrefix(addrBookPrefix)
+	m.localNode.DB().DeleteByPrefix(legacyAddrPrefix)
 }
 
-// SavePeers stores active peer addresses
+// SavePeers stores the address book. Stored records
+// replace the previously stored ones in a single
+// database transaction. The address book is saved
+// periodically and when the manager stops.
 func (m *Manager) SavePeers() error {
 
-	var numAddrs = 0
 	var kvObjs []*elldb.KVObject
 	var peers = m.GetPeers()
 
@@ -622,47 +762,89 @@ func (m *Manager) SavePeers() error {
 			continue
 		}
 
-		key := []byte(p.StringID())
-		value := map[string]interface{}{
-			"address":   p.GetAddress(),
-			"createdAt": p.CreatedAt().Unix(),
-			"lastSeen":  p.GetLastSeen().Unix(),
+		record := &addrRecord{
+			Address:   p.GetAddress(),
+			Source:    p.GetAddress(),
+			CreatedAt: p.CreatedAt().Unix(),
+			LastSeen:  p.GetLastSeen().Unix(),
+		}
+
+		if ka, ok := m.addrBook.get(p.StringID()); ok {
+			record.Source = ka.src
+			record.Tried = ka.tried
+			record.Attempts = ka.attempts
+			if !ka.lastSuccess.IsZero() {
+				record.LastSuccess = ka.lastSuccess.Unix()
+			}
 		}
 
 		if banTime := m.GetBanTime(p); !banTime.IsZero() {
-			value["banTime"] = banTime.Unix()
+			record.BanTime = banTime.Unix()
 		}
 
-		obj := elldb.NewKVObject(key, util.ObjectToBytes(value), []byte("address"))
-		kvObjs = append(kvObjs, obj)
-		numAddrs++
+		key := []byte(p.StringID())
+		kvObjs = append(kvObjs, elldb.NewKVObject(key, util.ObjectToBytes(record), addrBookPrefix))
 	}
 
-	if err := m.localNode.DB().Put(kvObjs); err != nil {
+	tx, err := m.localNode.DB().NewTx()
+	if err != nil {
 		return err
 	}
 
-	return nil
+	// Records of peers stored before the address book
+	// was introduced were migrated when loaded
+	for _, prefix := range [][]byte{addrBookPrefix, legacyAddrPrefix} {
+		if err := tx.DeleteByPrefix(prefix); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if err := tx.Put(kvObjs); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
 }
 
-// LoadPeers loads peers stored in
-// the local database
+// LoadPeers loads the address book stored in the local
+// database. Addresses are placed back in their new or
+// tried buckets. Peers stored before the address book
+// was introduced are added as new addresses.
 func (m *Manager) LoadPeers() error {
 
-	kvObjs := m.localNode.DB().GetByPrefix([]byte("address"))
+	db := m.localNode.DB()
 
-	// create remote node to represent
-	// the addresses and add them to the
-	// managers active peer list
-	for _, o := range kvObjs {
+	var records []*addrRecord
+	var stored = make(map[util.NodeAddr]struct{})
+	for _, o := range db.GetByPrefix(addrBookPrefix) {
+		var record addrRecord
+		if err := util.BytesToObject(o.Value, &record); err != nil {
+			return err
+		}
+		records = append(records, &record)
+		stored[record.Address] = struct{}{}
+	}
 
-		var addrData map[string]interface{}
-		if err := o.Scan(&addrData); err != nil {
+	// Legacy records share the address, creation,
+	// last seen and ban time fields of addrRecord
+	for _, o := range db.GetByPrefix(legacyAddrPrefix) {
+		var record addrRecord
+		if err := util.BytesToObject(o.Value, &record); err != nil {
 			return err
 		}
+		if _, ok := stored[record.Address]; ok {
+			continue
+		}
+		record.Source = record.Address
+		records = append(records, &record)
+	}
+
+	var loaded []core.Engine
+	for _, record := range records {
 
-		addr := util.NodeAddr(addrData["address"].(string))
-		peer := m.localNode.NewRemoteNode(addr)
+		peer := m.localNode.NewRemoteNode(record.Address)
 
 		// Do not overwrite peer if it already exists
 		// in the peer list. The peer might have been
@@ -671,18 +853,39 @@ func (m *Manager) LoadPeers() error {
 			continue
 		}
 
-		peer.SetCreatedAt(time.Unix(int64(addrData["createdAt"].(uint32)), 0))
-		peer.SetLastSeen(time.Unix(int64(addrData["lastSeen"].(uint32)), 0))
-		m.AddPeer(peer)
+		peer.SetCreatedAt(time.Unix(record.CreatedAt, 0))
+		peer.SetLastSeen(time.Unix(record.LastSeen, 0))
 
-		if addrData["banTime"] != nil {
-			banTime := time.Unix(int64(addrData["banTime"].(uint32)), 0)
+		ka := &knownAddress{
+			addr:     record.Address,
+			src:      record.Source,
+			addedAt:  peer.CreatedAt(),
+			attempts: record.Attempts,
+			tried:    record.Tried,
+		}
+		if record.LastSuccess > 0 {
+			ka.lastSuccess = time.Unix(record.LastSuccess, 0)
+		}
+		m.addrBook.restore(ka)
+		loaded = append(loaded, peer)
+
+		if record.BanTime > 0 {
 			m.cacheMtx.Lock()
-			m.timeBan[addr.IP().String()] = banTime
+			m.timeBan[record.Address.IP().String()] = time.Unix(record.BanTime, 0)
 			m.cacheMtx.Unlock()
 		}
 	}
 
+	// Only add the peers whose addresses
+	// were not evicted while restoring
+	for _, peer := range loaded {
+		if m.addrBook.Has(peer.StringID()) {
+			m.ptx.Lock()
+			m.peers[peer.StringID()] = peer
+			m.ptx.Unlock()
+		}
+	}
+
 	return nil
 }
 
@@ -692,6 +895,7 @@ func (m *Manager) Stop() {
 
 	m.CleanPeers()
 	m.SavePeers()
+	m.stopMDNS()
 
 	m.mtx.Lock()
 	defer m.mtx.Unlock()
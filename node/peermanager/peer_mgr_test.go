@@ -9,6 +9,7 @@ import (
 
 	"github.com/ellcrys/elld/config"
 	"github.com/ellcrys/elld/crypto"
+	"github.com/ellcrys/elld/elldb"
 	"github.com/ellcrys/elld/node"
 	"github.com/ellcrys/elld/node/peermanager"
 	"github.com/ellcrys/elld/types/core"
@@ -404,7 +405,7 @@ var _ = Describe("PeerManager", func() {
 				err := mgr.SavePeers()
 				Expect(err).To(BeNil())
 
-				result := mgr.LocalPeer().DB().GetByPrefix([]byte("address"))
+				result := mgr.LocalPeer().DB().GetByPrefix([]byte("addrbook:addr"))
 				Expect(result).To(BeEmpty())
 			})
 		})
@@ -428,7 +429,7 @@ var _ = Describe("PeerManager", func() {
 				err := mgr.SavePeers()
 				Expect(err).To(BeNil())
 
-				result := mgr.LocalPeer().DB().GetByPrefix([]byte("address"))
+				result := mgr.LocalPeer().DB().GetByPrefix([]byte("addrbook:addr"))
 				Expect(result).To(HaveLen(2))
 			})
 		})
@@ -451,7 +452,7 @@ var _ = Describe("PeerManager", func() {
 				err := mgr.SavePeers()
 				Expect(err).To(BeNil())
 
-				result := mgr.LocalPeer().DB().GetByPrefix([]byte("address"))
+				result := mgr.LocalPeer().DB().GetByPrefix([]byte("addrbook:addr"))
 				Expect(result).To(HaveLen(1))
 
 				var m map[string]interface{}
@@ -528,6 +529,47 @@ var _ = Describe("PeerManager", func() {
 				Expect(mgr.GetBanTime(peer).Unix()).To(Equal(banTime))
 			})
 		})
+
+		Context("load peer stored before the address book was introduced", func() {
+			BeforeEach(func() {
+				createdAt = time.Now().Add(-21 * time.Minute)
+				lastSeen = time.Now()
+				addr, _ := ma.NewMultiaddr("/ip4/127.0.0.1/tcp/9000/ipfs/12D3KooWM4yJB31d4hF2F9Vdwuj9WFo1qonoySyw4bVAQ9a9d21o")
+				peer = node.NewRemoteNodeFromMultiAddr(addr, lp)
+
+				value := map[string]interface{}{
+					"address":   peer.GetAddress(),
+					"createdAt": createdAt.Unix(),
+					"lastSeen":  lastSeen.Unix(),
+				}
+				obj := elldb.NewKVObject([]byte(peer.StringID()), util.ObjectToBytes(value), []byte("address"))
+				Expect(mgr.LocalPeer().DB().Put([]*elldb.KVObject{obj})).To(BeNil())
+			})
+
+			It("should fetch 1 address and add it to the address book", func() {
+				err := mgr.LoadPeers()
+				Expect(err).To(BeNil())
+				Expect(mgr.Peers()).To(HaveKey(peer.StringID()))
+				peer := mgr.Peers()[peer.StringID()]
+				Expect(peer.GetLastSeen().Unix()).To(Equal(lastSeen.Unix()))
+				Expect(peer.CreatedAt().Unix()).To(Equal(createdAt.Unix()))
+				Expect(mgr.AddrBook().Has(peer.StringID())).To(BeTrue())
+			})
+
+			It("should replace the old record when the address book is saved", func() {
+				Expect(mgr.LoadPeers()).To(BeNil())
+				Expect(mgr.SavePeers()).To(BeNil())
+				Expect(mgr.LocalPeer().DB().GetByPrefix([]byte("address"))).To(BeEmpty())
+				Expect(mgr.LocalPeer().DB().GetByPrefix([]byte("addrbook:addr"))).To(HaveLen(1))
+			})
+
+			It("should delete the old record when peers are forgotten", func() {
+				mgr.ForgetPeers()
+				Expect(mgr.LocalPeer().DB().GetByPrefix([]byte("address"))).To(BeEmpty())
+				Expect(mgr.LoadPeers()).To(BeNil())
+				Expect(mgr.Peers()).ToNot(HaveKey(peer.StringID()))
+			})
+		})
 	})
 
 	Describe(".GetKnownPeer", func() {
@@ -0,0 +1,50 @@
+package peermanager
+
+import (
+	"github.com/ellcrys/elld/types/core"
+)
+
+// Protocol holds the message versions and
+// features negotiated with a peer
+type Protocol struct {
+	MsgVersions map[string]uint32
+	Features    []string
+}
+
+// SetProtocol stores the protocol
+// negotiated with a peer
+func (m *Manager) SetProtocol(peer core.Engine, p *Protocol) {
+	m.cacheMtx.Lock()
+	defer m.cacheMtx.Unlock()
+	m.protocols[peer.StringID()] = p
+}
+
+// GetMsgVersion returns the version of a message type
+// negotiated with a peer. It returns zero if no
+// version was negotiated.
+func (m *Manager) GetMsgVersion(peer core.Engine, msgType string) uint32 {
+	m.cacheMtx.RLock()
+	defer m.cacheMtx.RUnlock()
+	p, ok := m.protocols[peer.StringID()]
+	if !ok {
+		return 0
+	}
+	return p.MsgVersions[msgType]
+}
+
+// HasFeature checks whether a feature
+// was negotiated with a peer
+func (m *Manager) HasFeature(peer core.Engine, feature string) bool {
+	m.cacheMtx.RLock()
+	defer m.cacheMtx.RUnlock()
+	p, ok := m.protocols[peer.StringID()]
+	if !ok {
+		return false
+	}
+	for _, f := range p.Features {
+		if f == feature {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,180 @@
+package peermanager
+
+import (
+	"time"
+
+	"github.com/ellcrys/elld/params"
+	"github.com/ellcrys/elld/types/core"
+)
+
+// Misbehavior penalties added to the
+// score of a peer that misbehaves
+const (
+	// PenaltyMalformedMsg is the penalty for
+	// sending a message that cannot be decoded
+	PenaltyMalformedMsg = 20
+
+	// PenaltyInvalidBlock is the penalty for
+	// sending a block that fails validation
+	PenaltyInvalidBlock = 50
+
+	// PenaltyInvalidTx is the penalty for sending
+	// a transaction that fails validation
+	PenaltyInvalidTx = 10
+
+	// PenaltyInvalidAddr is the penalty for
+	// sending an invalid or unroutable address
+	PenaltyInvalidAddr = 2
+
+	// PenaltyTooManyAddrs is the penalty for sending
+	// more addresses than allowed in an Addr message
+	PenaltyTooManyAddrs = 20
+
+	// PenaltyBadRequest is the penalty for
+	// sending a request with invalid parameters
+	PenaltyBadRequest = 10
+
+	// PenaltyMsgTooLarge is the penalty for sending
+	// a message larger than the allowed maximum
+	PenaltyMsgTooLarge = 50
+
+	// PenaltyRateLimited is the penalty for sending
+	// messages faster than the allowed rate
+	PenaltyRateLimited = 5
+)
+
+// peerScore holds the misbehavior
+// score information of a peer
+type peerScore struct {
+	score       int
+	lastUpdated time.Time
+	lastReason  string
+	numBans     int
+	banEndTime  time.Time
+}
+
+// current returns the score after
+// decay since it was last updated
+func (s *peerScore) current(now time.Time) int {
+	decay := int(now.Sub(s.lastUpdated) / params.PeerScoreDecayInterval)
+	if decay >= s.score {
+		return 0
+	}
+	return s.score - decay
+}
+
+// PeerScore describes the misbehavior score of a peer
+type PeerScore struct {
+	PeerID     string `json:"peerId"`
+	Score      int    `json:"score"`
+	LastReason string `json:"lastReason"`
+	NumBans    int    `json:"numBans"`
+	BanEndTime int64  `json:"banEndTime"`
+}
+
+// banDuration returns the duration of a peer's
+// ban given the number of previous bans.
+func banDuration(numBans int) time.Duration {
+	dur := params.PeerBaseBanDuration
+	for i := 0; i < numBans && dur < params.PeerMaxBanDuration; i++ {
+		dur *= 2
+	}
+	if dur > params.PeerMaxBanDuration {
+		dur = params.PeerMaxBanDuration
+	}
+	return dur
+}
+
+// Penalize increases the misbehavior score of a peer.
+// When the score reaches params.PeerScoreBanThreshold,
+// the peer is disconnected and banned. Each ban lasts
+// twice as long as the previous one.
+func (m *Manager) Penalize(peer core.Engine, penalty int, reason string) {
+
+	now := time.Now()
+
+	m.cacheMtx.Lock()
+	ps, ok := m.scores[peer.StringID()]
+	if !ok {
+		ps = &peerScore{}
+		m.scores[peer.StringID()] = ps
+	}
+
+	ps.score = ps.current(now) + penalty
+	ps.lastUpdated = now
+	ps.lastReason = reason
+	score := ps.score
+
+	// Hardcoded seeds are disconnected
+	// but cannot be banned
+	var disconnect bool
+	var ban time.Duration
+	if score >= params.PeerScoreBanThreshold {
+		disconnect = true
+		ps.score = 0
+		if !peer.IsHardcodedSeed() {
+			ban = banDuration(ps.numBans)
+			ps.numBans++
+			ps.banEndTime = now.Add(ban)
+		}
+	}
+	m.cacheMtx.Unlock()
+
+	m.log.Debug("Penalized peer for misbehavior", "PeerID", peer.ShortID(),
+		"Penalty", penalty, "Score", score, "Reason", reason)
+
+	if !disconnect {
+		return
+	}
+
+	m.log.Info("Disconnecting misbehaving peer", "PeerID", peer.ShortID(),
+		"BanDuration", ban.String(), "Reason", reason)
+
+	if ban > 0 {
+		m.AddTimeBan(peer, ban)
+	}
+
+	m.RemoveAcquainted(peer)
+	m.localNode.GetHost().Network().ClosePeer(peer.ID())
+}
+
+// GetScore returns the current misbehavior score of a peer
+func (m *Manager) GetScore(peer core.Engine) int {
+	m.cacheMtx.RLock()
+	defer m.cacheMtx.RUnlock()
+	ps, ok := m.scores[peer.StringID()]
+	if !ok {
+		return 0
+	}
+	return ps.current(time.Now())
+}
+
+// HasMisbehaviorBan checks whether a peer is
+// serving a ban caused by misbehavior
+func (m *Manager) HasMisbehaviorBan(peer core.Engine) bool {
+	m.cacheMtx.RLock()
+	defer m.cacheMtx.RUnlock()
+	ps, ok := m.scores[peer.StringID()]
+	return ok && ps.banEndTime.After(time.Now())
+}
+
+// GetPeerScores returns the misbehavior
+// scores of peers that have misbehaved
+func (m *Manager) GetPeerScores() (scores []*PeerScore) {
+	m.cacheMtx.RLock()
+	defer m.cacheMtx.RUnlock()
+	now := time.Now()
+	for id, ps := range m.scores {
+		score := &PeerScore{
+			PeerID:     id,
+			Score:      ps.current(now),
+			LastReason: ps.lastReason,
+			NumBans:    ps.numBans,
+		}
+		if !ps.banEndTime.IsZero() {
+			score.BanEndTime = ps.banEndTime.Unix()
+		}
+		scores = append(scores, score)
+	}
+	return
+}
@@ -0,0 +1,96 @@
+package peermanager_test
+
+import (
+	"time"
+
+	"github.com/ellcrys/elld/node"
+	"github.com/ellcrys/elld/node/peermanager"
+	"github.com/ellcrys/elld/params"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PeerScore", func() {
+
+	var lp, rp *node.Node
+	var mgr *peermanager.Manager
+
+	BeforeEach(func() {
+		lp = makeTestNodeWith(getPort(), 1)
+		Expect(lp.GetBlockchain().Up()).To(BeNil())
+
+		rp = makeTestNodeWith(getPort(), 2)
+		Expect(rp.GetBlockchain().Up()).To(BeNil())
+		mgr = rp.PM()
+		mgr.SetLocalNode(rp)
+	})
+
+	AfterEach(func() {
+		closeNode(lp)
+		closeNode(rp)
+	})
+
+	Describe(".Penalize", func() {
+		When("score is below the ban threshold", func() {
+			BeforeEach(func() {
+				mgr.Penalize(lp, peermanager.PenaltyInvalidTx, "invalid transaction")
+			})
+
+			It("should increase the score of the peer", func() {
+				Expect(mgr.GetScore(lp)).To(Equal(peermanager.PenaltyInvalidTx))
+			})
+
+			It("should not ban the peer", func() {
+				Expect(mgr.HasMisbehaviorBan(lp)).To(BeFalse())
+			})
+		})
+
+		When("score reaches the ban threshold", func() {
+			BeforeEach(func() {
+				mgr.Penalize(lp, params.PeerScoreBanThreshold, "invalid block")
+			})
+
+			It("should reset the score and ban the peer", func() {
+				Expect(mgr.GetScore(lp)).To(Equal(0))
+				Expect(mgr.HasMisbehaviorBan(lp)).To(BeTrue())
+				Expect(mgr.IsBanned(lp)).To(BeTrue())
+			})
+
+			It("should not accept the peer", func() {
+				accept, err := mgr.CanAcceptNode(lp)
+				Expect(accept).To(BeFalse())
+				Expect(err).ToNot(BeNil())
+			})
+		})
+
+		When("peer is banned a second time", func() {
+			var firstBanEnd int64
+
+			BeforeEach(func() {
+				mgr.Penalize(lp, params.PeerScoreBanThreshold, "invalid block")
+				firstBanEnd = mgr.GetPeerScores()[0].BanEndTime
+				mgr.Penalize(lp, params.PeerScoreBanThreshold, "invalid block")
+			})
+
+			It("should double the ban duration", func() {
+				scores := mgr.GetPeerScores()
+				Expect(scores).To(HaveLen(1))
+				Expect(scores[0].NumBans).To(Equal(2))
+				expected := time.Now().Add(2 * params.PeerBaseBanDuration).Unix()
+				Expect(scores[0].BanEndTime).To(BeNumerically("~", expected, 2))
+				Expect(scores[0].BanEndTime).To(BeNumerically(">", firstBanEnd))
+			})
+		})
+	})
+
+	Describe(".GetPeerScores", func() {
+		It("should return scores of penalized peers", func() {
+			Expect(mgr.GetPeerScores()).To(BeEmpty())
+			mgr.Penalize(lp, peermanager.PenaltyMalformedMsg, "malformed message")
+			scores := mgr.GetPeerScores()
+			Expect(scores).To(HaveLen(1))
+			Expect(scores[0].PeerID).To(Equal(lp.StringID()))
+			Expect(scores[0].LastReason).To(Equal("malformed message"))
+		})
+	})
+})
@@ -2,11 +2,15 @@ package node
 
 import (
 	"fmt"
+	"sync"
 	"time"
 
+	"github.com/ellcrys/elld/params"
+
 	"gopkg.in/oleiade/lane.v1"
 
 	"github.com/ellcrys/elld/blockchain"
+	"github.com/ellcrys/elld/blockchain/txpool"
 	"github.com/ellcrys/elld/types"
 	"github.com/ellcrys/elld/types/core"
 	"github.com/ellcrys/elld/util/logger"
@@ -32,6 +36,15 @@ type TxManager struct {
 
 	// txBroadcastQueue store transactions to broadcast
 	txBroadcastQueue *lane.Deque
+
+	// feed delivers transaction pool events to subscribers
+	feed *txpool.Feed
+
+	// mtx protects done
+	mtx sync.Mutex
+
+	// done is closed to stop the tickers
+	done chan struct{}
 }
 
 // NewTxManager creates a new transaction manager
@@ -42,18 +55,38 @@ func NewTxManager(n *Node) *TxManager {
 		evt:              n.event,
 		bChain:           n.bChain,
 		txBroadcastQueue: lane.NewDeque(),
+		feed:             txpool.NewFeed(n.event),
+		done:             make(chan struct{}),
 	}
 }
 
 // Manage incoming transaction related events
 func (tm *TxManager) Manage() {
 
+	tm.feed.Start()
+
 	go func() {
 		ticker := time.NewTicker(3 * time.Second)
+		defer ticker.Stop()
 		for {
 			select {
 			case <-ticker.C:
 				go tm.broadcastTx()
+			case <-tm.done:
+				return
+			}
+		}
+	}()
+
+	go func() {
+		ticker := time.NewTicker(params.LocalTxRebroadcastInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				go tm.rebroadcastLocalTxs()
+			case <-tm.done:
+				return
 			}
 		}
 	}()
@@ -65,8 +98,35 @@ func (tm *TxManager) Manage() {
 	}()
 }
 
+// Stop stops the broadcast and
+// rebroadcast tickers
+func (tm *TxManager) Stop() {
+	tm.mtx.Lock()
+	defer tm.mtx.Unlock()
+	select {
+	case <-tm.done:
+	default:
+		close(tm.done)
+	}
+}
+
 // AddTx adds a transaction to the pool
 func (tm *TxManager) AddTx(tx types.Transaction) error {
+	return tm.addTx(tx, false)
+}
+
+// AddLocalTx adds a transaction submitted by a local
+// user to the pool. Local transactions are not evicted
+// from the pool and are periodically rebroadcast until
+// they are mined or expire.
+func (tm *TxManager) AddLocalTx(tx types.Transaction) error {
+	return tm.addTx(tx, true)
+}
+
+// addTx validates and adds a transaction to the pool.
+// If local is true, the transaction is added as a
+// local transaction.
+func (tm *TxManager) addTx(tx types.Transaction, local bool) error {
 
 	// TxTypeAlloc transactions are not allowed
 	if tx.GetType() == core.TxTypeAlloc {
@@ -85,7 +145,13 @@ func (tm *TxManager) AddTx(tx types.Transaction) error {
 
 	// Next we attempt to add the transaction
 	// to the transactions pool.
-	if err := tm.engine.GetTxPool().Put(tx); err != nil {
+	var err error
+	if local {
+		err = tm.engine.GetTxPool().PutLocal(tx)
+	} else {
+		err = tm.engine.GetTxPool().Put(tx)
+	}
+	if err != nil {
 		go tm.evt.Emit(core.EventTransactionInvalid, tx, err)
 		return err
 	}
@@ -111,3 +177,37 @@ func (tm *TxManager) broadcastTx() error {
 	return tm.engine.gossipMgr.BroadcastTx(tx.(types.Transaction),
 		tm.engine.PM().GetAcquaintedPeers())
 }
+
+// rebroadcastLocalTxs broadcasts all local transactions
+// that are still in the pool. Local transactions that
+// have been mined or have expired are no longer in the
+// pool and will not be rebroadcast.
+func (tm *TxManager) rebroadcastLocalTxs() {
+	for _, tx := range tm.engine.GetTxPool().GetLocal() {
+		tm.engine.gossipMgr.BroadcastTx(tx, tm.engine.PM().GetAcquaintedPeers())
+	}
+}
+
+// Feed returns the transaction pool event feed
+func (tm *TxManager) Feed() *txpool.Feed {
+	return tm.feed
+}
+
+// GetLocalTxs returns the local transactions in the pool
+func (tm *TxManager) GetLocalTxs() []types.Transaction {
+	return tm.engine.GetTxPool().GetLocal()
+}
+
+// CancelLocalTx removes a local transaction from the
+// pool and stops it from being rebroadcast. It cannot
+// stop peers that already have the transaction from
+// including it in a block.
+func (tm *TxManager) CancelLocalTx(hash string) error {
+	pool := tm.engine.GetTxPool()
+	tx := pool.GetByHash(hash)
+	if tx == nil || !pool.IsLocal(hash) {
+		return fmt.Errorf("local transaction not found")
+	}
+	pool.Remove(tx)
+	return nil
+}
@@ -20,4 +20,14 @@ var _ = Describe("Node", func() {
 	AfterEach(func() {
 		closeNode(lp)
 	})
+
+	Describe(".Stop", func() {
+		It("should stop the tickers and be safe to call more than once", func() {
+			tm := NewTxManager(lp)
+			tm.Manage()
+			tm.Stop()
+			tm.Stop()
+			Eventually(tm.done).Should(BeClosed())
+		})
+	})
 })
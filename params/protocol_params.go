@@ -10,6 +10,68 @@ var (
 	// MaxGetBlockHashes is the max number of block headers to request
 	// from a remote peer per request.
 	MaxGetBlockHashes = int64(5)
+
+	// TxInvInterval is the interval between transaction
+	// inventory announcements to a peer
+	TxInvInterval = 500 * time.Millisecond
+
+	// MaxTxInvSize is the maximum number of transaction
+	// hashes in a single inventory announcement
+	MaxTxInvSize = 1000
+
+	// MaxTxInvQueueSize is the maximum number of transaction
+	// hashes waiting to be announced to a peer
+	MaxTxInvQueueSize = 10000
+
+	// MaxMsgSize is the default maximum size of a message.
+	// Messages that carry blocks have larger limits.
+	MaxMsgSize = int64(512 * 1024)
+
+	// MsgRateLimit is the number of messages of a
+	// type a peer is allowed to send per second
+	MsgRateLimit = float64(20)
+
+	// MsgRateBurst is the number of messages of a type
+	// a peer is allowed to send in a short burst
+	MsgRateBurst = 100
+)
+
+// Peer discovery parameters
+var (
+	// DHTBucketSize is the maximum number of peers
+	// returned in response to a FindPeers message
+	DHTBucketSize = 20
+
+	// DHTConcurrency is the number of peers
+	// queried in parallel during a lookup
+	DHTConcurrency = 3
+
+	// DHTLookupInterval is the interval between
+	// lookups of random keys
+	DHTLookupInterval = 1 * time.Minute
+
+	// MDNSInterval is the interval between queries
+	// for nodes on the local network
+	MDNSInterval = 10 * time.Second
+)
+
+// Peer reputation parameters
+var (
+	// PeerScoreBanThreshold is the misbehavior score at
+	// which a peer is disconnected and banned
+	PeerScoreBanThreshold = 100
+
+	// PeerScoreDecayInterval is the duration after which
+	// a peer's misbehavior score is reduced by one point
+	PeerScoreDecayInterval = 1 * time.Minute
+
+	// PeerBaseBanDuration is the duration of a peer's first
+	// misbehavior ban. Subsequent bans double the duration.
+	PeerBaseBanDuration = 30 * time.Minute
+
+	// PeerMaxBanDuration is the maximum duration
+	// of a misbehavior ban
+	PeerMaxBanDuration = 7 * 24 * time.Hour
 )
 
 // Monetary parameters
@@ -33,6 +95,10 @@ var (
 	// transactions that can fit in a block
 	MaxBlockTxsSize = int64(9998976)
 
+	// MaxBlockTxs is the maximum number of
+	// transactions that can fit in a block
+	MaxBlockTxs = MaxBlockTxsSize / MinTxSize
+
 	// MaximumExtraDataSize is the size of extra data a block can contain.
 	MaximumExtraDataSize uint64 = 32
 
@@ -61,6 +127,10 @@ var (
 	// that can be added to the transaction pool at
 	// any given time.
 	PoolCapacity = int64(10000)
+
+	// MinTxSize is the size of the
+	// smallest possible transaction
+	MinTxSize = int64(230)
 )
 
 // Engine parameters
@@ -1,6 +1,8 @@
 package params
 
 import (
+	"time"
+
 	"github.com/shopspring/decimal"
 )
 
@@ -12,4 +14,17 @@ var (
 	// TxTTL is the number of days a transaction
 	// can last for in the pool
 	TxTTL = 7
+
+	// LocalTxRebroadcastInterval is the duration between
+	// each attempt to rebroadcast local transactions
+	LocalTxRebroadcastInterval = 5 * time.Minute
+
+	// FeeEstimatorMaxBlocks is the number of recent
+	// main chain blocks whose transaction fee rates
+	// are used for fee estimation
+	FeeEstimatorMaxBlocks = 25
+
+	// MaxTxOutputs is the maximum number of recipients
+	// of a multi-recipient transfer transaction
+	MaxTxOutputs = 500
 )
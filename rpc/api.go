@@ -33,6 +33,33 @@ func (s *Server) apiRPCEcho(params interface{}) *jsonrpc.Response {
 	return jsonrpc.Success(params)
 }
 
+func (s *Server) apiRPCTopics(params interface{}) *jsonrpc.Response {
+	return jsonrpc.Success(s.rpc.Topics())
+}
+
+func (s *Server) apiRPCDiscover(params interface{}) *jsonrpc.Response {
+	var version string
+	if s.cfg.VersionInfo != nil {
+		version = s.cfg.VersionInfo.BuildVersion
+	}
+	return jsonrpc.Success(s.rpc.OpenRPC(OpenRPCInfo(version)))
+}
+
+// OpenRPCInfo describes the RPC API in OpenRPC documents
+func OpenRPCInfo(version string) jsonrpc.OpenRPCInfo {
+	return jsonrpc.OpenRPCInfo{
+		Title:       "Elld JSON-RPC API",
+		Description: "The JSON-RPC API of the Ellcrys node",
+		Version:     version,
+	}
+}
+
+// apiAuthParams describes the parameters of auth
+var apiAuthParams = jsonrpc.Object("The credentials", map[string]*jsonrpc.Schema{
+	"username": jsonrpc.String("The username"),
+	"password": jsonrpc.String("The password"),
+})
+
 // APIs returns all API handlers
 func (s *Server) APIs() jsonrpc.APISet {
 	return map[string]jsonrpc.APIInfo{
@@ -41,6 +68,9 @@ func (s *Server) APIs() jsonrpc.APISet {
 		"auth": {
 			Namespace:   types.NamespaceAdmin,
 			Description: "Get a session token",
+			Params:      apiAuthParams,
+			Result:      jsonrpc.String("The session token"),
+			Errors:      []*jsonrpc.Err{ErrDescArgType, ErrDescInvalidAuth},
 			Func:        s.apiRPCAuth,
 		},
 
@@ -48,13 +78,28 @@ func (s *Server) APIs() jsonrpc.APISet {
 		"stop": {
 			Private:     true,
 			Namespace:   types.NamespaceRPC,
-			Description: "Get a session token",
+			Description: "Stop the RPC server",
+			Result:      jsonrpc.Boolean("Always true"),
 			Func:        s.apiRPCStop,
 		},
 		"echo": {
 			Namespace:   types.NamespaceRPC,
 			Description: "Sends back the parameter passed to it",
+			Params:      jsonrpc.Any("The value to send back"),
+			Result:      jsonrpc.Any("The value"),
 			Func:        s.apiRPCEcho,
 		},
+		"topics": {
+			Namespace:   types.NamespaceRPC,
+			Description: "List the topics WebSocket clients can subscribe to",
+			Result:      jsonrpc.Array("The topics", jsonrpc.Object("A topic", nil)),
+			Func:        s.apiRPCTopics,
+		},
+		"discover": {
+			Namespace:   types.NamespaceRPC,
+			Description: "Get the OpenRPC document describing all methods",
+			Result:      jsonrpc.Object("The OpenRPC document", nil),
+			Func:        s.apiRPCDiscover,
+		},
 	}
 }
@@ -6,19 +6,38 @@ import (
 	"github.com/ellcrys/elld/rpc/jsonrpc"
 )
 
-// auth creates a session token when username and
-// password match the configured rpc username and password.
-// The returned token can be used to access private
-// RPC APIs
+// auth creates a session token when username and password
+// match the configured rpc username and password or those
+// of one of the configured rpc users. The returned token
+// can be used to access the private RPC APIs permitted
+// by the role of the user.
 func (s *Server) auth(username, password string) (string, error) {
 
-	// Compare username and password with RPC
-	if username != s.cfg.RPC.Username || password != s.cfg.RPC.Password {
+	role := ""
+
+	// The configured rpc username and password
+	// belong to an administrator
+	if username == s.cfg.RPC.Username && password == s.cfg.RPC.Password {
+		role = RoleAdmin
+	}
+
+	for _, user := range s.cfg.RPC.Users {
+		if role == "" && username == user.Username && password == user.Password {
+			role = user.Role
+		}
+	}
+
+	if role == "" {
 		return "", fmt.Errorf("username or password are invalid")
 	}
 
-	// Create JWT token 
-	tokenStr := jsonrpc.MakeSessionToken(username, s.cfg.RPC.SessionSecretKey, s.cfg.RPC.SessionTTL)
+	if _, ok := Roles()[role]; !ok {
+		return "", fmt.Errorf("user has an unknown role")
+	}
+
+	// Create JWT token
+	tokenStr := jsonrpc.MakeRoleSessionToken(username, role, s.cfg.RPC.SessionSecretKey,
+		s.cfg.RPC.SessionTTL)
 
 	return tokenStr, nil
 }
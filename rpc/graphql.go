@@ -0,0 +1,453 @@
+package rpc
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+
+	gql "github.com/ellcrys/elld/rpc/graphql"
+	"github.com/ellcrys/elld/types"
+	"github.com/ellcrys/elld/types/core"
+	"github.com/ellcrys/elld/util"
+	"github.com/graphql-go/graphql"
+)
+
+const (
+	// GraphQLPath is the path of the GraphQL endpoint
+	GraphQLPath = "/graphql"
+
+	// graphQLMethod is the name GraphQL
+	// requests are logged with
+	graphQLMethod = "graphql"
+
+	// graphQLFieldsPerCost is the number of fields of
+	// a GraphQL query that cost as much as an RPC call
+	graphQLFieldsPerCost = 10
+
+	// graphQLMaxBlocks is the maximum number
+	// of blocks a blocks query can return
+	graphQLMaxBlocks = 50
+
+	// graphQLMaxTxs is the maximum number of transactions
+	// of a block a transactions query can return. It is
+	// also the number returned when not specified.
+	graphQLMaxTxs = 100
+)
+
+// SetBlockchain sets the blockchain queried by
+// the GraphQL endpoint. The endpoint is only
+// served when enabled in the config.
+func (s *Server) SetBlockchain(bchain types.Blockchain) {
+
+	if !s.cfg.RPC.EnableGraphQL {
+		return
+	}
+
+	schema, err := GraphQLSchema(bchain)
+	if err != nil {
+		s.log.Error("Failed to create the GraphQL schema", "Err", err.Error())
+		return
+	}
+
+	if s.cfg.RPC.GraphQLMaxDepth > 0 {
+		schema.MaxDepth = s.cfg.RPC.GraphQLMaxDepth
+	}
+	if s.cfg.RPC.GraphQLMaxComplexity > 0 {
+		schema.MaxComplexity = s.cfg.RPC.GraphQLMaxComplexity
+	}
+
+	// Queries are charged to the rate limit of
+	// the client according to their complexity
+	// and logged like other RPC requests. The
+	// result code of failed queries is their
+	// HTTP status.
+	s.rpc.Handle(GraphQLPath, &gql.Handler{
+		Schema: schema,
+		Charge: func(r *http.Request, complexity int) (bool, time.Duration) {
+			cost := math.Ceil(float64(complexity) / graphQLFieldsPerCost)
+			return s.rpc.Charge(r, cost)
+		},
+		Log: func(r *http.Request, status int, start time.Time) {
+			code := 0
+			if status != http.StatusOK {
+				code = status
+			}
+			s.rpc.LogRequest(r, graphQLMethod, code, start)
+		},
+	})
+}
+
+// GraphQLSchema creates the GraphQL schema of
+// the blocks, transactions and accounts of the
+// main chain of a blockchain
+func GraphQLSchema(bchain types.Blockchain) (*gql.Schema, error) {
+
+	account := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Account",
+		Description: "An account",
+		Fields: graphql.Fields{
+			"address": accountField(graphql.String, "The address of the account", func(a types.Account) interface{} {
+				return a.GetAddress()
+			}),
+			"balance": accountField(graphql.String, "The balance of the account", func(a types.Account) interface{} {
+				return a.GetBalance()
+			}),
+			"nonce": accountField(gql.Long, "The nonce of the account", func(a types.Account) interface{} {
+				return a.GetNonce()
+			}),
+		},
+	})
+
+	resolveAccount := func(address util.String) (interface{}, error) {
+		reader, err := mainChainReader(bchain)
+		if err != nil {
+			return nil, err
+		}
+		acct, err := reader.GetAccount(address)
+		if err != nil {
+			if err == core.ErrAccountNotFound {
+				return nil, nil
+			}
+			return nil, err
+		}
+		return acct, nil
+	}
+
+	tx := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Transaction",
+		Description: "A transaction",
+		Fields: graphql.Fields{
+			"hash": txField(graphql.String, "The hash of the transaction", func(tx types.Transaction) interface{} {
+				return tx.GetHash().HexStr()
+			}),
+			"type": txField(graphql.Int, "The type of the transaction", func(tx types.Transaction) interface{} {
+				return tx.GetType()
+			}),
+			"nonce": txField(gql.Long, "The nonce of the sender", func(tx types.Transaction) interface{} {
+				return tx.GetNonce()
+			}),
+			"from": txField(graphql.String, "The address of the sender", func(tx types.Transaction) interface{} {
+				return tx.GetFrom()
+			}),
+			"to": txField(graphql.String, "The address of the recipient", func(tx types.Transaction) interface{} {
+				return tx.GetTo()
+			}),
+			"value": txField(graphql.String, "The amount transferred", func(tx types.Transaction) interface{} {
+				return tx.GetValue()
+			}),
+			"fee": txField(graphql.String, "The fee paid", func(tx types.Transaction) interface{} {
+				return tx.GetFee()
+			}),
+			"timestamp": txField(gql.Long, "The time the transaction was created", func(tx types.Transaction) interface{} {
+				return tx.GetTimestamp()
+			}),
+			"senderPubKey": txField(graphql.String, "The public key of the sender", func(tx types.Transaction) interface{} {
+				return tx.GetSenderPubKey()
+			}),
+			"sender": {
+				Description: "The account of the sender",
+				Type:        account,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return resolveAccount(p.Source.(types.Transaction).GetFrom())
+				},
+			},
+			"recipient": {
+				Description: "The account of the recipient",
+				Type:        account,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return resolveAccount(p.Source.(types.Transaction).GetTo())
+				},
+			},
+		},
+	})
+
+	block := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Block",
+		Description: "A block",
+		Fields: graphql.Fields{
+			"number": blockField(gql.Long, "The number of the block", func(b types.Block) interface{} {
+				return b.GetNumber()
+			}),
+			"hash": blockField(graphql.String, "The hash of the block", func(b types.Block) interface{} {
+				return b.GetHashAsHex()
+			}),
+			"parentHash": blockField(graphql.String, "The hash of the parent block", func(b types.Block) interface{} {
+				return b.GetHeader().GetParentHash().HexStr()
+			}),
+			"timestamp": blockField(gql.Long, "The time the block was created", func(b types.Block) interface{} {
+				return b.GetHeader().GetTimestamp()
+			}),
+			"difficulty": blockField(graphql.String, "The difficulty of the block", func(b types.Block) interface{} {
+				return b.GetHeader().GetDifficulty().String()
+			}),
+			"totalDifficulty": blockField(graphql.String, "The total difficulty of the chain", func(b types.Block) interface{} {
+				return b.GetHeader().GetTotalDifficulty().String()
+			}),
+			"creatorPubKey": blockField(graphql.String, "The public key of the creator", func(b types.Block) interface{} {
+				return b.GetHeader().GetCreatorPubKey()
+			}),
+			"stateRoot": blockField(graphql.String, "The root of the state tree", func(b types.Block) interface{} {
+				return b.GetHeader().GetStateRoot().HexStr()
+			}),
+			"transactionsRoot": blockField(graphql.String, "The root of the transactions tree", func(b types.Block) interface{} {
+				return b.GetHeader().GetTransactionsRoot().HexStr()
+			}),
+			"size": blockField(gql.Long, "The size of the block in bytes", func(b types.Block) interface{} {
+				return b.GetSize()
+			}),
+			"transactionCount": blockField(graphql.Int, "The number of transactions", func(b types.Block) interface{} {
+				return len(b.GetTransactions())
+			}),
+		},
+	})
+
+	block.AddFieldConfig("transactions", &graphql.Field{
+		Description: fmt.Sprintf("The transactions of the block (at most %d)", graphQLMaxTxs),
+		Type:        graphql.NewList(tx),
+		Args: graphql.FieldConfigArgument{
+			"first": {Type: graphql.Int, Description: fmt.Sprintf("The number of transactions "+
+				"to return (Default: %d)", graphQLMaxTxs)},
+			"skip": {Type: graphql.Int, Description: "The number of transactions to skip"},
+		},
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			first, skip, err := txsRange(p.Args)
+			if err != nil {
+				return nil, err
+			}
+			txs := p.Source.(types.Block).GetTransactions()
+			if skip >= len(txs) {
+				return []types.Transaction{}, nil
+			}
+			txs = txs[skip:]
+			if first < len(txs) {
+				txs = txs[:first]
+			}
+			return txs, nil
+		},
+	})
+
+	block.AddFieldConfig("parent", &graphql.Field{
+		Description: "The parent block",
+		Type:        block,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			b := p.Source.(types.Block)
+			if b.GetNumber() <= 1 {
+				return nil, nil
+			}
+			return getBlock(bchain, nil, b.GetHeader().GetParentHash().HexStr())
+		},
+	})
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"tip": {
+				Description: "The highest block on the main chain",
+				Type:        block,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return getBlock(bchain, int64(0), nil)
+				},
+			},
+			"block": {
+				Description: "A block on the main chain by number or hash",
+				Type:        block,
+				Args: graphql.FieldConfigArgument{
+					"number": {Type: gql.Long, Description: "The number of the block"},
+					"hash":   {Type: graphql.String, Description: "The hash of the block"},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					number, hash := p.Args["number"], p.Args["hash"]
+					if number == nil && hash == nil {
+						return nil, fmt.Errorf("number or hash is required")
+					}
+					return getBlock(bchain, number, hash)
+				},
+			},
+			"blocks": {
+				Description: fmt.Sprintf("A range of blocks on the main chain (at most %d)",
+					graphQLMaxBlocks),
+				Type: graphql.NewList(block),
+				Args: graphql.FieldConfigArgument{
+					"from": {Type: graphql.NewNonNull(gql.Long), Description: "The number of the first block"},
+					"to":   {Type: graphql.NewNonNull(gql.Long), Description: "The number of the last block"},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					from, to := p.Args["from"].(int64), p.Args["to"].(int64)
+					if from < 1 || to < from {
+						return nil, fmt.Errorf("invalid range")
+					}
+					if to-from >= graphQLMaxBlocks {
+						return nil, fmt.Errorf("range cannot include more than %d blocks",
+							graphQLMaxBlocks)
+					}
+					var blocks []interface{}
+					for n := from; n <= to; n++ {
+						b, err := getBlock(bchain, n, nil)
+						if err != nil {
+							return nil, err
+						}
+						if b == nil {
+							break
+						}
+						blocks = append(blocks, b)
+					}
+					return blocks, nil
+				},
+			},
+			"transaction": {
+				Description: "A transaction on the main chain",
+				Type:        tx,
+				Args: graphql.FieldConfigArgument{
+					"hash": {Type: graphql.NewNonNull(graphql.String), Description: "The hash of the transaction"},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					hash, err := util.HexToHash(p.Args["hash"].(string))
+					if err != nil {
+						return nil, fmt.Errorf("invalid transaction hash")
+					}
+					transaction, err := bchain.GetTransaction(hash)
+					if err != nil {
+						if err == core.ErrTxNotFound {
+							return nil, nil
+						}
+						return nil, err
+					}
+					return transaction, nil
+				},
+			},
+			"account": {
+				Description: "An account",
+				Type:        account,
+				Args: graphql.FieldConfigArgument{
+					"address": {Type: graphql.NewNonNull(graphql.String), Description: "The address of the account"},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return resolveAccount(util.String(p.Args["address"].(string)))
+				},
+			},
+		},
+	})
+
+	schema, err := gql.NewSchema(graphql.SchemaConfig{Query: query})
+	if err != nil {
+		return nil, err
+	}
+
+	// The complexity of a list is the number of items
+	// it can return times the complexity of an item
+	schema.Complexity["Block.transactions"] = func(args map[string]interface{}, childComplexity int) int {
+		first, _, err := txsRange(args)
+		if err != nil {
+			first = 1
+		}
+		return 1 + first*childComplexity
+	}
+	schema.Complexity["Query.blocks"] = func(args map[string]interface{}, childComplexity int) int {
+		from, _ := args["from"].(int)
+		to, _ := args["to"].(int)
+		n := to - from + 1
+		if n < 1 || n > graphQLMaxBlocks {
+			n = 1
+		}
+		return 1 + n*childComplexity
+	}
+
+	return schema, nil
+}
+
+// blockField creates a scalar field of a block
+func blockField(typ graphql.Output, desc string, get func(types.Block) interface{}) *graphql.Field {
+	return &graphql.Field{
+		Type:        typ,
+		Description: desc,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return get(p.Source.(types.Block)), nil
+		},
+	}
+}
+
+// txField creates a scalar field of a transaction
+func txField(typ graphql.Output, desc string, get func(types.Transaction) interface{}) *graphql.Field {
+	return &graphql.Field{
+		Type:        typ,
+		Description: desc,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return get(p.Source.(types.Transaction)), nil
+		},
+	}
+}
+
+// accountField creates a scalar field of an account
+func accountField(typ graphql.Output, desc string, get func(types.Account) interface{}) *graphql.Field {
+	return &graphql.Field{
+		Type:        typ,
+		Description: desc,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return get(p.Source.(types.Account)), nil
+		},
+	}
+}
+
+// txsRange returns the number of transactions
+// to return and skip given the arguments of
+// a transactions query
+func txsRange(args map[string]interface{}) (first, skip int, err error) {
+	first = graphQLMaxTxs
+	if v, ok := args["first"].(int); ok {
+		if v < 0 || v > graphQLMaxTxs {
+			return 0, 0, fmt.Errorf("first must be between 0 and %d", graphQLMaxTxs)
+		}
+		first = v
+	}
+	if v, ok := args["skip"].(int); ok {
+		if v < 0 {
+			return 0, 0, fmt.Errorf("skip cannot be negative")
+		}
+		skip = v
+	}
+	return
+}
+
+// mainChainReader returns a reader of the main chain
+func mainChainReader(bchain types.Blockchain) (types.ChainReaderFactory, error) {
+	if bchain.GetBestChain() == nil {
+		return nil, fmt.Errorf("best chain not set")
+	}
+	return bchain.ChainReader(), nil
+}
+
+// getBlock finds a block on the main chain by number or
+// hash. A zero number refers to the highest block. It
+// returns nil if the block does not exist.
+func getBlock(bchain types.Blockchain, number, hash interface{}) (interface{}, error) {
+
+	reader, err := mainChainReader(bchain)
+	if err != nil {
+		return nil, err
+	}
+
+	var block types.Block
+	if number != nil {
+		n := number.(int64)
+		if n < 0 {
+			return nil, fmt.Errorf("invalid block number")
+		}
+		block, err = reader.GetBlock(uint64(n))
+	} else {
+		var blockHash util.Hash
+		if blockHash, err = util.HexToHash(hash.(string)); err != nil {
+			return nil, fmt.Errorf("invalid block hash")
+		}
+		block, err = reader.GetBlockByHash(blockHash)
+	}
+
+	if err != nil {
+		if err == core.ErrBlockNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return block, nil
+}
@@ -0,0 +1,141 @@
+package graphql
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/graphql-go/graphql"
+)
+
+// MaxRequestSize is the maximum size
+// of the body of a request in bytes
+const MaxRequestSize = 1 << 20
+
+var (
+	errQueryRequired    = fmt.Errorf("query is required")
+	errRequestTooLarge  = fmt.Errorf("request too large")
+	errInvalidBody      = fmt.Errorf("invalid request body")
+	errInvalidVariables = fmt.Errorf("invalid variables")
+)
+
+// request is a GraphQL request
+type request struct {
+	Query         string                 `json:"query"`
+	Variables     map[string]interface{} `json:"variables"`
+	OperationName string                 `json:"operationName"`
+}
+
+// Handler serves GraphQL queries over HTTP. Queries
+// are accepted as the parameters of GET requests or
+// as the JSON body of POST requests.
+type Handler struct {
+
+	// Schema is the schema of the queries
+	Schema *Schema
+
+	// Charge is called with the complexity of a query
+	// before it is executed. If it returns false, the
+	// query is rejected and the client is told to retry
+	// after the returned duration.
+	Charge func(r *http.Request, complexity int) (bool, time.Duration)
+
+	// Log is called with the HTTP status of the response
+	// and the time the request was received after a
+	// request is served
+	Log func(r *http.Request, status int, start time.Time)
+}
+
+// ServeHTTP implements http.Handler
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+
+	start := time.Now()
+	status := http.StatusOK
+	if h.Log != nil {
+		defer func() { h.Log(r, status, start) }()
+	}
+
+	respond := func(code int, result *graphql.Result) {
+		status = code
+		writeResult(w, code, result)
+	}
+
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		w.Header().Set("Allow", "GET, POST")
+		respond(http.StatusMethodNotAllowed, errorResult(fmt.Errorf("method not allowed")))
+		return
+	}
+
+	req, err := readRequest(w, r)
+	if err != nil {
+		code := http.StatusBadRequest
+		if err == errRequestTooLarge {
+			code = http.StatusRequestEntityTooLarge
+		}
+		respond(code, errorResult(err))
+		return
+	}
+
+	q, err := h.Schema.Prepare(req.Query, req.Variables, req.OperationName)
+	if err != nil {
+		respond(http.StatusBadRequest, errorResult(err))
+		return
+	}
+
+	if h.Charge != nil {
+		if ok, wait := h.Charge(r, q.Complexity()); !ok {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			respond(http.StatusTooManyRequests, errorResult(fmt.Errorf("rate limit exceeded")))
+			return
+		}
+	}
+
+	respond(http.StatusOK, q.Execute(r.Context()))
+}
+
+// readRequest reads the query of a GET or POST request.
+// The body of a POST request is limited to MaxRequestSize.
+func readRequest(w http.ResponseWriter, r *http.Request) (*request, error) {
+
+	var req request
+	switch r.Method {
+	case http.MethodGet:
+		values := r.URL.Query()
+		req.Query = values.Get("query")
+		req.OperationName = values.Get("operationName")
+		if vars := values.Get("variables"); vars != "" {
+			if err := json.Unmarshal([]byte(vars), &req.Variables); err != nil {
+				return nil, errInvalidVariables
+			}
+		}
+
+	default:
+		body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, MaxRequestSize))
+		if err != nil {
+			return nil, errRequestTooLarge
+		}
+		if strings.HasPrefix(r.Header.Get("Content-Type"), "application/graphql") {
+			req.Query = string(body)
+		} else if err := json.Unmarshal(body, &req); err != nil {
+			return nil, errInvalidBody
+		}
+	}
+
+	if req.Query == "" {
+		return nil, errQueryRequired
+	}
+
+	return &req, nil
+}
+
+// writeResult writes the result of a query
+func writeResult(w http.ResponseWriter, status int, result *graphql.Result) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(result)
+}
@@ -0,0 +1,100 @@
+package graphql
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Handler", func() {
+
+	var h *Handler
+
+	BeforeEach(func() {
+		h = &Handler{Schema: testSchema()}
+	})
+
+	serve := func(req *http.Request) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+		return rr
+	}
+
+	It("should execute the query of a GET request", func() {
+		req, _ := http.NewRequest("GET", "/graphql?query="+
+			url.QueryEscape(`query($n: Int!) { block(number: $n) { number } }`)+
+			"&variables="+url.QueryEscape(`{"n": 3}`), nil)
+		rr := serve(req)
+		Expect(rr.Code).To(Equal(200))
+		Expect(rr.Body.String()).To(MatchJSON(`{"data":{"block":{"number":3}}}`))
+	})
+
+	It("should execute the query of a POST request", func() {
+		body := []byte(`{"query": "{ block(number: 2) { number } }"}`)
+		req, _ := http.NewRequest("POST", "/graphql", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rr := serve(req)
+		Expect(rr.Code).To(Equal(200))
+		Expect(rr.Body.String()).To(MatchJSON(`{"data":{"block":{"number":2}}}`))
+	})
+
+	It("should return 400 when the query is invalid", func() {
+		req, _ := http.NewRequest("POST", "/graphql", bytes.NewReader([]byte(`{"query": "{"}`)))
+		rr := serve(req)
+		Expect(rr.Code).To(Equal(400))
+		Expect(rr.Body.String()).To(ContainSubstring(`Syntax Error`))
+	})
+
+	It("should return 413 when the body is too large", func() {
+		body := bytes.Repeat([]byte(" "), MaxRequestSize+1)
+		req, _ := http.NewRequest("POST", "/graphql", bytes.NewReader(body))
+		rr := serve(req)
+		Expect(rr.Code).To(Equal(http.StatusRequestEntityTooLarge))
+	})
+
+	It("should return 400 when the query is nested too deeply", func() {
+		query := strings.Repeat("[", maxNesting+1)
+		body, _ := json.Marshal(map[string]string{"query": "{ block(number: " + query + ") { number } }"})
+		req, _ := http.NewRequest("POST", "/graphql", bytes.NewReader(body))
+		rr := serve(req)
+		Expect(rr.Code).To(Equal(400))
+		Expect(rr.Body.String()).To(ContainSubstring("query is nested too deeply"))
+	})
+
+	It("should log the request with the status of the response", func() {
+		var logged int
+		h.Log = func(r *http.Request, status int, start time.Time) {
+			logged = status
+		}
+		req, _ := http.NewRequest("POST", "/graphql", bytes.NewReader([]byte(`{"query": "{"}`)))
+		serve(req)
+		Expect(logged).To(Equal(400))
+	})
+
+	It("should return 405 when the method is not GET or POST", func() {
+		req, _ := http.NewRequest("PUT", "/graphql", nil)
+		rr := serve(req)
+		Expect(rr.Code).To(Equal(http.StatusMethodNotAllowed))
+	})
+
+	It("should charge the complexity of the query and reject it when not allowed", func() {
+		var charged int
+		h.Charge = func(r *http.Request, complexity int) (bool, time.Duration) {
+			charged = complexity
+			return false, 1500 * time.Millisecond
+		}
+		body := []byte(`{"query": "{ block(number: 2) { number } }"}`)
+		req, _ := http.NewRequest("POST", "/graphql", bytes.NewReader(body))
+		rr := serve(req)
+		Expect(charged).To(Equal(2))
+		Expect(rr.Code).To(Equal(http.StatusTooManyRequests))
+		Expect(rr.Header().Get("Retry-After")).To(Equal("2"))
+	})
+})
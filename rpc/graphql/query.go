@@ -0,0 +1,332 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+)
+
+// maxNesting is the maximum nesting of the selection
+// sets, values and arguments of a query. It bounds the
+// recursion of the parser. The depth of the selections
+// of a query is further limited by the schema.
+const maxNesting = 64
+
+// Errors are the errors of a query
+// that cannot be executed
+type Errors []gqlerrors.FormattedError
+
+// Error implements the error interface
+func (e Errors) Error() string {
+	var msgs []string
+	for _, err := range e {
+		msgs = append(msgs, err.Message)
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Query is a validated query
+// that is ready to be executed
+type Query struct {
+	schema        *Schema
+	doc           *ast.Document
+	op            *ast.OperationDefinition
+	fragments     map[string]*ast.FragmentDefinition
+	variables     map[string]interface{}
+	operationName string
+	complexity    int
+}
+
+// Complexity returns the complexity of the query
+func (q *Query) Complexity() int {
+	return q.complexity
+}
+
+// Prepare parses and validates a query. The query is
+// rejected if it exceeds the maximum depth or
+// complexity of the schema.
+func (s *Schema) Prepare(query string, variables map[string]interface{}, operationName string) (*Query, error) {
+
+	if err := checkNesting(query); err != nil {
+		return nil, err
+	}
+
+	doc, err := parser.Parse(parser.ParseParams{
+		Source: source.NewSource(&source.Source{Body: []byte(query), Name: "GraphQL request"}),
+	})
+	if err != nil {
+		return nil, Errors(gqlerrors.FormatErrors(err))
+	}
+
+	if res := graphql.ValidateDocument(&s.Schema, doc, nil); !res.IsValid {
+		return nil, Errors(res.Errors)
+	}
+
+	q := &Query{
+		schema:        s,
+		doc:           doc,
+		fragments:     make(map[string]*ast.FragmentDefinition),
+		variables:     variables,
+		operationName: operationName,
+	}
+
+	if err := q.selectOperation(); err != nil {
+		return nil, err
+	}
+
+	if q.op.Operation != ast.OperationTypeQuery {
+		return nil, fmt.Errorf("%s operations are not supported", q.op.Operation)
+	}
+
+	if q.complexity, err = q.analyze(s.Schema.QueryType(), q.op.SelectionSet, 1); err != nil {
+		return nil, err
+	}
+
+	if s.MaxComplexity > 0 && q.complexity > s.MaxComplexity {
+		return nil, fmt.Errorf("query complexity %d exceeds the maximum of %d",
+			q.complexity, s.MaxComplexity)
+	}
+
+	return q, nil
+}
+
+// Execute prepares and executes a query
+func (s *Schema) Execute(ctx context.Context, query string, variables map[string]interface{},
+	operationName string) *graphql.Result {
+	q, err := s.Prepare(query, variables, operationName)
+	if err != nil {
+		return errorResult(err)
+	}
+	return q.Execute(ctx)
+}
+
+// Execute executes the query. Fields whose
+// resolvers fail are set to null and their
+// errors are added to the result.
+func (q *Query) Execute(ctx context.Context) *graphql.Result {
+	return graphql.Execute(graphql.ExecuteParams{
+		Schema:        q.schema.Schema,
+		AST:           q.doc,
+		OperationName: q.operationName,
+		Args:          q.variables,
+		Context:       ctx,
+	})
+}
+
+// selectOperation finds the operation to execute
+// and the fragments of the document
+func (q *Query) selectOperation() error {
+
+	var ops []*ast.OperationDefinition
+	for _, def := range q.doc.Definitions {
+		switch def := def.(type) {
+		case *ast.OperationDefinition:
+			ops = append(ops, def)
+		case *ast.FragmentDefinition:
+			q.fragments[def.Name.Value] = def
+		}
+	}
+
+	if q.operationName == "" {
+		if len(ops) != 1 {
+			return fmt.Errorf("operation name is required when " +
+				"the query has multiple operations")
+		}
+		q.op = ops[0]
+		return nil
+	}
+
+	for _, op := range ops {
+		if op.Name != nil && op.Name.Value == q.operationName {
+			q.op = op
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unknown operation %q", q.operationName)
+}
+
+// analyze computes the complexity of the selections of a
+// type. Fragments are expanded in place. The query must
+// have been validated; validation rejects unknown fields
+// and fragment cycles.
+func (q *Query) analyze(typ graphql.Type, set *ast.SelectionSet, depth int) (int, error) {
+
+	if set == nil {
+		return 0, nil
+	}
+
+	if q.schema.MaxDepth > 0 && depth > q.schema.MaxDepth {
+		return 0, fmt.Errorf("query exceeds the maximum depth of %d", q.schema.MaxDepth)
+	}
+
+	complexity := 0
+	for _, sel := range set.Selections {
+
+		var c int
+		var err error
+		switch sel := sel.(type) {
+		case *ast.Field:
+			c, err = q.analyzeField(typ, sel, depth)
+		case *ast.InlineFragment:
+			fragType := typ
+			if sel.TypeCondition != nil {
+				fragType = q.schema.Schema.Type(sel.TypeCondition.Name.Value)
+			}
+			c, err = q.analyze(fragType, sel.SelectionSet, depth)
+		case *ast.FragmentSpread:
+			frag := q.fragments[sel.Name.Value]
+			c, err = q.analyze(q.schema.Schema.Type(frag.TypeCondition.Name.Value),
+				frag.SelectionSet, depth)
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		complexity += c
+	}
+
+	return complexity, nil
+}
+
+// analyzeField computes the complexity of a field
+func (q *Query) analyzeField(typ graphql.Type, field *ast.Field, depth int) (int, error) {
+
+	name := field.Name.Value
+	if name == graphql.TypeNameMetaFieldDef.Name {
+		return 0, nil
+	}
+
+	def := fieldDef(typ, name)
+	if def == nil {
+		return 1, nil
+	}
+
+	childType, _ := graphql.GetNamed(def.Type).(graphql.Type)
+	childComplexity, err := q.analyze(childType, field.SelectionSet, depth+1)
+	if err != nil {
+		return 0, err
+	}
+
+	if f := q.schema.Complexity[typ.Name()+"."+name]; f != nil {
+		return f(q.argValues(field), childComplexity), nil
+	}
+
+	return 1 + childComplexity, nil
+}
+
+// fieldDef finds the definition of a field of a type
+func fieldDef(typ graphql.Type, name string) *graphql.FieldDefinition {
+
+	switch name {
+	case graphql.SchemaMetaFieldDef.Name:
+		return graphql.SchemaMetaFieldDef
+	case graphql.TypeMetaFieldDef.Name:
+		return graphql.TypeMetaFieldDef
+	}
+
+	switch typ := typ.(type) {
+	case *graphql.Object:
+		return typ.Fields()[name]
+	case *graphql.Interface:
+		return typ.Fields()[name]
+	}
+
+	return nil
+}
+
+// argValues returns the Int arguments of a field.
+// Arguments set with variables use the value or the
+// default value of the variable. Numbers of variables
+// are decoded as float64 so they are converted to int.
+func (q *Query) argValues(field *ast.Field) map[string]interface{} {
+
+	args := make(map[string]interface{})
+	for _, arg := range field.Arguments {
+
+		value := arg.Value
+		if v, ok := value.(*ast.Variable); ok {
+			if n, ok := q.variables[v.Name.Value].(float64); ok {
+				if n == math.Trunc(n) && math.Abs(n) <= math.MaxInt32 {
+					args[arg.Name.Value] = int(n)
+				}
+				continue
+			}
+			value = q.variableDefault(v.Name.Value)
+		}
+
+		if v, ok := value.(*ast.IntValue); ok {
+			if n, err := strconv.ParseInt(v.Value, 10, 32); err == nil {
+				args[arg.Name.Value] = int(n)
+			}
+		}
+	}
+
+	return args
+}
+
+// variableDefault returns the default
+// value of a variable of the operation
+func (q *Query) variableDefault(name string) ast.Value {
+	for _, def := range q.op.VariableDefinitions {
+		if def.Variable.Name.Value == name {
+			return def.DefaultValue
+		}
+	}
+	return nil
+}
+
+// checkNesting checks that the selection sets, values and
+// arguments of a query are not nested more than maxNesting
+// levels. Strings and comments are skipped.
+func checkNesting(query string) error {
+
+	depth := 0
+	for i := 0; i < len(query); i++ {
+		switch query[i] {
+		case '{', '[', '(':
+			if depth++; depth > maxNesting {
+				return fmt.Errorf("syntax error: query is nested too deeply at position %d", i)
+			}
+		case '}', ']', ')':
+			depth--
+		case '#':
+			for i < len(query) && query[i] != '\n' && query[i] != '\r' {
+				i++
+			}
+		case '"':
+			if strings.HasPrefix(query[i:], `"""`) {
+				end := strings.Index(query[i+3:], `"""`)
+				if end < 0 {
+					return nil
+				}
+				i += end + 5
+				break
+			}
+			for i++; i < len(query) && query[i] != '"' && query[i] != '\n'; i++ {
+				if query[i] == '\\' {
+					i++
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// errorResult creates the result of
+// a query that cannot be executed
+func errorResult(err error) *graphql.Result {
+	if errs, ok := err.(Errors); ok {
+		return &graphql.Result{Errors: errs}
+	}
+	return &graphql.Result{Errors: []gqlerrors.FormattedError{{Message: err.Error()}}}
+}
@@ -0,0 +1,178 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// testSchema creates a schema of blocks
+// with transactions and their senders
+func testSchema() *Schema {
+	account := graphql.NewObject(graphql.ObjectConfig{Name: "Account", Fields: graphql.Fields{
+		"address": {Type: graphql.String, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source.(string), nil
+		}},
+	}})
+	tx := graphql.NewObject(graphql.ObjectConfig{Name: "Transaction", Fields: graphql.Fields{
+		"hash": {Type: graphql.String, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source.(string), nil
+		}},
+		"sender": {Type: account, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return "e" + p.Source.(string), nil
+		}},
+	}})
+	block := graphql.NewObject(graphql.ObjectConfig{Name: "Block", Fields: graphql.Fields{
+		"number": {Type: Long, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source, nil
+		}},
+		"transactions": {
+			Type: graphql.NewList(tx),
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return []string{"0x1", "0x2"}, nil
+			},
+		},
+		"broken": {Type: graphql.String, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return nil, fmt.Errorf("failed")
+		}},
+	}})
+	s, err := NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{Name: "Query", Fields: graphql.Fields{
+			"block": {
+				Type: block,
+				Args: graphql.FieldConfigArgument{
+					"number": {Type: graphql.NewNonNull(graphql.Int)},
+					"note":   {Type: graphql.String},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Args["number"], nil
+				},
+			},
+		}}),
+	})
+	Expect(err).To(BeNil())
+	s.Complexity["Block.transactions"] = func(args map[string]interface{}, childComplexity int) int {
+		return 1 + 10*childComplexity
+	}
+	return s
+}
+
+// execute executes a query and returns the JSON encoded result
+func execute(s *Schema, query string, variables map[string]interface{}) string {
+	bs, _ := json.Marshal(s.Execute(context.Background(), query, variables, ""))
+	return string(bs)
+}
+
+var _ = Describe("Query", func() {
+
+	var s *Schema
+
+	BeforeEach(func() {
+		s = testSchema()
+	})
+
+	It("should resolve nested objects, lists and aliases", func() {
+		res := execute(s, `{ block(number: 5) { number, txs: transactions { hash sender { address } } } }`, nil)
+		Expect(res).To(MatchJSON(`{"data":{"block":{"number":5,"txs":[` +
+			`{"hash":"0x1","sender":{"address":"e0x1"}},` +
+			`{"hash":"0x2","sender":{"address":"e0x2"}}]}}}`))
+	})
+
+	It("should resolve variables and __typename", func() {
+		res := execute(s, `query Q($n: Int!) { block(number: $n) { __typename number } }`,
+			map[string]interface{}{"n": float64(7)})
+		Expect(res).To(MatchJSON(`{"data":{"block":{"__typename":"Block","number":7}}}`))
+	})
+
+	It("should resolve fragments and directives", func() {
+		res := execute(s, `query { block(number: 2) { ...F ... on Block { broken @skip(if: true) } } }
+			fragment F on Block { number }`, nil)
+		Expect(res).To(MatchJSON(`{"data":{"block":{"number":2}}}`))
+	})
+
+	It("should resolve block strings", func() {
+		Expect(execute(s, `{ block(number: 2, note: """a "quoted" { note""") { number } }`, nil)).
+			To(MatchJSON(`{"data":{"block":{"number":2}}}`))
+	})
+
+	It("should support introspection", func() {
+		res := execute(s, `{ __type(name: "Block") { name } }`, nil)
+		Expect(res).To(MatchJSON(`{"data":{"__type":{"name":"Block"}}}`))
+	})
+
+	It("should set fields that failed to null and return their errors", func() {
+		res := execute(s, `{ block(number: 1) { broken } }`, nil)
+		Expect(res).To(ContainSubstring(`"data":{"block":{"broken":null}}`))
+		Expect(res).To(ContainSubstring(`"message":"failed"`))
+	})
+
+	It("should return error when a required argument is missing", func() {
+		res := execute(s, `{ block { number } }`, nil)
+		Expect(res).To(ContainSubstring(`argument \"number\" of type \"Int!\" is required`))
+	})
+
+	It("should return error when a field is unknown", func() {
+		res := execute(s, `{ unknown }`, nil)
+		Expect(res).To(ContainSubstring(`Cannot query field \"unknown\" on type \"Query\"`))
+	})
+
+	It("should reject mutations", func() {
+		_, err := s.Prepare(`mutation { block }`, nil, "")
+		Expect(err).ToNot(BeNil())
+	})
+
+	It("should reject queries that exceed the maximum depth", func() {
+		s.MaxDepth = 2
+		res := execute(s, `{ block(number: 1) { transactions { sender { address } } } }`, nil)
+		Expect(res).To(ContainSubstring("query exceeds the maximum depth of 2"))
+	})
+
+	It("should count the depth of the selections of fragments", func() {
+		s.MaxDepth = 3
+		res := execute(s, `{ block(number: 1) { ...F } } fragment F on Block { transactions { hash } }`, nil)
+		Expect(res).To(ContainSubstring(`"data":{"block":{"transactions"`))
+		res = execute(s, `{ block(number: 1) { ...F } } fragment F on Block { transactions { sender { address } } }`, nil)
+		Expect(res).To(ContainSubstring("query exceeds the maximum depth of 3"))
+	})
+
+	It("should reject queries that exceed the maximum complexity", func() {
+		s.MaxComplexity = 20
+		_, err := s.Prepare(`{ block(number: 1) { transactions { hash sender { address } } } }`, nil, "")
+		Expect(err).ToNot(BeNil())
+		Expect(err.Error()).To(Equal("query complexity 32 exceeds the maximum of 20"))
+	})
+
+	It("should compute the complexity of a query", func() {
+		q, err := s.Prepare(`{ block(number: 1) { number transactions { hash } } }`, nil, "")
+		Expect(err).To(BeNil())
+		Expect(q.Complexity()).To(Equal(13))
+	})
+
+	It("should pass the arguments of a field to its complexity function", func() {
+		var args map[string]interface{}
+		s.Complexity["Query.block"] = func(a map[string]interface{}, childComplexity int) int {
+			args = a
+			return 1 + childComplexity
+		}
+		_, err := s.Prepare(`query($n: Int = 4) { a: block(number: 3) { number } b: block(number: $n) { number } }`,
+			nil, "")
+		Expect(err).To(BeNil())
+		Expect(args).To(Equal(map[string]interface{}{"number": 4}))
+	})
+})
+
+var _ = Describe("checkNesting", func() {
+
+	It("should ignore brackets in strings and comments", func() {
+		Expect(checkNesting(`{ a(s: "[[[") # {{{` + "\n" + `b(s: """[[[""") }`)).To(BeNil())
+	})
+
+	It("should return error when the query is nested too deeply", func() {
+		Expect(checkNesting(strings.Repeat("[", maxNesting+1))).ToNot(BeNil())
+	})
+})
@@ -0,0 +1,92 @@
+// Package graphql serves GraphQL queries over HTTP. Queries
+// are parsed, validated and executed by graphql-go. The
+// package limits the depth and complexity of queries
+// so that they can be charged to the rate limit of
+// the client before they are executed.
+package graphql
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// Default limits of queries
+const (
+	DefaultMaxDepth      = 10
+	DefaultMaxComplexity = 1000
+)
+
+// ComplexityFunc computes the complexity of a field
+// from its arguments and the complexity of its
+// selections. Int arguments are int.
+type ComplexityFunc func(args map[string]interface{}, childComplexity int) int
+
+// Schema is a GraphQL schema and the
+// limits of the queries it executes
+type Schema struct {
+
+	// Schema is the schema of the queries
+	Schema graphql.Schema
+
+	// Complexity contains the functions that compute the
+	// complexity of fields. They are keyed by the name of
+	// the type and the name of the field separated by a
+	// dot (e.g "Block.transactions"). The complexity of
+	// other fields is 1 plus the complexity of their
+	// selections.
+	Complexity map[string]ComplexityFunc
+
+	// MaxDepth is the maximum depth
+	// of the selections of a query
+	MaxDepth int
+
+	// MaxComplexity is the maximum
+	// complexity of a query
+	MaxComplexity int
+}
+
+// NewSchema creates a schema with the
+// default limits of queries
+func NewSchema(config graphql.SchemaConfig) (*Schema, error) {
+	schema, err := graphql.NewSchema(config)
+	if err != nil {
+		return nil, err
+	}
+	return &Schema{
+		Schema:        schema,
+		Complexity:    make(map[string]ComplexityFunc),
+		MaxDepth:      DefaultMaxDepth,
+		MaxComplexity: DefaultMaxComplexity,
+	}, nil
+}
+
+// Long is a scalar of 64-bit integers. The Int
+// scalar of GraphQL is limited to 32 bits.
+var Long = graphql.NewScalar(graphql.ScalarConfig{
+	Name:        "Long",
+	Description: "The `Long` scalar type represents 64-bit integers",
+	Serialize: func(value interface{}) interface{} {
+		switch v := value.(type) {
+		case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+			return v
+		}
+		return nil
+	},
+	ParseValue: func(value interface{}) interface{} {
+		if v, ok := value.(float64); ok && v == math.Trunc(v) && math.Abs(v) < 1<<63 {
+			return int64(v)
+		}
+		return nil
+	},
+	ParseLiteral: func(valueAST ast.Value) interface{} {
+		if v, ok := valueAST.(*ast.IntValue); ok {
+			if n, err := strconv.ParseInt(v.Value, 10, 64); err == nil {
+				return n
+			}
+		}
+		return nil
+	},
+})
@@ -12,6 +12,12 @@ func (p *Params) Scan(dest interface{}) error {
 	return util.MapDecode(p, &dest)
 }
 
+// StreamFunc is the type of API function that
+// sends a sequence of results to the client. It
+// must call send for every result and return when
+// done is closed or when send returns an error.
+type StreamFunc func(params interface{}, send func(result interface{}) error, done <-chan struct{}) error
+
 // APIInfo defines a standard API function type
 // and other parameters.
 type APIInfo struct {
@@ -19,6 +25,11 @@ type APIInfo struct {
 	// Func is the API function to be execute.
 	Func func(params interface{}) *Response
 
+	// Stream is the API function to be executed
+	// for methods that stream results. When set,
+	// Func is ignored.
+	Stream StreamFunc
+
 	// Private indicates a requirement for a private, authenticated
 	// user session before this API function is executed.
 	Private bool
@@ -28,6 +39,25 @@ type APIInfo struct {
 
 	// Description describes the API
 	Description string
+
+	// Params describes the parameters of the API
+	// function. When set, requests with parameters
+	// that do not match are rejected before the
+	// function is executed.
+	Params *Schema
+
+	// Result describes the result of the API function
+	Result *Schema
+
+	// Errors describes the application errors the
+	// API function can return. They are included
+	// in the OpenRPC description of the method.
+	Errors []*Err
+
+	// Cost is the number of rate limit tokens a call
+	// consumes. Expensive methods should have a higher
+	// cost. Defaults to 1 when not set.
+	Cost float64
 }
 
 // APISet defines a collection of APIs
@@ -1,14 +1,21 @@
 package jsonrpc
 
 import (
+	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"net/http"
+	"sync"
 	"time"
 
 	jwt "github.com/dgrijalva/jwt-go"
-	"github.com/ncodes/authtoken"
+	"github.com/olebedev/emitter"
+
+	"github.com/ellcrys/elld/util/logger"
 
 	"github.com/gorilla/mux"
 	"github.com/gorilla/rpc/v2"
@@ -20,12 +27,24 @@ const (
 	serverErrCode     = -32001
 )
 
+// DefaultMaxBatchSize is the default maximum
+// number of requests in a batch
+const DefaultMaxBatchSize = 100
+
+// MaxRequestSize is the maximum size
+// of a request body or message in bytes
+const MaxRequestSize = 16 << 20
+
 // MethodInfo describe an RPC method info
 type MethodInfo struct {
-	Name        string `json:"name"`
-	Namespace   string `json:"-"`
-	Description string `json:"description"`
-	Private     bool   `json:"private"`
+	Name        string  `json:"name"`
+	Namespace   string  `json:"-"`
+	Description string  `json:"description"`
+	Private     bool    `json:"private"`
+	Stream      bool    `json:"stream"`
+	Params      *Schema `json:"params,omitempty"`
+	Result      *Schema `json:"result,omitempty"`
+	Cost        float64 `json:"cost"`
 }
 
 // OnRequestFunc is the type of function to use
@@ -58,6 +77,25 @@ func (r Request) IsNotification() bool {
 	}
 }
 
+// HasValidID checks whether the ID of the request is a
+// string, a number or null as required by JSON RPC
+// specification. IsNotification must only be called
+// on requests with a valid ID.
+func (r Request) HasValidID() bool {
+	switch r.ID.(type) {
+	case nil, string, float64:
+		return true
+	default:
+		return false
+	}
+}
+
+// errInvalidID returns the error response
+// of a request with an invalid ID
+func errInvalidID() *Response {
+	return Error(-32600, "Invalid Request: `id` must be a string, number or null", nil)
+}
+
 // Err represents JSON RPC error object
 type Err struct {
 	Code    int         `json:"code"`
@@ -108,18 +146,89 @@ type JSONRPC struct {
 	// handle has been configured
 	handlerConfigured bool
 
+	// maxBatchSize is the maximum number
+	// of requests in a batch
+	maxBatchSize int
+
+	// tlsConfig is the TLS configuration.
+	// TLS is disabled when nil.
+	tlsConfig *tls.Config
+
+	// roles are the roles of session tokens
+	// and the private methods they can call
+	roles Roles
+
+	// rateLimiter limits the cost of the requests
+	// of each client. Disabled when nil.
+	rateLimiter *rateLimiter
+
+	// methodCosts overrides the cost of methods
+	methodCosts map[string]float64
+
+	// restRoutes are the REST resources
+	restRoutes []*RESTRoute
+
+	// handlers are served next
+	// to the JSON RPC endpoint
+	handlers map[string]http.Handler
+
+	// log is used to log requests
+	log logger.Logger
+
+	// auditMtx guards audit and audited
+	auditMtx sync.Mutex
+
+	// audit is where calls to private
+	// and audited methods are recorded
+	audit io.Writer
+
+	// audited contains the names of public methods
+	// and namespaces whose calls are also recorded
+	audited map[string]struct{}
+
+	// subMtx guards evt, topics,
+	// subs and listening
+	subMtx sync.RWMutex
+
+	// evt is the event emitter whose
+	// events drive subscriptions
+	evt *emitter.Emitter
+
+	// topics are the events WebSocket
+	// clients can subscribe to
+	topics Topics
+
+	// subs are the active subscriptions
+	subs map[string]*subscription
+
+	// listening are the topics whose
+	// events are being listened to
+	listening map[string]struct{}
+
 	// done is used to wait for the server to
 	// shutdown
 	done chan bool
 }
 
 // MakeSessionToken creates a session token for RPC requests.
-// If ttl is zero, the session will never expire.
+// If ttl is zero, the session will never expire. The token
+// has no role and can call all private methods.
 func MakeSessionToken(username, secretKey string, ttl int64) string {
+	return MakeRoleSessionToken(username, "", secretKey, ttl)
+}
+
+// MakeRoleSessionToken creates a session token for RPC
+// requests of a user with the given role. The role
+// determines the private methods the token can call.
+// If ttl is zero, the session will never expire.
+func MakeRoleSessionToken(username, role, secretKey string, ttl int64) string {
 	dur := time.Duration(ttl) * time.Millisecond
 	claim := jwt.MapClaims{
 		"username": username,
 	}
+	if role != "" {
+		claim["role"] = role
+	}
 	if ttl > 0 {
 		claim["exp"] = time.Now().Add(dur).Unix()
 	}
@@ -131,6 +240,13 @@ func MakeSessionToken(username, secretKey string, ttl int64) string {
 // VerifySessionToken verifies that the given token was created
 // using the given secretKey
 func VerifySessionToken(tokenStr, secretKey string) error {
+	_, err := ParseSessionToken(tokenStr, secretKey)
+	return err
+}
+
+// ParseSessionToken verifies that the given token was created
+// using the given secretKey and returns its claims
+func ParseSessionToken(tokenStr, secretKey string) (jwt.MapClaims, error) {
 	token, err := jwt.Parse(tokenStr, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("Unexpected signing method: %v", token.Header["alg"])
@@ -138,12 +254,13 @@ func VerifySessionToken(tokenStr, secretKey string) error {
 		return []byte(secretKey), nil
 	})
 	if err != nil {
-		return err
+		return nil, err
 	}
-	if token.Valid {
-		return nil
+	if !token.Valid {
+		return nil, fmt.Errorf("invalid token")
 	}
-	return fmt.Errorf("invalid token")
+	claims, _ := token.Claims.(jwt.MapClaims)
+	return claims, nil
 }
 
 // Error creates an error response
@@ -169,22 +286,38 @@ func Success(result interface{}) *Response {
 // New creates a JSONRPC server
 func New(addr string, sessionKey string, disableAuth bool) *JSONRPC {
 	rpc := &JSONRPC{
-		addr:        addr,
-		apiSet:      APISet{},
-		sessionKey:  sessionKey,
-		disableAuth: disableAuth,
-		done:        make(chan bool),
+		addr:         addr,
+		apiSet:       APISet{},
+		sessionKey:   sessionKey,
+		disableAuth:  disableAuth,
+		maxBatchSize: DefaultMaxBatchSize,
+		topics:       Topics{},
+		subs:         make(map[string]*subscription),
+		listening:    make(map[string]struct{}),
+		done:         make(chan bool),
 	}
 	rpc.MergeAPISet(rpc.APIs())
 	return rpc
 }
 
+// SetRoles sets the roles of session tokens
+func (s *JSONRPC) SetRoles(roles Roles) {
+	s.roles = roles
+}
+
+// SetMaxBatchSize sets the maximum
+// number of requests in a batch
+func (s *JSONRPC) SetMaxBatchSize(n int) {
+	s.maxBatchSize = n
+}
+
 // APIs returns system APIs
 func (s *JSONRPC) APIs() APISet {
 	return APISet{
 		"methods": APIInfo{
 			Description: "List RPC methods",
 			Namespace:   "rpc",
+			Result:      Array("The methods", Object("A method", nil)),
 			Func: func(interface{}) *Response {
 				return Success(s.Methods())
 			},
@@ -201,6 +334,10 @@ func (s *JSONRPC) Methods() (methodsInfo []MethodInfo) {
 			Description: d.Description,
 			Namespace:   d.Namespace,
 			Private:     d.Private,
+			Stream:      d.Stream != nil,
+			Params:      d.Params,
+			Result:      d.Result,
+			Cost:        s.methodCost(name, &d),
 		})
 	}
 	return
@@ -217,9 +354,13 @@ func (s *JSONRPC) Serve() {
 	server.RegisterCodec(json2.NewCodec(), "application/json;charset=UTF-8")
 	r.Handle("/rpc", server)
 
-	srv := &http.Server{Addr: s.addr}
+	srv := &http.Server{Addr: s.addr, TLSConfig: s.tlsConfig}
 	s.registerHandler()
-	go srv.ListenAndServe()
+	if s.tlsConfig != nil {
+		go srv.ListenAndServeTLS("", "")
+	} else {
+		go srv.ListenAndServe()
+	}
 
 	<-s.done
 	srv.Shutdown(context.Background())
@@ -229,16 +370,41 @@ func (s *JSONRPC) registerHandler() {
 	if s.handlerConfigured {
 		return
 	}
-	http.HandleFunc("/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	http.HandleFunc("/", s.withOnRequest(s.serveRequest))
+	http.HandleFunc("/ws", s.withOnRequest(s.serveWS))
+	if len(s.restRoutes) > 0 {
+		http.HandleFunc(RESTPrefix+"/", s.withOnRequest(s.restHandler().ServeHTTP))
+	}
+	for pattern, handler := range s.handlers {
+		http.HandleFunc(pattern, s.withOnRequest(handler.ServeHTTP))
+	}
+	s.handlerConfigured = true
+}
+
+// Handle serves a handler at a path next to the
+// JSON RPC endpoint. Handlers must be added
+// before the server is started.
+func (s *JSONRPC) Handle(pattern string, handler http.Handler) {
+	if s.handlers == nil {
+		s.handlers = make(map[string]http.Handler)
+	}
+	s.handlers[pattern] = handler
+}
+
+// withOnRequest wraps a handler such that the
+// session token of the request is parsed and
+// the OnRequest callback is called before it
+func (s *JSONRPC) withOnRequest(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		r = s.withSession(r)
 		if s.OnRequest != nil {
 			if err := s.OnRequest(r); err != nil {
 				json.NewEncoder(w).Encode(Error(middlewareErrCode, err.Error(), nil))
 				return
 			}
 		}
-		json.NewEncoder(w).Encode(s.handle(w, r))
-	}))
-	s.handlerConfigured = true
+		handler(w, r)
+	}
 }
 
 // Stop stops the RPC server
@@ -260,10 +426,40 @@ func (s *JSONRPC) AddAPI(name string, api APIInfo) {
 	s.apiSet[api.Namespace+"_"+name] = api
 }
 
+// serveRequest reads the body of a request and processes
+// it as a single request or as a batch of requests
+func (s *JSONRPC) serveRequest(w http.ResponseWriter, r *http.Request) {
+
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, MaxRequestSize))
+	if err != nil {
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+		json.NewEncoder(w).Encode(Error(-32600, "Invalid Request: request too large", nil))
+		return
+	}
+
+	if isBatch(body) {
+		if resp := s.handleBatch(w, r, body); resp != nil {
+			json.NewEncoder(w).Encode(resp)
+		}
+		return
+	}
+
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	if resp := s.handle(w, r); resp != nil {
+		json.NewEncoder(w).Encode(resp)
+	}
+}
+
+// isBatch checks whether a request body is a JSON array
+func isBatch(body []byte) bool {
+	body = bytes.TrimLeft(body, " \t\r\n")
+	return len(body) > 0 && body[0] == '['
+}
+
 // handle processes incoming requests. It validates
 // the request according to JSON RPC specification,
 // find the method and passes it off.
-func (s *JSONRPC) handle(w http.ResponseWriter, r *http.Request) *Response {
+func (s *JSONRPC) handle(w http.ResponseWriter, r *http.Request) (resp *Response) {
 	// attempt to decode the body
 	var newReq Request
 	if err := json.NewDecoder(r.Body).Decode(&newReq); err != nil {
@@ -271,61 +467,221 @@ func (s *JSONRPC) handle(w http.ResponseWriter, r *http.Request) *Response {
 		return Error(-32700, "Parse error", nil)
 	}
 
-	// JSON RPC version must be 2.0
-	if newReq.JSONRPCVersion != "2.0" {
+	start := time.Now()
+	defer func() { s.logRequest(r, newReq, resp, start) }()
+
+	f, errResp, status := s.check(r, newReq)
+	if errResp != nil {
+		setRetryAfter(w, errResp)
+		w.WriteHeader(status)
+		return errResp
+	}
+
+	// Streaming methods write their results
+	// directly to the response writer
+	if f.Stream != nil {
+		s.stream(w, r, newReq, f.Stream)
+		return nil
+	}
+
+	resp, status = s.call(f, newReq)
+	w.WriteHeader(status)
+	return resp
+}
+
+// handleBatch processes a batch of requests. It returns
+// a response for every request that is not a notification.
+// Requests are validated and authorized individually so
+// an invalid entry does not affect the other entries.
+func (s *JSONRPC) handleBatch(w http.ResponseWriter, r *http.Request, body []byte) interface{} {
+
+	var rawReqs []json.RawMessage
+	if err := json.Unmarshal(body, &rawReqs); err != nil {
 		w.WriteHeader(http.StatusBadRequest)
-		return Error(-32600, "`jsonrpc` value is required", nil)
+		return Error(-32700, "Parse error", nil)
+	}
+
+	if len(rawReqs) == 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		return Error(-32600, "Invalid Request: empty batch", nil)
+	}
+
+	if len(rawReqs) > s.maxBatchSize {
+		w.WriteHeader(http.StatusBadRequest)
+		return Error(-32600, fmt.Sprintf("Invalid Request: batch size exceeds %d",
+			s.maxBatchSize), nil)
+	}
+
+	var resps []*Response
+	for _, rawReq := range rawReqs {
+
+		var req Request
+		if err := json.Unmarshal(rawReq, &req); err != nil {
+			resps = append(resps, Error(-32600, "Invalid Request", nil))
+			continue
+		}
+
+		// The ID of an entry with an invalid ID cannot
+		// be echoed; its error response has a null ID
+		if !req.HasValidID() {
+			resps = append(resps, errInvalidID())
+			continue
+		}
+
+		start := time.Now()
+		f, resp, _ := s.check(r, req)
+		setRetryAfter(w, resp)
+		if resp == nil && f.Stream != nil {
+			resp = Error(-32600, "Invalid Request: streaming methods cannot be batched", nil)
+		}
+		if resp == nil {
+			resp, _ = s.call(f, req)
+		}
+		s.logRequest(r, req, resp, start)
+
+		// Notifications are not responded to, even
+		// when they fail. Entries that are not valid
+		// requests are responded to.
+		if req.IsNotification() && req.JSONRPCVersion == "2.0" {
+			continue
+		}
+
+		resp.ID = req.ID
+		resps = append(resps, resp)
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	// A batch of notifications has no response
+	if len(resps) == 0 {
+		return nil
+	}
+
+	return resps
+}
+
+// check validates a request, finds its method, charges
+// its cost to the client and authorizes access to
+// private methods. It returns the method or an error
+// response and the HTTP status code of the error.
+func (s *JSONRPC) check(r *http.Request, req Request) (*APIInfo, *Response, int) {
+
+	// JSON RPC version must be 2.0
+	if req.JSONRPCVersion != "2.0" {
+		return nil, Error(-32600, "`jsonrpc` value is required", nil), http.StatusBadRequest
+	}
+
+	// ID must be a string, number or null
+	if !req.HasValidID() {
+		return nil, errInvalidID(), http.StatusBadRequest
 	}
 
 	// Method must be known
-	f := s.apiSet.Get(newReq.Method)
+	f := s.apiSet.Get(req.Method)
 	if f == nil {
-		w.WriteHeader(http.StatusNotFound)
-		return Error(-32601, "Method not found", nil)
+		return nil, Error(-32601, "Method not found", nil), http.StatusNotFound
+	}
+
+	// The client must not exceed its rate limit
+	if errResp := s.limit(r, req.Method, f); errResp != nil {
+		return nil, errResp, http.StatusTooManyRequests
 	}
 
 	// If the method request is a private
 	// method, we must authenticate the provided bearer
 	// token
 	if !s.disableAuth && f.Private {
-		authToken, err := authtoken.FromRequest(r)
-		if err != nil {
-			w.WriteHeader(http.StatusUnauthorized)
-			return Error(-32600, fmt.Sprintf("Invalid Request: %s", err.Error()), nil)
+		sess := s.session(r)
+		if sess.headerErr != nil {
+			return nil, Error(-32600, fmt.Sprintf("Invalid Request: %s", sess.headerErr.Error()), nil),
+				http.StatusUnauthorized
 		}
-		if err = VerifySessionToken(authToken, s.sessionKey); err != nil {
-			w.WriteHeader(http.StatusUnauthorized)
-			return Error(-32600, fmt.Sprintf("Authorization Error: session token is not valid"), nil)
+		if sess.tokenErr != nil {
+			return nil, Error(-32600, fmt.Sprintf("Authorization Error: session token is not valid"), nil),
+				http.StatusUnauthorized
+		}
+
+		// Tokens with a role can only call the
+		// private methods permitted by the role
+		if role, ok := sess.claims["role"].(string); ok {
+			if r, known := s.roles[role]; !known || !r.Permits(req.Method, f.Namespace) {
+				return nil, Error(-32600, fmt.Sprintf("Authorization Error: role is not permitted "+
+					"to call this method"), nil), http.StatusForbidden
+			}
+		}
+	}
+
+	// Parameters must match the schema of the method
+	if f.Params != nil {
+		if err := f.Params.Validate(req.Params); err != nil {
+			return nil, Error(-32602, fmt.Sprintf("Invalid params: %s", err), nil),
+				http.StatusBadRequest
 		}
 	}
 
-	var resp *Response
+	return f, nil, 0
+}
+
+// call executes the method of a request. It returns the
+// response and the HTTP status code of the response.
+func (s *JSONRPC) call(f *APIInfo, req Request) (resp *Response, status int) {
 
 	defer func() {
-		if rcv, ok := recover().(error); ok {
-			w.WriteHeader(http.StatusInternalServerError)
-			json.NewEncoder(w).Encode(Error(serverErrCode, rcv.Error(), nil))
+		if rcv := recover(); rcv != nil {
+			resp, status = Error(serverErrCode, fmt.Sprint(rcv), nil), http.StatusInternalServerError
 		}
 	}()
 
-	resp = f.Func(newReq.Params)
+	resp = f.Func(req.Params)
 	if resp == nil {
-		w.WriteHeader(http.StatusOK)
-		return Success(nil)
+		return Success(nil), http.StatusOK
 	}
 
-	if !resp.IsError() {
-		resp.ID = newReq.ID
+	if resp.IsError() {
+		return resp, http.StatusBadRequest
+	}
 
-		// a notification. Send no response.
-		if newReq.IsNotification() {
-			resp.Result = nil
-		}
+	resp.ID = req.ID
 
-		w.WriteHeader(http.StatusOK)
-	} else {
-		w.WriteHeader(http.StatusBadRequest)
+	// a notification. Send no response.
+	if req.IsNotification() {
+		resp.Result = nil
 	}
 
-	return resp
+	return resp, http.StatusOK
+}
+
+// stream executes a streaming method. The results are
+// written as newline-delimited JSON RPC responses and
+// flushed as they are produced. Streaming ends when
+// the method returns or the client disconnects.
+func (s *JSONRPC) stream(w http.ResponseWriter, r *http.Request, req Request, f StreamFunc) {
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(Error(serverErrCode, "streaming not supported", nil))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	enc := json.NewEncoder(w)
+	send := func(result interface{}) error {
+		resp := Success(result)
+		resp.ID = req.ID
+		if err := enc.Encode(resp); err != nil {
+			return err
+		}
+		flusher.Flush()
+		return nil
+	}
+
+	if err := f(req.Params, send, r.Context().Done()); err != nil {
+		errResp := Error(serverErrCode, err.Error(), nil)
+		errResp.ID = req.ID
+		enc.Encode(errResp)
+	}
 }
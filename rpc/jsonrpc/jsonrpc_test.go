@@ -214,6 +214,50 @@ var _ = Describe("RPC", func() {
 				})
 			})
 		})
+
+		Context("Call streaming method", func() {
+			It("should write each result as a separate JSON line", func() {
+				rpc.apiSet["count"] = APIInfo{
+					Namespace: "math",
+					Stream: func(params interface{}, send func(interface{}) error, done <-chan struct{}) error {
+						for i := 1; i <= 2; i++ {
+							if err := send(i); err != nil {
+								return err
+							}
+						}
+						return nil
+					},
+				}
+
+				data, _ := json.Marshal(Request{
+					JSONRPCVersion: "2.0",
+					Method:         "count",
+					ID:             1,
+				})
+
+				req, _ := http.NewRequest("POST", "/rpc", bytes.NewReader(data))
+				rr := httptest.NewRecorder()
+
+				handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					resp := rpc.handle(w, r)
+					Expect(resp).To(BeNil())
+				})
+
+				handler.ServeHTTP(rr, req)
+				Expect(rr.Code).To(Equal(200))
+				Expect(rr.Header().Get("Content-Type")).To(Equal("application/x-ndjson"))
+
+				dec := json.NewDecoder(rr.Body)
+				var results []float64
+				for dec.More() {
+					var resp Response
+					Expect(dec.Decode(&resp)).To(BeNil())
+					Expect(resp.ID).To(Equal(float64(1)))
+					results = append(results, resp.Result.(float64))
+				}
+				Expect(results).To(Equal([]float64{1, 2}))
+			})
+		})
 	})
 
 	Context("Call private method", func() {
@@ -353,6 +397,76 @@ var _ = Describe("RPC", func() {
 			})
 		})
 
+		It("should return 'Invalid params' when params do not match the schema", func() {
+			rpc.apiSet["add"] = APIInfo{
+				Params: Object("", map[string]*Schema{"x": Number(""), "y": Number("")}),
+				Func: func(params interface{}) *Response {
+					m := params.(map[string]interface{})
+					return Success(m["x"].(float64) + m["y"].(float64))
+				},
+			}
+
+			data, _ := json.Marshal(Request{
+				JSONRPCVersion: "2.0",
+				Method:         "add",
+				ID:             1,
+				Params:         map[string]interface{}{"x": 1},
+			})
+			req, _ := http.NewRequest("POST", "/rpc", bytes.NewReader(data))
+			rr := httptest.NewRecorder()
+
+			resp := rpc.handle(rr, req)
+			Expect(resp.Err).ToNot(BeNil())
+			Expect(resp.Err.Code).To(Equal(-32602))
+			Expect(resp.Err.Message).To(Equal("Invalid params: params.y is required"))
+			Expect(rr.Code).To(Equal(400))
+		})
+
+		When("bearer token has a role", func() {
+
+			BeforeEach(func() {
+				rpc.SetRoles(Roles{
+					"reader": {Methods: []string{"test_info"}},
+					"tester": {Namespaces: []string{"test"}},
+				})
+				rpc.apiSet["test_echo"] = APIInfo{
+					Private:   true,
+					Namespace: "test",
+					Func: func(params interface{}) *Response {
+						return Success(params)
+					},
+				}
+			})
+
+			call := func(role string) (*Response, int) {
+				data, _ := json.Marshal(Request{JSONRPCVersion: "2.0", Method: "test_echo", ID: 1})
+				req, _ := http.NewRequest("POST", "/rpc", bytes.NewReader(data))
+				req.Header.Set("Authorization", "Bearer "+MakeRoleSessionToken("user1", role, rpc.sessionKey, 600))
+				rr := httptest.NewRecorder()
+				resp := rpc.handle(rr, req)
+				return resp, rr.Code
+			}
+
+			It("should be successful when the role permits the method's namespace", func() {
+				resp, code := call("tester")
+				Expect(resp.Err).To(BeNil())
+				Expect(code).To(Equal(200))
+			})
+
+			It("should return error when the role does not permit the method", func() {
+				resp, code := call("reader")
+				Expect(resp.Err).ToNot(BeNil())
+				Expect(resp.Err.Message).To(Equal("Authorization Error: role is not permitted to call this method"))
+				Expect(code).To(Equal(403))
+			})
+
+			It("should return error when the role is unknown", func() {
+				resp, code := call("unknown")
+				Expect(resp.Err).ToNot(BeNil())
+				Expect(code).To(Equal(403))
+			})
+		})
+
 		When("authorization format is valid and bearer token is not valid", func() {
 			It("should be successful when disableAuth is true", func() {
 				rpc.disableAuth = true
@@ -390,6 +504,185 @@ var _ = Describe("RPC", func() {
 		})
 	})
 
+	Describe(".serveRequest", func() {
+		It("should return error when the body exceeds the maximum request size", func() {
+			data := bytes.Repeat([]byte(" "), MaxRequestSize+1)
+			req, _ := http.NewRequest("POST", "/rpc", bytes.NewReader(data))
+			rr := httptest.NewRecorder()
+			rpc.serveRequest(rr, req)
+			Expect(rr.Code).To(Equal(http.StatusRequestEntityTooLarge))
+		})
+	})
+
+	Describe(".handleBatch", func() {
+
+		BeforeEach(func() {
+			rpc.apiSet["echo"] = APIInfo{
+				Namespace: "test",
+				Func: func(params interface{}) *Response {
+					return Success(params)
+				},
+			}
+			rpc.apiSet["secret"] = APIInfo{
+				Private:   true,
+				Namespace: "test",
+				Func: func(params interface{}) *Response {
+					return Success("secret")
+				},
+			}
+		})
+
+		It("should return a response for every request that is not a notification", func() {
+			data := []byte(`[
+				{"jsonrpc": "2.0", "method": "echo", "params": "a", "id": 1},
+				{"jsonrpc": "2.0", "method": "echo", "params": "b"},
+				{"jsonrpc": "2.0", "method": "unknown", "id": 2},
+				{"jsonrpc": "2.0", "method": "secret", "id": 3},
+				1
+			]`)
+			req, _ := http.NewRequest("POST", "/rpc", bytes.NewReader(data))
+			rr := httptest.NewRecorder()
+
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				resp := rpc.handleBatch(w, r, data)
+				resps := resp.([]*Response)
+				Expect(resps).To(HaveLen(4))
+				Expect(resps[0].Result).To(Equal("a"))
+				Expect(resps[0].ID).To(Equal(float64(1)))
+				Expect(resps[1].Err.Code).To(Equal(-32601))
+				Expect(resps[1].ID).To(Equal(float64(2)))
+				Expect(resps[2].Err.Message).To(ContainSubstring("Invalid Request"))
+				Expect(resps[2].ID).To(Equal(float64(3)))
+				Expect(resps[3].Err.Code).To(Equal(-32600))
+				Expect(rr.Code).To(Equal(200))
+			})
+
+			handler.ServeHTTP(rr, req)
+		})
+
+		It("should authorize private methods of every request", func() {
+			data := []byte(`[{"jsonrpc": "2.0", "method": "secret", "id": 1}]`)
+			req, _ := http.NewRequest("POST", "/rpc", bytes.NewReader(data))
+			req.Header.Set("Authorization", "Bearer "+MakeSessionToken("user1", rpc.sessionKey, 600))
+			rr := httptest.NewRecorder()
+
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				resps := rpc.handleBatch(w, r, data).([]*Response)
+				Expect(resps).To(HaveLen(1))
+				Expect(resps[0].Err).To(BeNil())
+				Expect(resps[0].Result).To(Equal("secret"))
+			})
+
+			handler.ServeHTTP(rr, req)
+		})
+
+		It("should not respond to notifications that fail", func() {
+			data := []byte(`[
+				{"jsonrpc": "2.0", "method": "unknown"},
+				{"jsonrpc": "2.0", "method": "secret"},
+				{"jsonrpc": "2.0", "method": "echo", "params": "a", "id": 1}
+			]`)
+			req, _ := http.NewRequest("POST", "/rpc", bytes.NewReader(data))
+			rr := httptest.NewRecorder()
+
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				resps := rpc.handleBatch(w, r, data).([]*Response)
+				Expect(resps).To(HaveLen(1))
+				Expect(resps[0].ID).To(Equal(float64(1)))
+			})
+
+			handler.ServeHTTP(rr, req)
+		})
+
+		It("should return an error only for entries with an invalid id", func() {
+			data := []byte(`[
+				{"jsonrpc": "2.0", "method": "echo", "params": "a", "id": true},
+				{"jsonrpc": "2.0", "method": "echo", "params": "b", "id": {}},
+				{"jsonrpc": "2.0", "method": "echo", "params": "c", "id": 1}
+			]`)
+			req, _ := http.NewRequest("POST", "/rpc", bytes.NewReader(data))
+			rr := httptest.NewRecorder()
+
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				resps := rpc.handleBatch(w, r, data).([]*Response)
+				Expect(resps).To(HaveLen(3))
+				Expect(resps[0].Err.Code).To(Equal(-32600))
+				Expect(resps[0].ID).To(BeNil())
+				Expect(resps[1].Err.Code).To(Equal(-32600))
+				Expect(resps[1].ID).To(BeNil())
+				Expect(resps[2].Err).To(BeNil())
+				Expect(resps[2].Result).To(Equal("c"))
+				Expect(resps[2].ID).To(Equal(float64(1)))
+				Expect(rr.Code).To(Equal(200))
+			})
+
+			handler.ServeHTTP(rr, req)
+		})
+
+		It("should return a server error when a method panics with a value that is not an error", func() {
+			rpc.apiSet["panic"] = APIInfo{
+				Namespace: "test",
+				Func: func(params interface{}) *Response {
+					panic("boom")
+				},
+			}
+			data := []byte(`[{"jsonrpc": "2.0", "method": "panic", "id": 1}]`)
+			req, _ := http.NewRequest("POST", "/rpc", bytes.NewReader(data))
+			rr := httptest.NewRecorder()
+
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				resps := rpc.handleBatch(w, r, data).([]*Response)
+				Expect(resps).To(HaveLen(1))
+				Expect(resps[0].Err.Code).To(Equal(-32001))
+				Expect(resps[0].Err.Message).To(Equal("boom"))
+				Expect(resps[0].ID).To(Equal(float64(1)))
+			})
+
+			handler.ServeHTTP(rr, req)
+		})
+
+		It("should return no response when all requests are notifications", func() {
+			data := []byte(`[{"jsonrpc": "2.0", "method": "echo", "params": "a"}]`)
+			req, _ := http.NewRequest("POST", "/rpc", bytes.NewReader(data))
+			rr := httptest.NewRecorder()
+
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				Expect(rpc.handleBatch(w, r, data)).To(BeNil())
+			})
+
+			handler.ServeHTTP(rr, req)
+		})
+
+		It("should return error when batch is empty", func() {
+			data := []byte(`[]`)
+			req, _ := http.NewRequest("POST", "/rpc", bytes.NewReader(data))
+			rr := httptest.NewRecorder()
+
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				resp := rpc.handleBatch(w, r, data).(*Response)
+				Expect(resp.Err.Code).To(Equal(-32600))
+				Expect(rr.Code).To(Equal(400))
+			})
+
+			handler.ServeHTTP(rr, req)
+		})
+
+		It("should return error when batch size exceeds the maximum", func() {
+			rpc.SetMaxBatchSize(1)
+			data := []byte(`[{"jsonrpc": "2.0", "method": "echo", "id": 1}, {"jsonrpc": "2.0", "method": "echo", "id": 2}]`)
+			req, _ := http.NewRequest("POST", "/rpc", bytes.NewReader(data))
+			rr := httptest.NewRecorder()
+
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				resp := rpc.handleBatch(w, r, data).(*Response)
+				Expect(resp.Err.Message).To(Equal("Invalid Request: batch size exceeds 1"))
+				Expect(rr.Code).To(Equal(400))
+			})
+
+			handler.ServeHTTP(rr, req)
+		})
+	})
+
 	Describe(".AddAPI", func() {
 		Context("with no namespace provided", func() {
 			It("should add API", func() {
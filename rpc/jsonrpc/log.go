@@ -0,0 +1,126 @@
+package jsonrpc
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/ellcrys/elld/util/logger"
+)
+
+// AuditEntry is a record of a call to a private or
+// audited method. It does not include the parameters
+// of the call as they may contain secrets.
+type AuditEntry struct {
+	Time       time.Time `json:"time"`
+	Method     string    `json:"method"`
+	Caller     string    `json:"caller,omitempty"`
+	Role       string    `json:"role,omitempty"`
+	RemoteAddr string    `json:"remoteAddr"`
+	Latency    int64     `json:"latencyMs"`
+	Code       int       `json:"code"`
+}
+
+// SetLogger sets the logger of the requests
+func (s *JSONRPC) SetLogger(log logger.Logger) {
+	s.log = log
+}
+
+// SetAuditLog sets the writer calls to private and
+// audited methods are recorded to. Each call is written
+// as a JSON encoded AuditEntry on its own line.
+func (s *JSONRPC) SetAuditLog(w io.Writer) {
+	s.auditMtx.Lock()
+	defer s.auditMtx.Unlock()
+	s.audit = w
+}
+
+// SetAuditedMethods sets the methods whose calls are
+// recorded to the audit log even when they are public.
+// A name is either the full name of a method or the
+// namespace of the methods followed by "_*".
+func (s *JSONRPC) SetAuditedMethods(names ...string) {
+	s.auditMtx.Lock()
+	defer s.auditMtx.Unlock()
+	s.audited = make(map[string]struct{}, len(names))
+	for _, name := range names {
+		s.audited[name] = struct{}{}
+	}
+}
+
+// isAudited checks whether calls to a method
+// must be recorded to the audit log
+func (s *JSONRPC) isAudited(method string, f *APIInfo) bool {
+	if f == nil {
+		return false
+	}
+	if f.Private {
+		return true
+	}
+
+	s.auditMtx.Lock()
+	defer s.auditMtx.Unlock()
+	if _, ok := s.audited[method]; ok {
+		return true
+	}
+	_, ok := s.audited[f.Namespace+"_*"]
+	return ok
+}
+
+// logRequest logs a request and its result
+func (s *JSONRPC) logRequest(r *http.Request, req Request, resp *Response, start time.Time) {
+	code := 0
+	if resp != nil && resp.Err != nil {
+		code = resp.Err.Code
+	}
+	s.LogRequest(r, req.Method, code, start)
+}
+
+// LogRequest logs a request of a method and its result
+// code. Calls to private and audited methods are also
+// written to the audit log. A request that succeeded
+// has a zero result code. Handlers added with Handle
+// can use it to log their requests like JSON RPC requests.
+func (s *JSONRPC) LogRequest(r *http.Request, method string, code int, start time.Time) {
+
+	audited := s.isAudited(method, s.apiSet.Get(method))
+	if s.log == nil && !audited {
+		return
+	}
+
+	caller, role := s.identify(r)
+	latency := time.Since(start)
+
+	if s.log != nil {
+		s.log.Debug("RPC request", "Method", method, "Caller", caller,
+			"RemoteAddr", r.RemoteAddr, "Latency", latency.String(), "Code", code)
+	}
+
+	if audited {
+		s.writeAudit(&AuditEntry{
+			Time:       start.UTC(),
+			Method:     method,
+			Caller:     caller,
+			Role:       role,
+			RemoteAddr: r.RemoteAddr,
+			Latency:    int64(latency / time.Millisecond),
+			Code:       code,
+		})
+	}
+}
+
+// writeAudit appends an entry to the audit log
+func (s *JSONRPC) writeAudit(entry *AuditEntry) {
+	s.auditMtx.Lock()
+	defer s.auditMtx.Unlock()
+
+	if s.audit == nil {
+		return
+	}
+
+	bs, _ := json.Marshal(entry)
+	if _, err := s.audit.Write(append(bs, '\n')); err != nil && s.log != nil {
+		s.log.Error("Failed to write RPC audit log", "Err", err.Error())
+	}
+}
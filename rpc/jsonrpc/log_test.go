@@ -0,0 +1,147 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Log", func() {
+
+	var rpc *JSONRPC
+	var audit *bytes.Buffer
+
+	BeforeEach(func() {
+		rpc = New("", "abc", false)
+		audit = bytes.NewBuffer(nil)
+		rpc.SetAuditLog(audit)
+		rpc.MergeAPISet(APISet{
+			"echo": {
+				Namespace: "test",
+				Func: func(params interface{}) *Response {
+					return Success(params)
+				},
+			},
+			"secret": {
+				Namespace: "test",
+				Private:   true,
+				Func: func(params interface{}) *Response {
+					return Success(params)
+				},
+			},
+			"listAccounts": {
+				Namespace: "personal",
+				Func: func(params interface{}) *Response {
+					return Success(params)
+				},
+			},
+			"send": {
+				Namespace: "ell",
+				Func: func(params interface{}) *Response {
+					return Success(params)
+				},
+			},
+		})
+		rpc.SetAuditedMethods("personal_*", "ell_send")
+	})
+
+	call := func(method string, token string) {
+		data, _ := json.Marshal(Request{JSONRPCVersion: "2.0", Method: method,
+			Params: "password", ID: 1})
+		req, _ := http.NewRequest("POST", "/rpc", bytes.NewReader(data))
+		req.RemoteAddr = "127.0.0.1:5000"
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rpc.handle(httptest.NewRecorder(), req)
+	}
+
+	readAudit := func() (entries []*AuditEntry) {
+		for _, line := range strings.Split(strings.TrimSpace(audit.String()), "\n") {
+			if line == "" {
+				continue
+			}
+			var entry AuditEntry
+			Expect(json.Unmarshal([]byte(line), &entry)).To(BeNil())
+			entries = append(entries, &entry)
+		}
+		return
+	}
+
+	It("should not audit calls to public methods", func() {
+		call("test_echo", "")
+		Expect(readAudit()).To(BeEmpty())
+	})
+
+	It("should audit calls to private methods with the caller identity", func() {
+		call("test_secret", MakeRoleSessionToken("user", "admin", "abc", 0))
+		entries := readAudit()
+		Expect(entries).To(HaveLen(1))
+		Expect(entries[0].Method).To(Equal("test_secret"))
+		Expect(entries[0].Caller).To(Equal("user"))
+		Expect(entries[0].Role).To(Equal("admin"))
+		Expect(entries[0].RemoteAddr).To(Equal("127.0.0.1:5000"))
+		Expect(entries[0].Code).To(Equal(0))
+	})
+
+	It("should audit calls to public methods of an audited namespace", func() {
+		call("personal_listAccounts", "")
+		entries := readAudit()
+		Expect(entries).To(HaveLen(1))
+		Expect(entries[0].Method).To(Equal("personal_listAccounts"))
+		Expect(entries[0].Caller).To(BeEmpty())
+		Expect(entries[0].Code).To(Equal(0))
+	})
+
+	It("should audit calls to audited public methods", func() {
+		call("ell_send", "")
+		entries := readAudit()
+		Expect(entries).To(HaveLen(1))
+		Expect(entries[0].Method).To(Equal("ell_send"))
+	})
+
+	It("should audit rejected calls to private methods", func() {
+		call("test_secret", "")
+		entries := readAudit()
+		Expect(entries).To(HaveLen(1))
+		Expect(entries[0].Caller).To(BeEmpty())
+		Expect(entries[0].Code).To(Equal(-32600))
+	})
+
+	It("should not record the parameters", func() {
+		call("test_secret", MakeSessionToken("user", "abc", 0))
+		Expect(audit.String()).ToNot(ContainSubstring("password"))
+	})
+
+	Describe(".withSession", func() {
+
+		newRequest := func(token string) *http.Request {
+			req, _ := http.NewRequest("POST", "/rpc", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			return req
+		}
+
+		It("should parse the session token once", func() {
+			req := rpc.withSession(newRequest(MakeRoleSessionToken("user", "admin", "abc", 0)))
+			rpc.sessionKey = "xyz"
+			username, role := rpc.identify(req)
+			Expect(username).To(Equal("user"))
+			Expect(role).To(Equal("admin"))
+		})
+
+		It("should reject an attached session whose token has expired", func() {
+			req := rpc.withSession(newRequest(MakeSessionToken("user", "abc", 1000)))
+			Expect(rpc.session(req).claims).ToNot(BeNil())
+			rpc.session(req).claims["exp"] = float64(time.Now().Add(-time.Minute).Unix())
+			Expect(rpc.session(req).tokenErr).ToNot(BeNil())
+			username, _ := rpc.identify(req)
+			Expect(username).To(BeEmpty())
+		})
+	})
+})
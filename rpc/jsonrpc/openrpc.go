@@ -0,0 +1,159 @@
+package jsonrpc
+
+import (
+	"sort"
+)
+
+// OpenRPCVersion is the version of the OpenRPC
+// specification followed by generated documents
+const OpenRPCVersion = "1.2.6"
+
+// OpenRPCDocument is an OpenRPC document
+// that describes the methods of the server
+type OpenRPCDocument struct {
+	OpenRPC string           `json:"openrpc"`
+	Info    OpenRPCInfo      `json:"info"`
+	Methods []*OpenRPCMethod `json:"methods"`
+}
+
+// OpenRPCInfo describes the API
+type OpenRPCInfo struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// OpenRPCMethod describes a method. Private,
+// streaming and WebSocket-only methods are
+// indicated using specification extensions. Methods whose params
+// member is a single value other than an object
+// are indicated using the x-params-by-value
+// extension; their only parameter describes
+// the value of the params member.
+type OpenRPCMethod struct {
+	Name           string                      `json:"name"`
+	Summary        string                      `json:"summary,omitempty"`
+	Tags           []*OpenRPCTag               `json:"tags,omitempty"`
+	ParamStructure string                      `json:"paramStructure,omitempty"`
+	Params         []*OpenRPCContentDescriptor `json:"params"`
+	Result         *OpenRPCContentDescriptor   `json:"result"`
+	Errors         []*Err                      `json:"errors,omitempty"`
+	Private        bool                        `json:"x-private,omitempty"`
+	Stream         bool                        `json:"x-stream,omitempty"`
+	ParamsByValue  bool                        `json:"x-params-by-value,omitempty"`
+	WebSocket      bool                        `json:"x-websocket,omitempty"`
+}
+
+// OpenRPCTag groups methods. Methods are
+// tagged with their namespace.
+type OpenRPCTag struct {
+	Name string `json:"name"`
+}
+
+// OpenRPCContentDescriptor describes
+// the parameters or result of a method
+type OpenRPCContentDescriptor struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	Required    bool                   `json:"required,omitempty"`
+	Schema      map[string]interface{} `json:"schema"`
+}
+
+// OpenRPC creates an OpenRPC document describing all
+// methods in the API set. The subscription methods of
+// WebSocket clients are included when subscriptions
+// are supported.
+func (s *JSONRPC) OpenRPC(info OpenRPCInfo) *OpenRPCDocument {
+
+	doc := &OpenRPCDocument{
+		OpenRPC: OpenRPCVersion,
+		Info:    info,
+		Methods: []*OpenRPCMethod{},
+	}
+
+	for name, api := range s.apiSet {
+		doc.Methods = append(doc.Methods, openRPCMethod(name, api))
+	}
+
+	s.subMtx.RLock()
+	hasSubs := s.evt != nil
+	s.subMtx.RUnlock()
+	if hasSubs {
+		for name, api := range wsAPIs() {
+			m := openRPCMethod(name, api)
+			m.WebSocket = true
+			doc.Methods = append(doc.Methods, m)
+		}
+	}
+
+	sort.Slice(doc.Methods, func(i, j int) bool {
+		return doc.Methods[i].Name < doc.Methods[j].Name
+	})
+
+	return doc
+}
+
+// openRPCMethod creates the OpenRPC description of a method.
+// Object parameters are passed by name, so each property is
+// described by a content descriptor. Other parameters are
+// passed as a single value described by one content descriptor.
+func openRPCMethod(name string, api APIInfo) *OpenRPCMethod {
+
+	m := &OpenRPCMethod{
+		Name:    name,
+		Summary: api.Description,
+		Tags:    []*OpenRPCTag{{Name: api.Namespace}},
+		Params:  []*OpenRPCContentDescriptor{},
+		Result:  &OpenRPCContentDescriptor{Name: "result", Schema: map[string]interface{}{}},
+		Private: api.Private,
+		Stream:  api.Stream != nil,
+	}
+
+	if api.Params != nil {
+		if api.Params.Type == TypeObject && len(api.Params.Properties) > 0 {
+			m.ParamStructure = "by-name"
+			for _, prop := range sortedProperties(api.Params) {
+				schema := api.Params.Properties[prop]
+				m.Params = append(m.Params, &OpenRPCContentDescriptor{
+					Name:        prop,
+					Description: schema.Description,
+					Required:    !api.Params.Optional && !schema.Optional,
+					Schema:      schema.JSONSchema(),
+				})
+			}
+		} else {
+			m.ParamsByValue = true
+			m.Params = append(m.Params, &OpenRPCContentDescriptor{
+				Name:        "params",
+				Description: api.Params.Description,
+				Required:    !api.Params.Optional,
+				Schema:      api.Params.JSONSchema(),
+			})
+		}
+		m.Errors = append(m.Errors, &Err{Code: -32602, Message: "Invalid params"})
+	}
+
+	if api.Result != nil {
+		m.Result.Description = api.Result.Description
+		m.Result.Schema = api.Result.JSONSchema()
+	}
+
+	if api.Private {
+		m.Errors = append(m.Errors, &Err{Code: -32600, Message: "Authorization Error"})
+	}
+
+	m.Errors = append(m.Errors, api.Errors...)
+
+	return m
+}
+
+// sortedProperties returns the names of
+// the properties of s in sorted order
+func sortedProperties(s *Schema) []string {
+	var names []string
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,113 @@
+package jsonrpc
+
+import (
+	"github.com/olebedev/emitter"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("OpenRPC", func() {
+
+	var rpc *JSONRPC
+
+	BeforeEach(func() {
+		rpc = New("", "abc", false)
+		rpc.MergeAPISet(APISet{
+			"add": {
+				Namespace:   "math",
+				Description: "Add two numbers",
+				Private:     true,
+				Params: Object("The numbers", map[string]*Schema{
+					"x": Number("The first number"),
+					"y": Number("The second number").Opt(),
+				}),
+				Result: Number("The sum"),
+				Errors: []*Err{{Code: 60000, Message: "Unexpected argument type"}},
+				Func: func(params interface{}) *Response {
+					return Success(nil)
+				},
+			},
+			"neg": {
+				Namespace:   "math",
+				Description: "Negate a number",
+				Params:      Number("The number"),
+				Func: func(params interface{}) *Response {
+					return Success(nil)
+				},
+			},
+		})
+	})
+
+	Describe(".OpenRPC", func() {
+
+		var doc *OpenRPCDocument
+
+		BeforeEach(func() {
+			doc = rpc.OpenRPC(OpenRPCInfo{Title: "API", Version: "1.0"})
+		})
+
+		It("should describe all methods sorted by name", func() {
+			Expect(doc.OpenRPC).To(Equal(OpenRPCVersion))
+			Expect(doc.Info.Version).To(Equal("1.0"))
+			Expect(doc.Methods).To(HaveLen(3))
+			Expect(doc.Methods[0].Name).To(Equal("math_add"))
+			Expect(doc.Methods[1].Name).To(Equal("math_neg"))
+			Expect(doc.Methods[2].Name).To(Equal("rpc_methods"))
+		})
+
+		It("should describe the parameters, result and errors of a method", func() {
+			m := doc.Methods[0]
+			Expect(m.Summary).To(Equal("Add two numbers"))
+			Expect(m.Tags[0].Name).To(Equal("math"))
+			Expect(m.Private).To(BeTrue())
+			Expect(m.ParamStructure).To(Equal("by-name"))
+			Expect(m.ParamsByValue).To(BeFalse())
+			Expect(m.Params).To(HaveLen(2))
+			Expect(m.Params[0].Name).To(Equal("x"))
+			Expect(m.Params[0].Required).To(BeTrue())
+			Expect(m.Params[0].Schema["type"]).To(Equal("number"))
+			Expect(m.Params[1].Name).To(Equal("y"))
+			Expect(m.Params[1].Required).To(BeFalse())
+			Expect(m.Result.Schema).To(Equal(map[string]interface{}{
+				"type":        "number",
+				"description": "The sum",
+			}))
+			Expect(m.Errors).To(HaveLen(3))
+			Expect(m.Errors[0].Code).To(Equal(-32602))
+			Expect(m.Errors[1].Code).To(Equal(-32600))
+			Expect(m.Errors[2].Code).To(Equal(60000))
+		})
+
+		It("should describe a params value that is not an object", func() {
+			m := doc.Methods[1]
+			Expect(m.ParamStructure).To(BeEmpty())
+			Expect(m.ParamsByValue).To(BeTrue())
+			Expect(m.Params).To(HaveLen(1))
+			Expect(m.Params[0].Name).To(Equal("params"))
+			Expect(m.Params[0].Schema["type"]).To(Equal("number"))
+		})
+
+		It("should describe methods without schemas", func() {
+			m := doc.Methods[2]
+			Expect(m.Params).To(BeEmpty())
+			Expect(m.Errors).To(BeEmpty())
+		})
+
+		When("subscriptions are supported", func() {
+			BeforeEach(func() {
+				rpc.SetEventEmitter(&emitter.Emitter{})
+				doc = rpc.OpenRPC(OpenRPCInfo{Title: "API", Version: "1.0"})
+			})
+
+			It("should describe the WebSocket subscription methods", func() {
+				Expect(doc.Methods).To(HaveLen(5))
+				Expect(doc.Methods[3].Name).To(Equal(MethodSubscribe))
+				Expect(doc.Methods[3].WebSocket).To(BeTrue())
+				Expect(doc.Methods[3].ParamsByValue).To(BeTrue())
+				Expect(doc.Methods[4].Name).To(Equal(MethodUnsubscribe))
+				Expect(doc.Methods[4].WebSocket).To(BeTrue())
+				Expect(doc.Methods[4].Result.Schema["type"]).To(Equal("boolean"))
+			})
+		})
+	})
+})
@@ -0,0 +1,181 @@
+package jsonrpc
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrCodeRateLimited is the error code of requests
+// rejected because the client exceeded its rate limit
+const ErrCodeRateLimited = -32002
+
+// tokenBucket holds the tokens available to a client
+type tokenBucket struct {
+	tokens   float64
+	lastFill time.Time
+}
+
+// rateLimiter is a token bucket rate limiter. Each client
+// has a bucket of tokens that is refilled at a fixed rate.
+// A request consumes as many tokens as the cost of its
+// method and is rejected when not enough tokens are left.
+type rateLimiter struct {
+	mtx     sync.Mutex
+	rate    float64
+	burst   float64
+	buckets map[string]*tokenBucket
+	pruned  time.Time
+	now     func() time.Time
+}
+
+// newRateLimiter creates a rateLimiter that refills rate
+// tokens per second and holds up to burst tokens
+func newRateLimiter(rate float64, burst int) *rateLimiter {
+	return &rateLimiter{
+		rate:    rate,
+		burst:   float64(burst),
+		buckets: make(map[string]*tokenBucket),
+		now:     time.Now,
+	}
+}
+
+// Take consumes cost tokens from the bucket of key. If not
+// enough tokens are left, it returns false and the time
+// until enough tokens are available. A cost above the
+// burst is reduced to the burst.
+func (r *rateLimiter) Take(key string, cost float64) (bool, time.Duration) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	now := r.now()
+	r.prune(now)
+
+	if cost > r.burst {
+		cost = r.burst
+	}
+
+	b, ok := r.buckets[key]
+	if !ok {
+		b = &tokenBucket{tokens: r.burst, lastFill: now}
+		r.buckets[key] = b
+	}
+
+	b.tokens += now.Sub(b.lastFill).Seconds() * r.rate
+	if b.tokens > r.burst {
+		b.tokens = r.burst
+	}
+	b.lastFill = now
+
+	if b.tokens < cost {
+		wait := (cost - b.tokens) / r.rate
+		return false, time.Duration(wait * float64(time.Second))
+	}
+
+	b.tokens -= cost
+	return true, 0
+}
+
+// prune removes buckets that have refilled completely
+// since they were last used. They are equivalent to
+// the new bucket created for an unknown key.
+func (r *rateLimiter) prune(now time.Time) {
+	if now.Sub(r.pruned) < time.Minute {
+		return
+	}
+	r.pruned = now
+	for key, b := range r.buckets {
+		if b.tokens+now.Sub(b.lastFill).Seconds()*r.rate >= r.burst {
+			delete(r.buckets, key)
+		}
+	}
+}
+
+// SetRateLimit limits the cost of the requests of each
+// client to rate per second with bursts of up to burst.
+// A zero rate disables rate limiting. If burst is not
+// set, it defaults to the cost allowed in a second.
+func (s *JSONRPC) SetRateLimit(rate float64, burst int) {
+	if rate <= 0 {
+		s.rateLimiter = nil
+		return
+	}
+	if burst < 1 {
+		burst = int(math.Ceil(rate))
+	}
+	s.rateLimiter = newRateLimiter(rate, burst)
+}
+
+// SetMethodCosts sets the cost of methods. It overrides
+// the costs set in the API set. Method names are not
+// case sensitive.
+func (s *JSONRPC) SetMethodCosts(costs map[string]float64) {
+	s.methodCosts = make(map[string]float64, len(costs))
+	for method, cost := range costs {
+		s.methodCosts[strings.ToLower(method)] = cost
+	}
+}
+
+// methodCost returns the cost of a method
+func (s *JSONRPC) methodCost(method string, f *APIInfo) float64 {
+	if cost, ok := s.methodCosts[strings.ToLower(method)]; ok {
+		return cost
+	}
+	if f.Cost > 0 {
+		return f.Cost
+	}
+	return 1
+}
+
+// clientKey identifies the client of a request. Clients
+// with a valid session token are identified by the
+// subject of the token, others by their IP address.
+func (s *JSONRPC) clientKey(r *http.Request) string {
+	if username, _ := s.identify(r); username != "" {
+		return "user:" + username
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+// Charge charges a cost to the client of a request. If
+// the client has exceeded its rate limit, it returns
+// false and the time until the cost can be charged.
+func (s *JSONRPC) Charge(r *http.Request, cost float64) (bool, time.Duration) {
+	if s.rateLimiter == nil {
+		return true, 0
+	}
+	return s.rateLimiter.Take(s.clientKey(r), cost)
+}
+
+// limit charges the cost of a method to the client of a
+// request. It returns an error response if the client
+// has exceeded its rate limit.
+func (s *JSONRPC) limit(r *http.Request, method string, f *APIInfo) *Response {
+	ok, wait := s.Charge(r, s.methodCost(method, f))
+	if ok {
+		return nil
+	}
+	retryAfter := int(math.Ceil(wait.Seconds()))
+	return Error(ErrCodeRateLimited, "Rate limit exceeded", map[string]interface{}{
+		"retryAfter": retryAfter,
+	})
+}
+
+// setRetryAfter sets the Retry-After header
+// when a response is a rate limit error
+func setRetryAfter(w http.ResponseWriter, resp *Response) {
+	if resp == nil || resp.Err == nil || resp.Err.Code != ErrCodeRateLimited {
+		return
+	}
+	if data, ok := resp.Err.Data.(map[string]interface{}); ok {
+		w.Header().Set("Retry-After", strconv.Itoa(data["retryAfter"].(int)))
+	}
+}
@@ -0,0 +1,134 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RateLimiter", func() {
+
+	Describe(".Take", func() {
+
+		var rl *rateLimiter
+		var now time.Time
+
+		BeforeEach(func() {
+			now = time.Now()
+			rl = newRateLimiter(1, 10)
+			rl.now = func() time.Time { return now }
+		})
+
+		It("should allow requests until the burst is consumed", func() {
+			ok, _ := rl.Take("client", 6)
+			Expect(ok).To(BeTrue())
+			ok, _ = rl.Take("client", 4)
+			Expect(ok).To(BeTrue())
+			ok, wait := rl.Take("client", 2)
+			Expect(ok).To(BeFalse())
+			Expect(wait).To(Equal(2 * time.Second))
+		})
+
+		It("should refill tokens over time", func() {
+			ok, _ := rl.Take("client", 10)
+			Expect(ok).To(BeTrue())
+			now = now.Add(3 * time.Second)
+			ok, _ = rl.Take("client", 3)
+			Expect(ok).To(BeTrue())
+		})
+
+		It("should track clients separately", func() {
+			ok, _ := rl.Take("client", 10)
+			Expect(ok).To(BeTrue())
+			ok, _ = rl.Take("client2", 10)
+			Expect(ok).To(BeTrue())
+		})
+
+		It("should reduce a cost above the burst to the burst", func() {
+			ok, _ := rl.Take("client", 100)
+			Expect(ok).To(BeTrue())
+		})
+	})
+
+	Describe(".handle", func() {
+
+		var rpc *JSONRPC
+
+		BeforeEach(func() {
+			rpc = New("", "abc", false)
+			rpc.SetRateLimit(1, 5)
+			rpc.MergeAPISet(APISet{
+				"echo": {
+					Namespace: "test",
+					Func: func(params interface{}) *Response {
+						return Success(params)
+					},
+				},
+				"expensive": {
+					Namespace: "test",
+					Cost:      5,
+					Func: func(params interface{}) *Response {
+						return Success(params)
+					},
+				},
+			})
+		})
+
+		call := func(method string, token string) (*httptest.ResponseRecorder, *Response) {
+			data, _ := json.Marshal(Request{JSONRPCVersion: "2.0", Method: method, ID: 1})
+			req, _ := http.NewRequest("POST", "/rpc", bytes.NewReader(data))
+			req.RemoteAddr = "127.0.0.1:5000"
+			if token != "" {
+				req.Header.Set("Authorization", "Bearer "+token)
+			}
+			rr := httptest.NewRecorder()
+			return rr, rpc.handle(rr, req)
+		}
+
+		It("should return rate limit error and Retry-After when the cost exceeds the limit", func() {
+			rr, resp := call("test_expensive", "")
+			Expect(resp.IsError()).To(BeFalse())
+
+			rr, resp = call("test_echo", "")
+			Expect(resp.Err).ToNot(BeNil())
+			Expect(resp.Err.Code).To(Equal(ErrCodeRateLimited))
+			Expect(resp.Err.Message).To(Equal("Rate limit exceeded"))
+			Expect(rr.Code).To(Equal(http.StatusTooManyRequests))
+			Expect(rr.Header().Get("Retry-After")).To(Equal("1"))
+		})
+
+		It("should identify clients with a session token by its subject", func() {
+			_, resp := call("test_expensive", "")
+			Expect(resp.IsError()).To(BeFalse())
+
+			token := MakeSessionToken("user", "abc", 0)
+			_, resp = call("test_expensive", token)
+			Expect(resp.IsError()).To(BeFalse())
+			_, resp = call("test_echo", token)
+			Expect(resp.Err.Code).To(Equal(ErrCodeRateLimited))
+		})
+
+		It("should use the cost set by SetMethodCosts", func() {
+			rpc.SetMethodCosts(map[string]float64{"test_expensive": 1})
+			for i := 0; i < 5; i++ {
+				_, resp := call("test_expensive", "")
+				Expect(resp.IsError()).To(BeFalse())
+			}
+			_, resp := call("test_expensive", "")
+			Expect(resp.Err.Code).To(Equal(ErrCodeRateLimited))
+		})
+
+		It("should not limit requests when rate limiting is disabled", func() {
+			rpc.SetRateLimit(0, 0)
+			for i := 0; i < 10; i++ {
+				_, resp := call("test_expensive", "")
+				Expect(resp.IsError()).To(BeFalse())
+			}
+		})
+	})
+})
@@ -0,0 +1,199 @@
+package jsonrpc
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// RESTPrefix is the path prefix of REST resources
+const RESTPrefix = "/rest"
+
+// RESTRoute exposes a public method of the API set as a
+// read-only REST resource. The resource is the result
+// of the method encoded as JSON.
+type RESTRoute struct {
+
+	// Path is the path of the resource relative to
+	// RESTPrefix. Path variables are enclosed in braces
+	// and can include a pattern (e.g /blocks/{number:[0-9]+})
+	Path string
+
+	// Method is the name of the method
+	// that produces the resource
+	Method string
+
+	// Params creates the parameters of the method
+	// from the path variables. The method is called
+	// without parameters when not set.
+	Params func(vars map[string]string) (interface{}, error)
+
+	// ETag creates the entity tag of the resource from
+	// the result of the method. Clients can use the tag
+	// to revalidate cached resources. Resources with an
+	// empty tag cannot be revalidated.
+	ETag func(result interface{}) string
+
+	// MaxAge returns the number of seconds a resource can
+	// be cached without revalidation given the result of
+	// the method. It should only return a positive value
+	// for resources that will not change. Resources are
+	// always revalidated when not set.
+	MaxAge func(result interface{}) int
+
+	// NotFoundCodes are the error codes of the method
+	// that indicate that the resource does not exist
+	NotFoundCodes []int
+}
+
+// isNotFound checks whether an error code
+// indicates that the resource does not exist
+func (r *RESTRoute) isNotFound(code int) bool {
+	for _, c := range r.NotFoundCodes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+// maxAge returns the number of seconds the
+// resource of a result can be cached
+func (r *RESTRoute) maxAge(result interface{}) int {
+	if r.MaxAge == nil {
+		return 0
+	}
+	return r.MaxAge(result)
+}
+
+// AddRESTRoutes adds REST resources. They are served
+// under RESTPrefix and must be added before the server
+// is started.
+func (s *JSONRPC) AddRESTRoutes(routes ...*RESTRoute) {
+	s.restRoutes = append(s.restRoutes, routes...)
+}
+
+// Call executes a method of the API set. It is meant
+// for calls made by the server so authorization and
+// rate limits do not apply.
+func (s *JSONRPC) Call(method string, params interface{}) *Response {
+	f := s.apiSet.Get(method)
+	if f == nil {
+		return Error(-32601, "Method not found", nil)
+	}
+	resp, _ := s.call(f, Request{JSONRPCVersion: "2.0", Method: method, Params: params, ID: 1})
+	return resp
+}
+
+// restHandler creates the handler of the REST resources
+func (s *JSONRPC) restHandler() http.Handler {
+	router := mux.NewRouter()
+	for _, route := range s.restRoutes {
+		router.Handle(RESTPrefix+route.Path, s.serveREST(route)).Methods("GET", "HEAD")
+	}
+	router.NotFoundHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeRESTError(w, http.StatusNotFound, Error(-32601, "Resource not found", nil))
+	})
+	return router
+}
+
+// serveREST creates the handler of a REST resource. Like
+// JSON RPC requests, requests of resources are rate
+// limited and logged. Private and streaming methods
+// cannot be exposed.
+func (s *JSONRPC) serveREST(route *RESTRoute) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+
+		start := time.Now()
+		req := Request{JSONRPCVersion: "2.0", Method: route.Method, ID: 1}
+		var resp *Response
+		defer func() { s.logRequest(r, req, resp, start) }()
+
+		f := s.apiSet.Get(route.Method)
+		if f == nil || f.Private || f.Stream != nil {
+			resp = Error(-32601, "Resource not found", nil)
+			writeRESTError(w, http.StatusNotFound, resp)
+			return
+		}
+
+		if resp = s.limit(r, route.Method, f); resp != nil {
+			setRetryAfter(w, resp)
+			writeRESTError(w, http.StatusTooManyRequests, resp)
+			return
+		}
+
+		if route.Params != nil {
+			params, err := route.Params(mux.Vars(r))
+			if err != nil {
+				resp = Error(-32602, fmt.Sprintf("Invalid params: %s", err), nil)
+				writeRESTError(w, http.StatusBadRequest, resp)
+				return
+			}
+			req.Params = params
+		}
+
+		if f.Params != nil {
+			if err := f.Params.Validate(req.Params); err != nil {
+				resp = Error(-32602, fmt.Sprintf("Invalid params: %s", err), nil)
+				writeRESTError(w, http.StatusBadRequest, resp)
+				return
+			}
+		}
+
+		resp, status := s.call(f, req)
+		if resp.IsError() {
+			if route.isNotFound(resp.Err.Code) {
+				status = http.StatusNotFound
+			}
+			writeRESTError(w, status, resp)
+			return
+		}
+
+		if maxAge := route.maxAge(resp.Result); maxAge > 0 {
+			w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", maxAge))
+		} else {
+			w.Header().Set("Cache-Control", "no-cache")
+		}
+
+		if route.ETag != nil {
+			if tag := route.ETag(resp.Result); tag != "" {
+				tag = `"` + tag + `"`
+				w.Header().Set("ETag", tag)
+				if matchETag(r.Header.Get("If-None-Match"), tag) {
+					w.WriteHeader(http.StatusNotModified)
+					return
+				}
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if r.Method != http.MethodHead {
+			json.NewEncoder(w).Encode(resp.Result)
+		}
+	}
+}
+
+// matchETag checks whether the value of an
+// If-None-Match header matches an entity tag
+func matchETag(header, tag string) bool {
+	for _, t := range strings.Split(header, ",") {
+		t = strings.TrimPrefix(strings.TrimSpace(t), "W/")
+		if t == tag || t == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// writeRESTError writes the error of
+// an error response as a JSON object
+func writeRESTError(w http.ResponseWriter, status int, resp *Response) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]*Err{"error": resp.Err})
+}
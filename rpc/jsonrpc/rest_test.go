@@ -0,0 +1,114 @@
+package jsonrpc
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("REST", func() {
+
+	var rpc *JSONRPC
+
+	BeforeEach(func() {
+		rpc = New("", "abc", false)
+		rpc.MergeAPISet(APISet{
+			"getItem": {
+				Namespace: "test",
+				Params:    Integer("The item number"),
+				Func: func(params interface{}) *Response {
+					n := params.(float64)
+					if n > 10 {
+						return Error(40001, "item not found", nil)
+					}
+					return Success(map[string]interface{}{"hash": fmt.Sprintf("0x%d", int(n))})
+				},
+			},
+			"secret": {
+				Namespace: "test",
+				Private:   true,
+				Func: func(params interface{}) *Response {
+					return Success("secret")
+				},
+			},
+		})
+		rpc.AddRESTRoutes(&RESTRoute{
+			Path:   "/items/{number:[0-9]+}",
+			Method: "test_getItem",
+			Params: func(vars map[string]string) (interface{}, error) {
+				n, err := strconv.Atoi(vars["number"])
+				return float64(n), err
+			},
+			ETag: func(result interface{}) string {
+				return result.(map[string]interface{})["hash"].(string)
+			},
+			MaxAge: func(result interface{}) int {
+				if result.(map[string]interface{})["hash"] == "0x1" {
+					return 0
+				}
+				return 60
+			},
+			NotFoundCodes: []int{40001},
+		}, &RESTRoute{
+			Path:   "/secret",
+			Method: "test_secret",
+		})
+	})
+
+	get := func(path string, headers map[string]string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", path, nil)
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		rr := httptest.NewRecorder()
+		rpc.restHandler().ServeHTTP(rr, req)
+		return rr
+	}
+
+	It("should return the result of the method with ETag and Cache-Control", func() {
+		rr := get("/rest/items/5", nil)
+		Expect(rr.Code).To(Equal(200))
+		Expect(rr.Header().Get("ETag")).To(Equal(`"0x5"`))
+		Expect(rr.Header().Get("Cache-Control")).To(Equal("public, max-age=60"))
+		var result map[string]interface{}
+		Expect(json.Unmarshal(rr.Body.Bytes(), &result)).To(BeNil())
+		Expect(result["hash"]).To(Equal("0x5"))
+	})
+
+	It("should require revalidation when the max age of the result is zero", func() {
+		rr := get("/rest/items/1", nil)
+		Expect(rr.Code).To(Equal(200))
+		Expect(rr.Header().Get("ETag")).To(Equal(`"0x1"`))
+		Expect(rr.Header().Get("Cache-Control")).To(Equal("no-cache"))
+	})
+
+	It("should return 304 when the ETag matches If-None-Match", func() {
+		rr := get("/rest/items/5", map[string]string{"If-None-Match": `"0x4", "0x5"`})
+		Expect(rr.Code).To(Equal(http.StatusNotModified))
+		Expect(rr.Body.Len()).To(Equal(0))
+	})
+
+	It("should return 404 when the method returns a not found error code", func() {
+		rr := get("/rest/items/11", nil)
+		Expect(rr.Code).To(Equal(http.StatusNotFound))
+		var body map[string]*Err
+		Expect(json.Unmarshal(rr.Body.Bytes(), &body)).To(BeNil())
+		Expect(body["error"].Code).To(Equal(40001))
+	})
+
+	It("should return 404 when the path is unknown", func() {
+		rr := get("/rest/items/abc", nil)
+		Expect(rr.Code).To(Equal(http.StatusNotFound))
+	})
+
+	It("should not expose private methods", func() {
+		rr := get("/rest/secret", nil)
+		Expect(rr.Code).To(Equal(http.StatusNotFound))
+		Expect(rr.Body.String()).ToNot(ContainSubstring(`"secret"`))
+	})
+})
@@ -0,0 +1,36 @@
+package jsonrpc
+
+// AllNamespaces allows a role to call
+// the private methods of all namespaces
+const AllNamespaces = "*"
+
+// Role describes the private methods
+// a session token can call
+type Role struct {
+
+	// Namespaces are the namespaces whose
+	// private methods can be called
+	Namespaces []string
+
+	// Methods are the full names (e.g namespace_method)
+	// of other private methods that can be called
+	Methods []string
+}
+
+// Roles defines a collection of roles
+type Roles map[string]Role
+
+// Permits checks whether the role can call a method
+func (r Role) Permits(method, namespace string) bool {
+	for _, ns := range r.Namespaces {
+		if ns == AllNamespaces || ns == namespace {
+			return true
+		}
+	}
+	for _, m := range r.Methods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,212 @@
+package jsonrpc
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Schema types
+const (
+	TypeString  = "string"
+	TypeNumber  = "number"
+	TypeInteger = "integer"
+	TypeBoolean = "boolean"
+	TypeObject  = "object"
+	TypeArray   = "array"
+)
+
+// Schema describes the parameters or the result of a
+// method. A schema without a type matches any value.
+type Schema struct {
+
+	// Type is the type of the value
+	Type string `json:"type,omitempty"`
+
+	// Description describes the value
+	Description string `json:"description,omitempty"`
+
+	// Optional indicates that the value can be absent
+	Optional bool `json:"optional,omitempty"`
+
+	// Properties describes the properties
+	// of an object value
+	Properties map[string]*Schema `json:"properties,omitempty"`
+
+	// Items describes the items of an array value
+	Items *Schema `json:"items,omitempty"`
+
+	// OneOf describes the alternative
+	// schemas the value can match
+	OneOf []*Schema `json:"oneOf,omitempty"`
+}
+
+// String creates a schema of a string value
+func String(desc string) *Schema {
+	return &Schema{Type: TypeString, Description: desc}
+}
+
+// Number creates a schema of a number value
+func Number(desc string) *Schema {
+	return &Schema{Type: TypeNumber, Description: desc}
+}
+
+// Integer creates a schema of an integer value
+func Integer(desc string) *Schema {
+	return &Schema{Type: TypeInteger, Description: desc}
+}
+
+// Boolean creates a schema of a boolean value
+func Boolean(desc string) *Schema {
+	return &Schema{Type: TypeBoolean, Description: desc}
+}
+
+// Any creates a schema that matches any value
+func Any(desc string) *Schema {
+	return &Schema{Description: desc}
+}
+
+// Object creates a schema of an object value
+func Object(desc string, props map[string]*Schema) *Schema {
+	return &Schema{Type: TypeObject, Description: desc, Properties: props}
+}
+
+// Array creates a schema of an array value
+func Array(desc string, items *Schema) *Schema {
+	return &Schema{Type: TypeArray, Description: desc, Items: items}
+}
+
+// OneOf creates a schema of a value that
+// matches one of the given schemas
+func OneOf(desc string, schemas ...*Schema) *Schema {
+	return &Schema{Description: desc, OneOf: schemas}
+}
+
+// Opt returns a copy of the schema
+// that allows an absent value
+func (s *Schema) Opt() *Schema {
+	c := *s
+	c.Optional = true
+	return &c
+}
+
+// Validate checks that a value matches the schema
+func (s *Schema) Validate(value interface{}) error {
+	return s.validate("params", value)
+}
+
+func (s *Schema) validate(path string, value interface{}) error {
+
+	if value == nil {
+		if s.Optional || (s.Type == "" && len(s.OneOf) == 0) {
+			return nil
+		}
+		return fmt.Errorf("%s is required", path)
+	}
+
+	if len(s.OneOf) > 0 {
+		var expected []string
+		for _, alt := range s.OneOf {
+			if alt.validate(path, value) == nil {
+				return nil
+			}
+			expected = append(expected, alt.name())
+		}
+		return fmt.Errorf("%s: expecting %s", path, strings.Join(expected, " or "))
+	}
+
+	var ok bool
+	switch s.Type {
+	case "":
+		ok = true
+	case TypeString:
+		_, ok = value.(string)
+	case TypeNumber:
+		_, ok = value.(float64)
+	case TypeInteger:
+		var n float64
+		n, ok = value.(float64)
+		ok = ok && n == math.Trunc(n)
+	case TypeBoolean:
+		_, ok = value.(bool)
+	case TypeObject:
+		var obj map[string]interface{}
+		if obj, ok = value.(map[string]interface{}); ok {
+			for name, prop := range s.Properties {
+				if err := prop.validate(path+"."+name, obj[name]); err != nil {
+					return err
+				}
+			}
+		}
+	case TypeArray:
+		var items []interface{}
+		if items, ok = value.([]interface{}); ok && s.Items != nil {
+			for i, item := range items {
+				if err := s.Items.validate(fmt.Sprintf("%s[%d]", path, i), item); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	if !ok {
+		return fmt.Errorf("%s: expecting %s", path, s.name())
+	}
+
+	return nil
+}
+
+// name returns the name of the type of the schema
+func (s *Schema) name() string {
+	if s.Type == TypeArray && s.Items != nil && s.Items.Type != "" {
+		return fmt.Sprintf("%s{%s}", s.Type, s.Items.Type)
+	}
+	if s.Type == "" {
+		return "any"
+	}
+	return s.Type
+}
+
+// JSONSchema converts the schema to a JSON Schema.
+// Properties that are not optional are required.
+func (s *Schema) JSONSchema() map[string]interface{} {
+
+	js := map[string]interface{}{}
+	if s.Type != "" {
+		js["type"] = s.Type
+	}
+	if s.Description != "" {
+		js["description"] = s.Description
+	}
+
+	if len(s.Properties) > 0 {
+		props := map[string]interface{}{}
+		var required []string
+		for name, prop := range s.Properties {
+			props[name] = prop.JSONSchema()
+			if !prop.Optional {
+				required = append(required, name)
+			}
+		}
+		js["properties"] = props
+		if len(required) > 0 {
+			sort.Strings(required)
+			js["required"] = required
+		}
+	}
+
+	if s.Items != nil {
+		js["items"] = s.Items.JSONSchema()
+	}
+
+	if len(s.OneOf) > 0 {
+		var oneOf []interface{}
+		for _, alt := range s.OneOf {
+			oneOf = append(oneOf, alt.JSONSchema())
+		}
+		js["oneOf"] = oneOf
+	}
+
+	return js
+}
@@ -0,0 +1,61 @@
+package jsonrpc
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Schema", func() {
+
+	Describe(".Validate", func() {
+
+		It("should accept any value when type is not set", func() {
+			Expect(Any("").Validate(nil)).To(BeNil())
+			Expect(Any("").Validate("a")).To(BeNil())
+		})
+
+		It("should return error when a required value is absent", func() {
+			err := String("").Validate(nil)
+			Expect(err).ToNot(BeNil())
+			Expect(err.Error()).To(Equal("params is required"))
+			Expect(String("").Opt().Validate(nil)).To(BeNil())
+		})
+
+		It("should check primitive types", func() {
+			Expect(String("").Validate("a")).To(BeNil())
+			Expect(String("").Validate(1.0).Error()).To(Equal("params: expecting string"))
+			Expect(Number("").Validate(1.5)).To(BeNil())
+			Expect(Integer("").Validate(2.0)).To(BeNil())
+			Expect(Integer("").Validate(1.5).Error()).To(Equal("params: expecting integer"))
+			Expect(Boolean("").Validate(true)).To(BeNil())
+			Expect(Boolean("").Validate("true")).ToNot(BeNil())
+		})
+
+		It("should check the properties of objects", func() {
+			s := Object("", map[string]*Schema{
+				"name": String(""),
+				"age":  Integer("").Opt(),
+			})
+			Expect(s.Validate(map[string]interface{}{"name": "a"})).To(BeNil())
+			Expect(s.Validate(map[string]interface{}{}).Error()).To(Equal("params.name is required"))
+			Expect(s.Validate(map[string]interface{}{"name": "a", "age": "1"}).Error()).
+				To(Equal("params.age: expecting integer"))
+			Expect(s.Validate("a").Error()).To(Equal("params: expecting object"))
+		})
+
+		It("should check the items of arrays", func() {
+			s := Array("", String(""))
+			Expect(s.Validate([]interface{}{"a", "b"})).To(BeNil())
+			Expect(s.Validate([]interface{}{"a", 1.0}).Error()).To(Equal("params[1]: expecting string"))
+			Expect(s.Validate("a").Error()).To(Equal("params: expecting array{string}"))
+		})
+
+		It("should accept a value that matches one of the alternatives", func() {
+			s := OneOf("", String(""), Array("", String("")))
+			Expect(s.Validate("a")).To(BeNil())
+			Expect(s.Validate([]interface{}{"a"})).To(BeNil())
+			Expect(s.Validate(1.0).Error()).To(Equal("params: expecting string or array{string}"))
+			Expect(s.Validate(nil).Error()).To(Equal("params is required"))
+		})
+	})
+})
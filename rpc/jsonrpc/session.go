@@ -0,0 +1,81 @@
+package jsonrpc
+
+import (
+	"context"
+	"net/http"
+
+	jwt "github.com/dgrijalva/jwt-go"
+	"github.com/ncodes/authtoken"
+)
+
+// sessionCtxKey is the context key of
+// the session of a request
+type sessionCtxKey struct{}
+
+// session is the result of parsing the
+// session token of a request
+type session struct {
+
+	// claims are the claims of a valid token
+	claims jwt.MapClaims
+
+	// headerErr is set when the request has
+	// no well-formed authorization header
+	headerErr error
+
+	// tokenErr is set when the token is not valid
+	tokenErr error
+}
+
+// parseSession parses the session token of a request
+func (s *JSONRPC) parseSession(r *http.Request) *session {
+	authToken, err := authtoken.FromRequest(r)
+	if err != nil {
+		return &session{headerErr: err}
+	}
+	claims, err := ParseSessionToken(authToken, s.sessionKey)
+	if err != nil {
+		return &session{tokenErr: err}
+	}
+	return &session{claims: claims}
+}
+
+// withSession parses the session token of a request and
+// attaches the result to the request so that the token
+// is verified once however many times it is needed.
+func (s *JSONRPC) withSession(r *http.Request) *http.Request {
+	if _, ok := r.Context().Value(sessionCtxKey{}).(*session); ok {
+		return r
+	}
+	return r.WithContext(context.WithValue(r.Context(), sessionCtxKey{}, s.parseSession(r)))
+}
+
+// session returns the session of a request. The session
+// attached to the request is used if present. Its claims
+// are validated again since a connection may outlive
+// its token.
+func (s *JSONRPC) session(r *http.Request) *session {
+	sess, ok := r.Context().Value(sessionCtxKey{}).(*session)
+	if !ok {
+		return s.parseSession(r)
+	}
+	if sess.claims != nil {
+		if err := sess.claims.Valid(); err != nil {
+			return &session{tokenErr: err}
+		}
+	}
+	return sess
+}
+
+// identify returns the username and role in the
+// session token of a request. They are empty when
+// the request has no valid session token.
+func (s *JSONRPC) identify(r *http.Request) (username, role string) {
+	sess := s.session(r)
+	if sess.claims == nil {
+		return
+	}
+	username, _ = sess.claims["username"].(string)
+	role, _ = sess.claims["role"].(string)
+	return
+}
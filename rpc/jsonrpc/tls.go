@@ -0,0 +1,84 @@
+package jsonrpc
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+)
+
+// SetTLS enables TLS using the given certificate and
+// key files. If clientCAFile is set, clients must present
+// a certificate signed by one of the CAs in the file.
+func (s *JSONRPC) SetTLS(certFile, keyFile, clientCAFile string) error {
+
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load certificate: %s", err)
+	}
+
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if clientCAFile != "" {
+		pool, err := loadCertPool(clientCAFile)
+		if err != nil {
+			return err
+		}
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	s.tlsConfig = tlsConfig
+	return nil
+}
+
+// IsSecured checks whether TLS is enabled
+func (s *JSONRPC) IsSecured() bool {
+	return s.tlsConfig != nil
+}
+
+// ClientTLSConfig creates the TLS configuration of a
+// client. If caFile is set, the server certificate
+// is verified using the CAs in the file instead of the
+// system CAs. If certFile and keyFile are set, the
+// certificate is presented to servers that require
+// client certificates.
+func ClientTLSConfig(caFile, certFile, keyFile string) (*tls.Config, error) {
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if caFile != "" {
+		pool, err := loadCertPool(caFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if certFile != "" || keyFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %s", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+// loadCertPool creates a certificate pool
+// from the PEM encoded certificates in a file
+func loadCertPool(file string) (*x509.CertPool, error) {
+	bs, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA file: %s", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(bs) {
+		return nil, fmt.Errorf("failed to read CA file: no valid certificate found")
+	}
+	return pool, nil
+}
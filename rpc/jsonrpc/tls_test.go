@@ -0,0 +1,115 @@
+package jsonrpc
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// writeTestCert creates a self-signed certificate
+// and writes it and its key to dir
+func writeTestCert(dir string) (certFile, keyFile string) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "127.0.0.1"},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, _ := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	keyDer, _ := x509.MarshalECPrivateKey(key)
+
+	certFile, keyFile = filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600)
+	ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600)
+	return
+}
+
+var _ = Describe("TLS", func() {
+
+	var rpc *JSONRPC
+	var dir, certFile, keyFile string
+
+	BeforeEach(func() {
+		rpc = New("", "abc", false)
+		dir, _ = ioutil.TempDir("", "")
+		certFile, keyFile = writeTestCert(dir)
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	Describe(".SetTLS", func() {
+		It("should return error when the certificate cannot be loaded", func() {
+			err := rpc.SetTLS(filepath.Join(dir, "unknown.pem"), keyFile, "")
+			Expect(err).ToNot(BeNil())
+			Expect(rpc.IsSecured()).To(BeFalse())
+		})
+
+		It("should enable TLS without client authentication", func() {
+			Expect(rpc.SetTLS(certFile, keyFile, "")).To(BeNil())
+			Expect(rpc.IsSecured()).To(BeTrue())
+			Expect(rpc.tlsConfig.ClientAuth).To(Equal(tls.NoClientCert))
+		})
+
+		It("should require client certificates when client CA is set", func() {
+			Expect(rpc.SetTLS(certFile, keyFile, certFile)).To(BeNil())
+			Expect(rpc.tlsConfig.ClientAuth).To(Equal(tls.RequireAndVerifyClientCert))
+			Expect(rpc.tlsConfig.ClientCAs).ToNot(BeNil())
+		})
+
+		It("should return error when client CA file has no certificate", func() {
+			err := rpc.SetTLS(certFile, keyFile, keyFile)
+			Expect(err).ToNot(BeNil())
+			Expect(err.Error()).To(Equal("failed to read CA file: no valid certificate found"))
+		})
+	})
+
+	Describe(".ClientTLSConfig", func() {
+		It("should set the root CAs and client certificate", func() {
+			tlsConfig, err := ClientTLSConfig(certFile, certFile, keyFile)
+			Expect(err).To(BeNil())
+			Expect(tlsConfig.RootCAs).ToNot(BeNil())
+			Expect(tlsConfig.Certificates).To(HaveLen(1))
+		})
+
+		It("should connect to a server that requires client certificates", func() {
+			Expect(rpc.SetTLS(certFile, keyFile, certFile)).To(BeNil())
+			ln, err := tls.Listen("tcp", "127.0.0.1:0", rpc.tlsConfig)
+			Expect(err).To(BeNil())
+			defer ln.Close()
+			go func() {
+				if conn, err := ln.Accept(); err == nil {
+					conn.(*tls.Conn).Handshake()
+					conn.Close()
+				}
+			}()
+
+			tlsConfig, err := ClientTLSConfig(certFile, certFile, keyFile)
+			Expect(err).To(BeNil())
+			conn, err := tls.Dial("tcp", ln.Addr().String(), tlsConfig)
+			Expect(err).To(BeNil())
+			Expect(conn.Handshake()).To(BeNil())
+			conn.Close()
+		})
+	})
+})
@@ -0,0 +1,423 @@
+package jsonrpc
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ellcrys/elld/util"
+	"github.com/gorilla/websocket"
+	"github.com/olebedev/emitter"
+)
+
+const (
+	// MethodSubscribe is the method WebSocket
+	// clients call to subscribe to a topic
+	MethodSubscribe = "rpc_subscribe"
+
+	// MethodUnsubscribe is the method WebSocket
+	// clients call to cancel a subscription
+	MethodUnsubscribe = "rpc_unsubscribe"
+
+	// MethodSubscription is the method of the
+	// notifications sent to subscribers
+	MethodSubscription = "rpc_subscription"
+)
+
+// wsSubscriberBufferSize is the number of notifications
+// that can be queued for a subscription. Notifications
+// are dropped for subscriptions that fall behind.
+const wsSubscriberBufferSize = 256
+
+// wsWriteTimeout is the maximum duration
+// for writing a message to a client
+const wsWriteTimeout = 10 * time.Second
+
+// Topic describes an event that
+// WebSocket clients can subscribe to
+type Topic struct {
+
+	// Event is the event emitted
+	// by the event emitter
+	Event string
+
+	// Description describes the topic
+	Description string
+
+	// Result converts the arguments of an event to the
+	// result sent to subscribers. If not set, the
+	// first argument is sent.
+	Result func(args []interface{}) interface{}
+}
+
+// Topics defines a collection of topics
+type Topics map[string]Topic
+
+// TopicInfo describes a topic
+type TopicInfo struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// subscription is a subscription of a client to a topic
+type subscription struct {
+	topic string
+	ch    chan interface{}
+}
+
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+}
+
+// wsAPIs describes the methods that are only
+// available to WebSocket clients. They are
+// handled by the WebSocket connection and
+// are not part of the API set.
+func wsAPIs() APISet {
+	return APISet{
+		MethodSubscribe: {
+			Namespace: "rpc",
+			Description: "Subscribe to a topic. Events of the topic are sent as " +
+				MethodSubscription + " notifications",
+			Params: String("The name of the topic"),
+			Result: String("The subscription ID"),
+		},
+		MethodUnsubscribe: {
+			Namespace:   "rpc",
+			Description: "Cancel a subscription",
+			Params:      String("The subscription ID"),
+			Result:      Boolean("Whether the subscription was cancelled"),
+		},
+	}
+}
+
+// SetEventEmitter sets the event emitter
+// whose events drive subscriptions
+func (s *JSONRPC) SetEventEmitter(evt *emitter.Emitter) {
+	s.subMtx.Lock()
+	defer s.subMtx.Unlock()
+	s.evt = evt
+}
+
+// AddTopics adds topics that WebSocket
+// clients can subscribe to
+func (s *JSONRPC) AddTopics(topics ...Topics) {
+	s.subMtx.Lock()
+	defer s.subMtx.Unlock()
+	for _, set := range topics {
+		for name, t := range set {
+			s.topics[name] = t
+		}
+	}
+}
+
+// Topics gets information about all topics
+func (s *JSONRPC) Topics() (topicsInfo []TopicInfo) {
+	s.subMtx.RLock()
+	defer s.subMtx.RUnlock()
+	for name, t := range s.topics {
+		topicsInfo = append(topicsInfo, TopicInfo{
+			Name:        name,
+			Description: t.Description,
+		})
+	}
+	return
+}
+
+// subscribe creates a subscription to a topic.
+// It returns the subscription ID.
+func (s *JSONRPC) subscribe(topicName string) (string, *subscription, error) {
+	s.subMtx.Lock()
+	defer s.subMtx.Unlock()
+
+	if s.evt == nil {
+		return "", nil, fmt.Errorf("subscriptions are not supported")
+	}
+
+	topic, ok := s.topics[topicName]
+	if !ok {
+		return "", nil, fmt.Errorf("unknown topic")
+	}
+
+	// Listen to the event of the topic
+	// when it gets its first subscriber
+	if _, ok := s.listening[topicName]; !ok {
+		s.listening[topicName] = struct{}{}
+		go s.listen(topicName, topic, s.evt.On(topic.Event))
+	}
+
+	id := util.RandString(16)
+	sub := &subscription{
+		topic: topicName,
+		ch:    make(chan interface{}, wsSubscriberBufferSize),
+	}
+	s.subs[id] = sub
+
+	return id, sub, nil
+}
+
+// unsubscribe removes a subscription
+func (s *JSONRPC) unsubscribe(id string) {
+	s.subMtx.Lock()
+	defer s.subMtx.Unlock()
+	if sub, ok := s.subs[id]; ok {
+		delete(s.subs, id)
+		close(sub.ch)
+	}
+}
+
+// listen delivers the events of a
+// topic to its subscriptions
+func (s *JSONRPC) listen(topicName string, topic Topic, events <-chan emitter.Event) {
+	for evt := range events {
+
+		var result interface{}
+		if topic.Result != nil {
+			result = topic.Result(evt.Args)
+		} else if len(evt.Args) > 0 {
+			result = evt.Args[0]
+		}
+
+		s.subMtx.RLock()
+		for _, sub := range s.subs {
+			if sub.topic != topicName {
+				continue
+			}
+			select {
+			case sub.ch <- result:
+			default:
+			}
+		}
+		s.subMtx.RUnlock()
+	}
+}
+
+// wsConn is a WebSocket client connection
+type wsConn struct {
+
+	// s is the JSONRPC server
+	s *JSONRPC
+
+	// r is the upgrade request. It is used to
+	// authorize access to private methods.
+	r *http.Request
+
+	// conn is the WebSocket connection
+	conn *websocket.Conn
+
+	// wMtx serializes writes
+	wMtx sync.Mutex
+
+	// subs are the IDs of the
+	// subscriptions of the client
+	subs map[string]struct{}
+}
+
+// serveWS upgrades a request to a WebSocket connection
+// and processes the requests sent by the client until
+// the connection is closed or the server is stopped
+func (s *JSONRPC) serveWS(w http.ResponseWriter, r *http.Request) {
+
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+
+	// Messages larger than the limit close the connection
+	conn.SetReadLimit(MaxRequestSize)
+
+	c := &wsConn{s: s, r: r, conn: conn, subs: make(map[string]struct{})}
+	defer func() {
+		for id := range c.subs {
+			s.unsubscribe(id)
+		}
+		conn.Close()
+	}()
+
+	// Close the connection when the server stops
+	closed := make(chan struct{})
+	defer close(closed)
+	go func(done chan bool) {
+		select {
+		case <-done:
+			conn.Close()
+		case <-closed:
+		}
+	}(s.done)
+
+	for {
+		_, msg, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		if resp := c.handleMessage(msg); resp != nil {
+			if err := c.write(resp); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// write sends a message to the client
+func (c *wsConn) write(v interface{}) error {
+	c.wMtx.Lock()
+	defer c.wMtx.Unlock()
+	c.conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+	return c.conn.WriteJSON(v)
+}
+
+// handleMessage processes a request or a batch of
+// requests. It returns the response to send or nil
+// if there is nothing to send.
+func (c *wsConn) handleMessage(msg []byte) interface{} {
+
+	if !isBatch(msg) {
+		var req Request
+		if err := json.Unmarshal(msg, &req); err != nil {
+			return Error(-32700, "Parse error", nil)
+		}
+		if resp := c.handleRequest(req); resp != nil {
+			return resp
+		}
+		return nil
+	}
+
+	var rawReqs []json.RawMessage
+	if err := json.Unmarshal(msg, &rawReqs); err != nil {
+		return Error(-32700, "Parse error", nil)
+	}
+
+	if len(rawReqs) == 0 {
+		return Error(-32600, "Invalid Request: empty batch", nil)
+	}
+
+	if len(rawReqs) > c.s.maxBatchSize {
+		return Error(-32600, fmt.Sprintf("Invalid Request: batch size exceeds %d",
+			c.s.maxBatchSize), nil)
+	}
+
+	var resps []*Response
+	for _, rawReq := range rawReqs {
+		var req Request
+		if err := json.Unmarshal(rawReq, &req); err != nil {
+			resps = append(resps, Error(-32600, "Invalid Request", nil))
+			continue
+		}
+		if resp := c.handleRequest(req); resp != nil {
+			resps = append(resps, resp)
+		}
+	}
+
+	if len(resps) == 0 {
+		return nil
+	}
+
+	return resps
+}
+
+// handleRequest processes a request. Successfully
+// executed notifications are not responded to.
+func (c *wsConn) handleRequest(req Request) (resp *Response) {
+
+	// The ID of a request with an invalid ID
+	// cannot be echoed; respond with a null ID
+	if !req.HasValidID() {
+		return errInvalidID()
+	}
+
+	start := time.Now()
+	defer func() { c.s.logRequest(c.r, req, resp, start) }()
+
+	switch req.Method {
+	case MethodSubscribe:
+		resp = c.subscribe(req)
+	case MethodUnsubscribe:
+		resp = c.unsubscribe(req)
+	default:
+		f, errResp, _ := c.s.check(c.r, req)
+		if errResp != nil {
+			resp = errResp
+		} else if f.Stream != nil {
+			resp = Error(-32600, fmt.Sprintf("Invalid Request: streaming methods are "+
+				"not supported; use %s", MethodSubscribe), nil)
+		} else {
+			resp, _ = c.s.call(f, req)
+		}
+	}
+
+	if !resp.IsError() && req.IsNotification() {
+		return nil
+	}
+
+	resp.ID = req.ID
+	return resp
+}
+
+// subscribe subscribes the client to the topic passed
+// as the parameter of the request. Events of the topic
+// are sent as notifications that include the
+// subscription ID.
+func (c *wsConn) subscribe(req Request) *Response {
+
+	if req.JSONRPCVersion != "2.0" {
+		return Error(-32600, "`jsonrpc` value is required", nil)
+	}
+
+	topicName, ok := req.Params.(string)
+	if params, isArr := req.Params.([]interface{}); isArr && len(params) == 1 {
+		topicName, ok = params[0].(string)
+	}
+	if !ok {
+		return Error(-32602, "Invalid params: topic name is required", nil)
+	}
+
+	id, sub, err := c.s.subscribe(topicName)
+	if err != nil {
+		return Error(-32602, fmt.Sprintf("Invalid params: %s", err), nil)
+	}
+	c.subs[id] = struct{}{}
+
+	go func() {
+		for result := range sub.ch {
+			c.write(&Request{
+				JSONRPCVersion: "2.0",
+				Method:         MethodSubscription,
+				Params: map[string]interface{}{
+					"subscription": id,
+					"result":       result,
+				},
+			})
+		}
+	}()
+
+	return Success(id)
+}
+
+// unsubscribe cancels the subscription whose
+// ID is passed as the parameter of the request
+func (c *wsConn) unsubscribe(req Request) *Response {
+
+	if req.JSONRPCVersion != "2.0" {
+		return Error(-32600, "`jsonrpc` value is required", nil)
+	}
+
+	id, ok := req.Params.(string)
+	if params, isArr := req.Params.([]interface{}); isArr && len(params) == 1 {
+		id, ok = params[0].(string)
+	}
+	if !ok {
+		return Error(-32602, "Invalid params: subscription ID is required", nil)
+	}
+
+	if _, ok := c.subs[id]; !ok {
+		return Success(false)
+	}
+
+	delete(c.subs, id)
+	c.s.unsubscribe(id)
+
+	return Success(true)
+}
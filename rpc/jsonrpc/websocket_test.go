@@ -0,0 +1,132 @@
+package jsonrpc
+
+import (
+	"net/http/httptest"
+	"strings"
+
+	"github.com/gorilla/websocket"
+	"github.com/olebedev/emitter"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("WebSocket", func() {
+
+	var rpc *JSONRPC
+	var evt *emitter.Emitter
+	var srv *httptest.Server
+	var conn *websocket.Conn
+
+	BeforeEach(func() {
+		var err error
+		evt = &emitter.Emitter{}
+		rpc = New("", "abc", false)
+		rpc.SetEventEmitter(evt)
+		rpc.AddTopics(Topics{
+			"newBlocks": {Event: "event.test"},
+		})
+		rpc.apiSet["echo"] = APIInfo{
+			Namespace: "test",
+			Func: func(params interface{}) *Response {
+				return Success(params)
+			},
+		}
+		srv = httptest.NewServer(rpc.withOnRequest(rpc.serveWS))
+		conn, _, err = websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+		Expect(err).To(BeNil())
+	})
+
+	AfterEach(func() {
+		conn.Close()
+		srv.Close()
+	})
+
+	It("should call methods of the API set", func() {
+		Expect(conn.WriteJSON(Request{JSONRPCVersion: "2.0", Method: "echo", Params: "hi", ID: 1})).To(BeNil())
+		var resp Response
+		Expect(conn.ReadJSON(&resp)).To(BeNil())
+		Expect(resp.Err).To(BeNil())
+		Expect(resp.Result).To(Equal("hi"))
+		Expect(resp.ID).To(Equal(float64(1)))
+	})
+
+	It("should return error when a private method is called without a session token", func() {
+		rpc.apiSet["secret"] = APIInfo{
+			Private:   true,
+			Namespace: "test",
+			Func: func(params interface{}) *Response {
+				return Success("secret")
+			},
+		}
+		Expect(conn.WriteJSON(Request{JSONRPCVersion: "2.0", Method: "secret", ID: 1})).To(BeNil())
+		var resp Response
+		Expect(conn.ReadJSON(&resp)).To(BeNil())
+		Expect(resp.Err).ToNot(BeNil())
+		Expect(resp.Err.Code).To(Equal(-32600))
+	})
+
+	It("should close the connection when a message exceeds the maximum request size", func() {
+		data := make([]byte, MaxRequestSize+1)
+		conn.WriteMessage(websocket.TextMessage, data)
+		_, _, err := conn.ReadMessage()
+		Expect(err).ToNot(BeNil())
+	})
+
+	Describe("subscriptions", func() {
+
+		var subID string
+
+		BeforeEach(func() {
+			Expect(conn.WriteJSON(Request{JSONRPCVersion: "2.0", Method: MethodSubscribe,
+				Params: []string{"newBlocks"}, ID: 1})).To(BeNil())
+			var resp Response
+			Expect(conn.ReadJSON(&resp)).To(BeNil())
+			Expect(resp.Err).To(BeNil())
+			subID = resp.Result.(string)
+		})
+
+		It("should send events of the topic as notifications", func() {
+			go evt.Emit("event.test", "block1")
+			var n Request
+			Expect(conn.ReadJSON(&n)).To(BeNil())
+			Expect(n.Method).To(Equal(MethodSubscription))
+			Expect(n.ID).To(BeNil())
+			Expect(n.Params).To(Equal(map[string]interface{}{
+				"subscription": subID,
+				"result":       "block1",
+			}))
+		})
+
+		It("should return error when the topic is unknown", func() {
+			Expect(conn.WriteJSON(Request{JSONRPCVersion: "2.0", Method: MethodSubscribe,
+				Params: "unknown", ID: 2})).To(BeNil())
+			var resp Response
+			Expect(conn.ReadJSON(&resp)).To(BeNil())
+			Expect(resp.Err.Code).To(Equal(-32602))
+			Expect(resp.Err.Message).To(Equal("Invalid params: unknown topic"))
+		})
+
+		It("should remove the subscription when unsubscribed", func() {
+			Expect(conn.WriteJSON(Request{JSONRPCVersion: "2.0", Method: MethodUnsubscribe,
+				Params: subID, ID: 2})).To(BeNil())
+			var resp Response
+			Expect(conn.ReadJSON(&resp)).To(BeNil())
+			Expect(resp.Result).To(Equal(true))
+			Eventually(func() int {
+				rpc.subMtx.RLock()
+				defer rpc.subMtx.RUnlock()
+				return len(rpc.subs)
+			}).Should(Equal(0))
+		})
+
+		It("should remove the subscriptions when the client disconnects", func() {
+			conn.Close()
+			Eventually(func() int {
+				rpc.subMtx.RLock()
+				defer rpc.subMtx.RUnlock()
+				return len(rpc.subs)
+			}).Should(Equal(0))
+		})
+	})
+})
@@ -0,0 +1,130 @@
+package rpc
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+
+	"github.com/ellcrys/elld/rpc/jsonrpc"
+	"github.com/ellcrys/elld/types"
+)
+
+// blockMaxAge is the number of seconds a block
+// requested by hash can be cached once it is
+// deep enough in the main chain to not change.
+const blockMaxAge = 86400
+
+// blockCacheDepth is the number of blocks that must
+// be added on top of a block before it can be cached.
+// Blocks closer to the tip can be reorganized out
+// of the main chain.
+const blockCacheDepth = 100
+
+// RESTRoutes returns the read-only
+// resources of the REST gateway
+func (s *Server) RESTRoutes() []*jsonrpc.RESTRoute {
+	return []*jsonrpc.RESTRoute{
+		{
+			Path:          "/tip",
+			Method:        "state_getTipBlock",
+			ETag:          blockETag,
+			NotFoundCodes: []int{types.ErrCodeBlockNotFound},
+		},
+		{
+			Path:          "/blocks/{number:[0-9]+}",
+			Method:        "state_getBlock",
+			Params:        numberParam("number"),
+			ETag:          blockETag,
+			NotFoundCodes: []int{types.ErrCodeBlockNotFound},
+		},
+		{
+			Path:          "/blocks/{hash:0x[0-9a-fA-F]+}",
+			Method:        "state_getBlockByHash",
+			Params:        stringParam("hash"),
+			ETag:          blockETag,
+			MaxAge:        s.blockMaxAge,
+			NotFoundCodes: []int{types.ErrCodeBlockNotFound},
+		},
+		{
+			Path:          "/transactions/{hash}",
+			Method:        "state_getTransaction",
+			Params:        stringParam("hash"),
+			ETag:          s.stateETag,
+			NotFoundCodes: []int{types.ErrCodeTransactionNotFound},
+		},
+		{
+			Path:          "/accounts/{address}",
+			Method:        "state_getAccount",
+			Params:        stringParam("address"),
+			ETag:          s.stateETag,
+			NotFoundCodes: []int{types.ErrCodeAccountNotFound},
+		},
+	}
+}
+
+// numberParam creates a function that
+// passes a path variable as a number
+func numberParam(name string) func(map[string]string) (interface{}, error) {
+	return func(vars map[string]string) (interface{}, error) {
+		n, err := strconv.ParseUint(vars[name], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		return float64(n), nil
+	}
+}
+
+// stringParam creates a function that
+// passes a path variable as a string
+func stringParam(name string) func(map[string]string) (interface{}, error) {
+	return func(vars map[string]string) (interface{}, error) {
+		return vars[name], nil
+	}
+}
+
+// blockETag uses the hash of a block as its entity tag
+func blockETag(result interface{}) string {
+	block, _ := result.(map[string]interface{})
+	hash, _ := block["hash"].(string)
+	return hash
+}
+
+// blockNumber returns the number of a block result
+func blockNumber(result interface{}) (uint64, bool) {
+	block, _ := result.(map[string]interface{})
+	header, _ := block["header"].(map[string]interface{})
+	number, _ := header["number"].(string)
+	n, err := strconv.ParseUint(number, 0, 64)
+	return n, err == nil
+}
+
+// blockMaxAge returns the number of seconds a block can
+// be cached. Blocks with fewer than blockCacheDepth
+// blocks on top of them are not cached.
+func (s *Server) blockMaxAge(result interface{}) int {
+	number, ok := blockNumber(result)
+	if !ok {
+		return 0
+	}
+	tip, ok := blockNumber(s.rpc.Call("state_getTipBlock", nil).Result)
+	if !ok || tip < number+blockCacheDepth {
+		return 0
+	}
+	return blockMaxAge
+}
+
+// stateETag creates the entity tag of a resource that
+// changes with the state of the chain. The tag is made
+// of the hash of the tip block and a digest of the
+// resource so it changes when the resource changes
+// even if the tip changed while it was read.
+func (s *Server) stateETag(result interface{}) string {
+	tip := blockETag(s.rpc.Call("state_getTipBlock", nil).Result)
+	if tip == "" {
+		return ""
+	}
+	bs, _ := json.Marshal(result)
+	digest := sha256.Sum256(bs)
+	return tip + "-" + hex.EncodeToString(digest[:8])
+}
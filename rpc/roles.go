@@ -0,0 +1,53 @@
+package rpc
+
+import (
+	"github.com/ellcrys/elld/rpc/jsonrpc"
+	"github.com/ellcrys/elld/types"
+)
+
+// Roles of RPC users
+const (
+	// RoleReadOnly can only call private
+	// methods that do not change state
+	RoleReadOnly = "read-only"
+
+	// RoleWallet can manage accounts and
+	// send transactions
+	RoleWallet = "wallet"
+
+	// RoleMinerOperator can control the miner
+	RoleMinerOperator = "miner-operator"
+
+	// RoleAdmin can call all private methods
+	RoleAdmin = "admin"
+)
+
+// readOnlyMethods are the private methods
+// that do not change state
+var readOnlyMethods = []string{
+	types.NamespaceNode + "_info",
+}
+
+// Roles returns the roles of RPC users
+// and the private methods they can call
+func Roles() jsonrpc.Roles {
+	return jsonrpc.Roles{
+		RoleReadOnly: {
+			Methods: readOnlyMethods,
+		},
+		RoleWallet: {
+			Namespaces: []string{types.NamespacePersonal},
+			Methods: append([]string{
+				types.NamespaceEll + "_send",
+				types.NamespacePool + "_cancelLocal",
+			}, readOnlyMethods...),
+		},
+		RoleMinerOperator: {
+			Namespaces: []string{types.NamespaceMiner},
+			Methods:    readOnlyMethods,
+		},
+		RoleAdmin: {
+			Namespaces: []string{jsonrpc.AllNamespaces},
+		},
+	}
+}
@@ -2,6 +2,9 @@ package rpc
 
 import (
 	"fmt"
+
+	"github.com/ellcrys/elld/rpc/jsonrpc"
+	"github.com/ellcrys/elld/types"
 )
 
 var (
@@ -11,3 +14,21 @@ var (
 		return fmt.Errorf("invalid argument type: expecting " + expectedType)
 	}
 )
+
+// Descriptions of the application errors that
+// API functions return. They are used to list
+// the errors of methods in OpenRPC documents.
+var (
+	ErrDescListAccountFailed = &jsonrpc.Err{Code: types.ErrCodeListAccountFailed, Message: "Failed to list accounts"}
+	ErrDescAccountNotFound   = &jsonrpc.Err{Code: types.ErrCodeAccountNotFound, Message: "Account not found"}
+	ErrDescInvalidAuth       = &jsonrpc.Err{Code: types.ErrCodeInvalidAuthCredentials, Message: "Invalid credentials"}
+	ErrDescQueryFailed       = &jsonrpc.Err{Code: types.ErrCodeQueryFailed, Message: "Query failed"}
+	ErrDescBlockNotFound     = &jsonrpc.Err{Code: types.ErrCodeBlockNotFound, Message: "Block not found"}
+	ErrDescTxNotFound        = &jsonrpc.Err{Code: types.ErrCodeTransactionNotFound, Message: "Transaction not found"}
+	ErrDescBlockQuery        = &jsonrpc.Err{Code: types.ErrCodeBlockQuery, Message: "Block query failed"}
+	ErrDescArgType           = &jsonrpc.Err{Code: types.ErrCodeUnexpectedArgType, Message: "Unexpected argument type"}
+	ErrDescQueryParam        = &jsonrpc.Err{Code: types.ErrCodeQueryParamError, Message: "Invalid query parameter"}
+	ErrDescUnexpected        = &jsonrpc.Err{Code: types.ErrCodeUnexpected, Message: "Unexpected error"}
+	ErrDescAddress           = &jsonrpc.Err{Code: types.ErrCodeAddress, Message: "Invalid address"}
+	ErrDescTxFailed          = &jsonrpc.Err{Code: types.ErrCodeTxFailed, Message: "Transaction failed"}
+)
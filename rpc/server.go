@@ -1,12 +1,19 @@
 package rpc
 
 import (
+	"io"
+	"math"
+	"path"
 	"sync"
+	"time"
+
+	rotatelogs "github.com/lestrrat-go/file-rotatelogs"
 
 	"github.com/ellcrys/elld/elldb"
 
 	"github.com/ellcrys/elld/config"
 	"github.com/ellcrys/elld/rpc/jsonrpc"
+	"github.com/ellcrys/elld/types"
 	"github.com/ellcrys/elld/util/logger"
 )
 
@@ -50,9 +57,73 @@ func NewServer(db elldb.DB, addr string, cfg *config.EngineConfig, log logger.Lo
 	s.log = log
 	s.cfg = cfg
 	s.rpc = jsonrpc.New(addr, cfg.RPC.SessionSecretKey, cfg.RPC.DisableAuth)
+	s.rpc.SetRoles(Roles())
+	if cfg.RPC.MaxBatchSize > 0 {
+		s.rpc.SetMaxBatchSize(cfg.RPC.MaxBatchSize)
+	}
+	s.rpc.SetRateLimit(cfg.RPC.RateLimit, cfg.RPC.RateBurst)
+	s.rpc.SetMethodCosts(cfg.RPC.MethodCosts)
+	if cfg.RPC.EnableREST {
+		s.rpc.AddRESTRoutes(s.RESTRoutes()...)
+	}
+	s.rpc.SetLogger(log)
+	s.rpc.SetAuditedMethods(AuditedMethods()...)
+	if cfg.NetDataDir() != "" {
+		s.rpc.SetAuditLog(newAuditLog(cfg.NetDataDir(), cfg.RPC.AuditLogMaxAge, log))
+	}
+	if cfg.RPC.TLSCert != "" {
+		if err := s.rpc.SetTLS(cfg.RPC.TLSCert, cfg.RPC.TLSKey, cfg.RPC.TLSClientCA); err != nil {
+			log.Fatal("Failed to configure RPC TLS", "Err", err.Error())
+		}
+	}
 	return s
 }
 
+// AuditedMethods returns the methods whose calls are
+// audited even when they are public: the methods of
+// the personal and miner namespaces, ell_send and rpc_stop.
+func AuditedMethods() []string {
+	return []string{
+		types.NamespacePersonal + "_*",
+		types.NamespaceMiner + "_*",
+		types.NamespaceEll + "_send",
+		types.NamespaceRPC + "_stop",
+	}
+}
+
+// newAuditLog creates the writer of the audit log of
+// private and audited method calls. The log is stored in the logs
+// directory of the network data directory and is
+// rotated daily. Rotated logs are kept for maxAgeDays
+// days or forever when maxAgeDays is zero.
+func newAuditLog(netDataDir string, maxAgeDays int, log logger.Logger) io.Writer {
+	filePath := path.Join(netDataDir, "logs", "rpc_audit.log")
+
+	// rotatelogs deletes logs older than a week unless a
+	// max age or rotation count is set, so the logs are
+	// kept by setting a count that cannot be reached.
+	retention := rotatelogs.WithRotationCount(math.MaxUint32)
+	if maxAgeDays > 0 {
+		retention = rotatelogs.WithMaxAge(time.Duration(maxAgeDays) * 24 * time.Hour)
+	}
+
+	writer, err := rotatelogs.New(
+		filePath+".%Y%m%d",
+		rotatelogs.WithLinkName(filePath),
+		rotatelogs.WithRotationTime(24*time.Hour),
+		retention,
+	)
+	if err != nil {
+		log.Fatal("Failed to create RPC audit log", "Err", err.Error())
+	}
+	return writer
+}
+
+// IsSecured checks whether the server uses TLS
+func (s *Server) IsSecured() bool {
+	return s.rpc.IsSecured()
+}
+
 // GetAddr gets the address
 func (s *Server) GetAddr() string {
 	s.RLock()
@@ -66,7 +137,8 @@ func (s *Server) Serve() {
 	s.Lock()
 	s.started = true
 	s.Unlock()
-	s.log.Info("RPC service started", "Address", s.addr)
+	s.log.Info("RPC service started", "Address", s.addr, "TLS", s.rpc.IsSecured(),
+		"REST", s.cfg.RPC.EnableREST, "GraphQL", s.cfg.RPC.EnableGraphQL)
 	s.rpc.Serve()
 }
 
@@ -0,0 +1,50 @@
+package rpc
+
+import (
+	"github.com/ellcrys/elld/rpc/jsonrpc"
+	"github.com/ellcrys/elld/types/core"
+	"github.com/ellcrys/elld/util"
+	"github.com/olebedev/emitter"
+)
+
+// SetEventEmitter sets the event emitter whose
+// events are sent to WebSocket subscribers
+func (s *Server) SetEventEmitter(evt *emitter.Emitter) {
+	s.rpc.SetEventEmitter(evt)
+	s.rpc.AddTopics(s.Topics())
+}
+
+// Topics returns the topics WebSocket clients can subscribe to
+func (s *Server) Topics() jsonrpc.Topics {
+	return jsonrpc.Topics{
+		"newBlocks": {
+			Event:       core.EventNewBlock,
+			Description: "Blocks added to the main chain",
+			Result: func(args []interface{}) interface{} {
+				return util.EncodeForJS(args[0])
+			},
+		},
+		"reOrgs": {
+			Event:       core.EventReOrg,
+			Description: "Reorganizations of the main chain",
+		},
+		"pendingTransactions": {
+			Event:       core.EventTransactionPooled,
+			Description: "Transactions added to the transaction pool",
+			Result: func(args []interface{}) interface{} {
+				return util.EncodeForJS(args[0])
+			},
+		},
+		"syncStatus": {
+			Event:       core.EventSyncStatus,
+			Description: "Progress of block synchronization",
+			Result: func(args []interface{}) interface{} {
+				status, _ := args[0].(*core.SyncStateInfo)
+				return map[string]interface{}{
+					"syncing": status != nil,
+					"status":  status,
+				}
+			},
+		},
+	}
+}
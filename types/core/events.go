@@ -21,6 +21,19 @@ const (
 	// has been added to the transaction pool
 	EventTransactionPooled = "event.txPooled"
 
+	// EventTransactionEvicted indicates that a transaction
+	// has been evicted from the pool to make room for a
+	// local transaction
+	EventTransactionEvicted = "event.txEvicted"
+
+	// EventTransactionExpired indicates that a transaction
+	// has been removed from the pool because it expired
+	EventTransactionExpired = "event.txExpired"
+
+	// EventTransactionMined indicates that a pooled transaction
+	// has been included in a block on the main chain
+	EventTransactionMined = "event.txMined"
+
 	// EventTransactionInvalid indicates that a transaction
 	// has been declared invalid
 	EventTransactionInvalid = "event.txInvalid"
@@ -38,4 +51,13 @@ const (
 	// EventBlockProcessed describes an event about
 	// a processed block
 	EventBlockProcessed = "event.blockProcessed"
+
+	// EventReOrg indicates that the main chain
+	// has been reorganized with the blocks of a branch
+	EventReOrg = "event.reOrg"
+
+	// EventSyncStatus describes an event about the
+	// progress of block synchronization. A nil status
+	// indicates that synchronization has ended.
+	EventSyncStatus = "event.syncStatus"
 )
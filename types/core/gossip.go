@@ -2,6 +2,7 @@ package core
 
 import (
 	"context"
+	"io"
 	"math/big"
 	"sync"
 	"time"
@@ -15,18 +16,24 @@ import (
 // Handshake represents the first
 // message between peers
 type Handshake struct {
-	Version                  string    `json:"version" msgpack:"version"`
-	BestBlockHash            util.Hash `json:"bestBlockHash" msgpack:"bestBlockHash"`
-	BestBlockTotalDifficulty *big.Int  `json:"bestBlockTD" msgpack:"bestBlockTD"`
-	BestBlockNumber          uint64    `json:"bestBlockNumber" msgpack:"bestBlockNumber"`
-	Name                     string    `json:"name" msgpack:"name"`
+	Version                  string              `json:"version" msgpack:"version"`
+	BestBlockHash            util.Hash           `json:"bestBlockHash" msgpack:"bestBlockHash"`
+	BestBlockTotalDifficulty *big.Int            `json:"bestBlockTD" msgpack:"bestBlockTD"`
+	BestBlockNumber          uint64              `json:"bestBlockNumber" msgpack:"bestBlockNumber"`
+	Name                     string              `json:"name" msgpack:"name"`
+	MsgVersions              map[string][]uint32 `json:"msgVersions" msgpack:"msgVersions"`
+	Features                 []string            `json:"features" msgpack:"features"`
+	GenesisHash              util.Hash           `json:"genesisHash" msgpack:"genesisHash"`
+	NetworkID                string              `json:"networkId" msgpack:"networkId"`
+	Reject                   *Reject             `json:"reject" msgpack:"reject"`
 }
 
 // EncodeMsgpack implements
 // msgpack.CustomEncoder
 func (h *Handshake) EncodeMsgpack(enc *msgpack.Encoder) error {
 	tdStr := h.BestBlockTotalDifficulty.String()
-	return enc.Encode(h.Version, h.Name, h.BestBlockHash, h.BestBlockNumber, tdStr)
+	return enc.Encode(h.Version, h.Name, h.BestBlockHash, h.BestBlockNumber, tdStr,
+		h.MsgVersions, h.Features, h.GenesisHash, h.NetworkID, h.Reject)
 }
 
 // DecodeMsgpack implements
@@ -38,6 +45,48 @@ func (h *Handshake) DecodeMsgpack(dec *msgpack.Decoder) error {
 		return err
 	}
 	h.BestBlockTotalDifficulty, _ = new(big.Int).SetString(tdStr, 10)
+
+	// Peers that predate message versioning send only
+	// the fields above. The remaining fields are decoded
+	// only while the message has values left.
+	optional := []interface{}{&h.MsgVersions, &h.Features,
+		&h.GenesisHash, &h.NetworkID, &h.Reject}
+	for _, field := range optional {
+		if _, err := dec.PeekCode(); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if err := dec.Decode(field); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// HandshakeV1 is a Handshake encoded as the first version
+// of the handshake message. It is used with peers that
+// predate message versioning and carries none of the
+// fields they do not know.
+type HandshakeV1 Handshake
+
+// EncodeMsgpack implements
+// msgpack.CustomEncoder
+func (h *HandshakeV1) EncodeMsgpack(enc *msgpack.Encoder) error {
+	tdStr := h.BestBlockTotalDifficulty.String()
+	return enc.Encode(h.Version, h.Name, h.BestBlockHash, h.BestBlockNumber, tdStr)
+}
+
+// DecodeMsgpack implements
+// msgpack.CustomDecoder
+func (h *HandshakeV1) DecodeMsgpack(dec *msgpack.Decoder) error {
+	var tdStr string
+	if err := dec.Decode(&h.Version, &h.Name, &h.BestBlockHash,
+		&h.BestBlockNumber, &tdStr); err != nil {
+		return err
+	}
+	h.BestBlockTotalDifficulty, _ = new(big.Int).SetString(tdStr, 10)
 	return nil
 }
 
@@ -46,6 +95,12 @@ func (h *Handshake) DecodeMsgpack(dec *msgpack.Decoder) error {
 type GetAddr struct {
 }
 
+// FindPeers is used to request for the
+// addresses of the peers closest to a key
+type FindPeers struct {
+	Target []byte `json:"target" msgpack:"target"`
+}
+
 // Addr is used to send peer addresses
 // in response to a GetAddr
 type Addr struct {
@@ -112,6 +167,13 @@ type Reject struct {
 	ExtraData []byte `json:"extraData" msgpack:"extraData"`
 }
 
+// Handshake reject codes
+const (
+	RejectCodeNetworkMismatch int32 = iota + 1
+	RejectCodeGenesisMismatch
+	RejectCodeIncompatibleProtocol
+)
+
 // RequestBlock represents a message requesting for a block
 type RequestBlock struct {
 	Hash string `json:"hash" msgpack:"hash"`
@@ -168,6 +230,56 @@ type TxOk struct {
 	Ok bool `json:"ok" msgpack:"ok"`
 }
 
+// TxInv announces the hashes of transactions
+// available to be requested by the receiver
+type TxInv struct {
+	Hashes []util.Hash `json:"hashes" msgpack:"hashes"`
+}
+
+// GetTxs represents a message requesting
+// for the transactions of the given hashes
+type GetTxs struct {
+	Hashes []util.Hash `json:"hashes" msgpack:"hashes"`
+}
+
+// Txs represents a message containing
+// transactions requested with GetTxs
+type Txs struct {
+	Txs []*Transaction `json:"txs" msgpack:"txs"`
+}
+
+// CompactBlock describes a block using its header
+// and the short IDs of its transactions. Transactions
+// the receiver is unlikely to have are prefilled.
+type CompactBlock struct {
+	Header    *Header        `json:"header" msgpack:"header"`
+	Hash      util.Hash      `json:"hash" msgpack:"hash"`
+	Sig       []byte         `json:"sig" msgpack:"sig"`
+	ShortIDs  []uint64       `json:"shortIds" msgpack:"shortIds"`
+	Prefilled []*PrefilledTx `json:"prefilled" msgpack:"prefilled"`
+}
+
+// PrefilledTx is a transaction included
+// whole in a CompactBlock message
+type PrefilledTx struct {
+	Index int          `json:"index" msgpack:"index"`
+	Tx    *Transaction `json:"tx" msgpack:"tx"`
+}
+
+// GetBlockTxs represents a message requesting for the
+// transactions of a block at the given indexes
+type GetBlockTxs struct {
+	Hash    util.Hash `json:"hash" msgpack:"hash"`
+	Indexes []int     `json:"indexes" msgpack:"indexes"`
+}
+
+// BlockTxs represents a message containing
+// transactions requested with GetBlockTxs
+type BlockTxs struct {
+	Hash util.Hash      `json:"hash" msgpack:"hash"`
+	Txs  []*Transaction `json:"txs" msgpack:"txs"`
+}
+
 // BlockInfo describes a block
 type BlockInfo struct {
 	Hash util.Hash `json:"hash" msgpack:"hash"`
@@ -195,6 +307,11 @@ type WrappedStream struct {
 // Gossip represent messages and interactions between nodes
 type Gossip interface {
 
+	// Manage starts and stops routines
+	// that run in the background
+	Manage()
+	Stop()
+
 	// Address messages
 	OnAddr(s net.Stream, rp Engine) error
 	RelayAddresses(addrs []*Address) []error
@@ -209,6 +326,8 @@ type Gossip interface {
 	OnGetBlockHashes(s net.Stream, rp Engine) error
 	SendGetBlockBodies(rp Engine, hashes []util.Hash) (*BlockBodies, error)
 	OnGetBlockBodies(s net.Stream, rp Engine) error
+	SendCompactBlock(rp Engine, block types.Block) error
+	OnCompactBlock(s net.Stream, rp Engine) error
 
 	// Handshake messages
 	SendHandshake(rp Engine) error
@@ -219,6 +338,11 @@ type Gossip interface {
 	SendGetAddr(remotePeers []Engine) error
 	OnGetAddr(s net.Stream, rp Engine) error
 
+	// Peer discovery messages
+	FindPeers(target []byte) []Engine
+	SendFindPeers(rp Engine, target []byte) ([]Engine, error)
+	OnFindPeers(s net.Stream, rp Engine) error
+
 	// Ping messages
 	SendPing(remotePeers []Engine)
 	SendPingToPeer(remotePeer Engine) error
@@ -230,6 +354,8 @@ type Gossip interface {
 	// Transaction messages
 	BroadcastTx(tx types.Transaction, remotePeers []Engine) error
 	OnTx(s net.Stream, rp Engine) error
+	SendTxInv(rp Engine, hashes []util.Hash) error
+	OnTxInv(s net.Stream, rp Engine) error
 
 	// PickBroadcasters selects N random addresses from
 	// the given slice of addresses and caches them to
@@ -243,9 +369,10 @@ type Gossip interface {
 	GetBroadcasters() *BroadcastPeers
 	GetRandBroadcasters() *BroadcastPeers
 
-	// NewStream creates a stream for a given protocol
-	// ID and between the local peer and the given remote peer.
-	NewStream(remotePeer Engine, msgVersion string) (net.Stream,
+	// NewStream creates a stream between the local peer and
+	// the given remote peer. The first of the given protocol
+	// IDs supported by the remote peer is used.
+	NewStream(remotePeer Engine, msgVersions ...string) (net.Stream,
 		context.CancelFunc, error)
 
 	// CheckRemotePeer performs validation against the remote peer.
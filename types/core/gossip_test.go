@@ -0,0 +1,57 @@
+package core
+
+import (
+	"bytes"
+	"math/big"
+
+	"github.com/ellcrys/elld/util"
+	"github.com/vmihailenco/msgpack"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Gossip", func() {
+
+	Describe("Handshake serialization", func() {
+
+		It("should serialize and deserialize as expected", func() {
+			h := &Handshake{
+				Version:                  "0.1.0",
+				Name:                     "node",
+				BestBlockHash:            util.StrToHash("abc"),
+				BestBlockNumber:          10,
+				BestBlockTotalDifficulty: new(big.Int).SetInt64(1000),
+				MsgVersions:              map[string][]uint32{"ping": {1, 2}},
+				Features:                 []string{"txinv"},
+				GenesisHash:              util.StrToHash("xyz"),
+				NetworkID:                "0001",
+			}
+			bs, err := msgpack.Marshal(h)
+			Expect(err).To(BeNil())
+
+			var h2 Handshake
+			Expect(msgpack.Unmarshal(bs, &h2)).To(BeNil())
+			Expect(&h2).To(Equal(h))
+		})
+
+		It("should deserialize a handshake without message versions and features", func() {
+			var buf bytes.Buffer
+			hash := util.StrToHash("abc")
+			err := msgpack.NewEncoder(&buf).Encode("0.1.0", "node", hash, uint64(10), "1000")
+			Expect(err).To(BeNil())
+
+			var h Handshake
+			Expect(msgpack.Unmarshal(buf.Bytes(), &h)).To(BeNil())
+			Expect(h.Version).To(Equal("0.1.0"))
+			Expect(h.Name).To(Equal("node"))
+			Expect(h.BestBlockHash).To(Equal(hash))
+			Expect(h.BestBlockNumber).To(Equal(uint64(10)))
+			Expect(h.BestBlockTotalDifficulty.Int64()).To(Equal(int64(1000)))
+			Expect(h.MsgVersions).To(BeNil())
+			Expect(h.Features).To(BeNil())
+			Expect(h.NetworkID).To(BeEmpty())
+			Expect(h.Reject).To(BeNil())
+		})
+	})
+})
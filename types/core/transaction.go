@@ -6,6 +6,7 @@ import (
 
 	"github.com/btcsuite/btcutil/base58"
 	"github.com/ellcrys/elld/crypto"
+	"github.com/ellcrys/elld/types"
 	"github.com/ellcrys/elld/util"
 )
 
@@ -29,6 +30,10 @@ var (
 
 	// TxTypeAlloc represents a transaction to alloc coins to an account
 	TxTypeAlloc int64 = 0x2
+
+	// TxTypeMultiTransfer represents a transaction from
+	// an account to multiple accounts
+	TxTypeMultiTransfer int64 = 0x3
 )
 
 // Base58CheckVersionTxPayload is the base58 encode version adopted
@@ -43,17 +48,19 @@ type InvokeArgs struct {
 
 // Transaction represents a transaction
 type Transaction struct {
-	Type         int64       `json:"type" msgpack:"type"`
-	Nonce        uint64      `json:"nonce" msgpack:"nonce"`
-	To           util.String `json:"to" msgpack:"to"`
-	From         util.String `json:"from" msgpack:"from"`
-	SenderPubKey util.String `json:"senderPubKey" msgpack:"senderPubKey"`
-	Value        util.String `json:"value" msgpack:"value"`
-	Timestamp    int64       `json:"timestamp" msgpack:"timestamp"`
-	Fee          util.String `json:"fee" msgpack:"fee"`
-	InvokeArgs   *InvokeArgs `json:"invokeArgs,omitempty" msgpack:"invokeArgs"`
-	Sig          []byte      `json:"sig" msgpack:"sig"`
-	Hash         util.Hash   `json:"hash" msgpack:"hash"`
+	Type         int64             `json:"type" msgpack:"type"`
+	Nonce        uint64            `json:"nonce" msgpack:"nonce"`
+	To           util.String       `json:"to" msgpack:"to"`
+	From         util.String       `json:"from" msgpack:"from"`
+	SenderPubKey util.String       `json:"senderPubKey" msgpack:"senderPubKey"`
+	Value        util.String       `json:"value" msgpack:"value"`
+	Timestamp    int64             `json:"timestamp" msgpack:"timestamp"`
+	Fee          util.String       `json:"fee" msgpack:"fee"`
+	InvokeArgs   *InvokeArgs       `json:"invokeArgs,omitempty" msgpack:"invokeArgs"`
+	Validity     *types.TxValidity `json:"validity,omitempty" msgpack:"validity"`
+	Outputs      []*types.TxOutput `json:"outputs,omitempty" msgpack:"outputs"`
+	Sig          []byte            `json:"sig" msgpack:"sig"`
+	Hash         util.Hash         `json:"hash" msgpack:"hash"`
 }
 
 // NewTransaction creates a new transaction
@@ -178,6 +185,48 @@ func (tx *Transaction) GetType() int64 {
 	return tx.Type
 }
 
+// GetValidity gets the validity window
+func (tx *Transaction) GetValidity() *types.TxValidity {
+	return tx.Validity
+}
+
+// SetValidity sets the validity window
+func (tx *Transaction) SetValidity(v *types.TxValidity) {
+	tx.Validity = v
+}
+
+// GetOutputs gets the outputs of
+// a multi-recipient transfer
+func (tx *Transaction) GetOutputs() []*types.TxOutput {
+	return tx.Outputs
+}
+
+// withOptionalFields appends the validity window and
+// outputs to the given transaction fields. They are only
+// appended when set so that the bytes of transactions
+// without them are unchanged.
+func (tx *Transaction) withOptionalFields(data []interface{}) []interface{} {
+
+	if tx.Validity != nil {
+		data = append(data, []interface{}{
+			tx.Validity.NotAfterHeight,
+			tx.Validity.NotAfterTime,
+			tx.Validity.NotBeforeHeight,
+			tx.Validity.NotBeforeTime,
+		})
+	}
+
+	if len(tx.Outputs) > 0 {
+		var outputs []interface{}
+		for _, out := range tx.Outputs {
+			outputs = append(outputs, []interface{}{out.To, out.Value})
+		}
+		data = append(data, outputs)
+	}
+
+	return data
+}
+
 // GetBytesNoHashAndSig converts a transaction
 // to bytes equivalent but omits the hash and
 // signature in the result.
@@ -200,7 +249,7 @@ func (tx *Transaction) GetBytesNoHashAndSig() []byte {
 		tx.Value,
 	}
 
-	return getBytes(data)
+	return getBytes(tx.withOptionalFields(data))
 }
 
 // Bytes converts a transaction
@@ -226,7 +275,7 @@ func (tx *Transaction) Bytes() []byte {
 		tx.Value,
 	}
 
-	return getBytes(data)
+	return getBytes(tx.withOptionalFields(data))
 }
 
 // GetSizeNoFee returns the virtual size of the
@@ -256,7 +305,7 @@ func (tx *Transaction) GetSizeNoFee() int64 {
 		tx.Value,
 	}
 
-	return int64(len(getBytes(data)))
+	return int64(len(getBytes(tx.withOptionalFields(data))))
 }
 
 // ComputeHash returns the SHA256
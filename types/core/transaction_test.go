@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/ellcrys/elld/crypto"
+	"github.com/ellcrys/elld/types"
 	"github.com/ellcrys/elld/util"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -30,6 +31,13 @@ var _ = Describe("Transaction", func() {
 			Expect(bs).ToNot(BeEmpty())
 			Expect(bs).To(Equal(expected))
 		})
+
+		It("should include the validity window when set", func() {
+			tx := &Transaction{Type: 1, Nonce: 1, To: "some_address", SenderPubKey: "some_pub_key"}
+			bs := tx.GetBytesNoHashAndSig()
+			tx.Validity = &types.TxValidity{NotAfterHeight: 100}
+			Expect(tx.GetBytesNoHashAndSig()).ToNot(Equal(bs))
+		})
 	})
 
 	Describe(".TxSign", func() {
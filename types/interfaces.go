@@ -327,6 +327,8 @@ type Transaction interface {
 	GetID() string
 	Sign(privKey string) ([]byte, error)
 	GetType() int64
+	GetValidity() *TxValidity
+	GetOutputs() []*TxOutput
 	GetFrom() util.String
 	SetFrom(util.String)
 	GetTo() util.String
@@ -373,6 +375,9 @@ type TxContainer interface {
 // TxPool represents a transactions pool
 type TxPool interface {
 	Put(tx Transaction) error
+	PutLocal(tx Transaction) error
+	IsLocal(hash string) bool
+	GetLocal() []Transaction
 	Has(tx Transaction) bool
 	HasByHash(hash string) bool
 	Remove(txs ...Transaction)
@@ -2140,6 +2140,34 @@ func (mr *MockTransactionMockRecorder) GetType() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetType", reflect.TypeOf((*MockTransaction)(nil).GetType))
 }
 
+// GetValidity mocks base method
+func (m *MockTransaction) GetValidity() *types.TxValidity {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetValidity")
+	ret0, _ := ret[0].(*types.TxValidity)
+	return ret0
+}
+
+// GetValidity indicates an expected call of GetValidity
+func (mr *MockTransactionMockRecorder) GetValidity() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetValidity", reflect.TypeOf((*MockTransaction)(nil).GetValidity))
+}
+
+// GetOutputs mocks base method
+func (m *MockTransaction) GetOutputs() []*types.TxOutput {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOutputs")
+	ret0, _ := ret[0].([]*types.TxOutput)
+	return ret0
+}
+
+// GetOutputs indicates an expected call of GetOutputs
+func (mr *MockTransactionMockRecorder) GetOutputs() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOutputs", reflect.TypeOf((*MockTransaction)(nil).GetOutputs))
+}
+
 // GetFrom mocks base method
 func (m *MockTransaction) GetFrom() util.String {
 	m.ctrl.T.Helper()
@@ -2612,6 +2640,48 @@ func (mr *MockTxPoolMockRecorder) Put(tx interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Put", reflect.TypeOf((*MockTxPool)(nil).Put), tx)
 }
 
+// PutLocal mocks base method
+func (m *MockTxPool) PutLocal(tx types.Transaction) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PutLocal", tx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PutLocal indicates an expected call of PutLocal
+func (mr *MockTxPoolMockRecorder) PutLocal(tx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutLocal", reflect.TypeOf((*MockTxPool)(nil).PutLocal), tx)
+}
+
+// IsLocal mocks base method
+func (m *MockTxPool) IsLocal(hash string) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsLocal", hash)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// IsLocal indicates an expected call of IsLocal
+func (mr *MockTxPoolMockRecorder) IsLocal(hash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsLocal", reflect.TypeOf((*MockTxPool)(nil).IsLocal), hash)
+}
+
+// GetLocal mocks base method
+func (m *MockTxPool) GetLocal() []types.Transaction {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLocal")
+	ret0, _ := ret[0].([]types.Transaction)
+	return ret0
+}
+
+// GetLocal indicates an expected call of GetLocal
+func (mr *MockTxPoolMockRecorder) GetLocal() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLocal", reflect.TypeOf((*MockTxPool)(nil).GetLocal))
+}
+
 // Has mocks base method
 func (m *MockTxPool) Has(tx types.Transaction) bool {
 	m.ctrl.T.Helper()
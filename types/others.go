@@ -153,3 +153,47 @@ type ConnectError string
 func (c ConnectError) Error() string {
 	return string(c)
 }
+
+// TxValidity describes the window within which a
+// transaction can be included in a block. The window
+// can be bounded by block height and/or timestamp.
+// Bounds with a zero value are not enforced.
+type TxValidity struct {
+
+	// NotBeforeHeight is the lowest block
+	// number the transaction can be included in
+	NotBeforeHeight uint64 `json:"notBeforeHeight,omitempty" msgpack:"notBeforeHeight"`
+
+	// NotAfterHeight is the highest block
+	// number the transaction can be included in
+	NotAfterHeight uint64 `json:"notAfterHeight,omitempty" msgpack:"notAfterHeight"`
+
+	// NotBeforeTime is the earliest block
+	// timestamp the transaction can be included in
+	NotBeforeTime int64 `json:"notBeforeTime,omitempty" msgpack:"notBeforeTime"`
+
+	// NotAfterTime is the latest block timestamp
+	// the transaction can be included in
+	NotAfterTime int64 `json:"notAfterTime,omitempty" msgpack:"notAfterTime"`
+}
+
+// IsPending checks whether the window has not
+// started at the given block number and timestamp
+func (v *TxValidity) IsPending(height uint64, timestamp int64) bool {
+	return (v.NotBeforeHeight > 0 && height < v.NotBeforeHeight) ||
+		(v.NotBeforeTime > 0 && timestamp < v.NotBeforeTime)
+}
+
+// IsExpired checks whether the window has ended
+// at the given block number and timestamp
+func (v *TxValidity) IsExpired(height uint64, timestamp int64) bool {
+	return (v.NotAfterHeight > 0 && height > v.NotAfterHeight) ||
+		(v.NotAfterTime > 0 && timestamp > v.NotAfterTime)
+}
+
+// TxOutput describes a payment to a recipient
+// of a multi-recipient transfer transaction
+type TxOutput struct {
+	To    util.String `json:"to" msgpack:"to"`
+	Value util.String `json:"value" msgpack:"value"`
+}
@@ -6,6 +6,7 @@
 package util
 
 import (
+	"fmt"
 	"net"
 )
 
@@ -85,6 +86,9 @@ var (
 	// zero4Net defines the IPv4 address block for address staring with 0
 	// (0.0.0.0/8).
 	zero4Net = ipNet("0.0.0.0", 8, 32)
+
+	// heNet defines the Hurricane Electric IPv6 address block.
+	heNet = ipNet("2001:470::", 32, 128)
 )
 
 // ipNet returns a net.IPNet struct given the passed IP address string, number
@@ -224,3 +228,54 @@ func IsRoutable(ip net.IP) bool {
 func IsDevAddr(ip net.IP) bool {
 	return isValid(ip) && (isLocal(ip) || isRFC1918(ip))
 }
+
+// GroupKey returns a string representing the network group an address is part
+// of. This is the /16 for IPv4, the /32 (/36 for he.net) for IPv6, "local" for
+// a local address and "unroutable" for an unroutable address. Addresses
+// embedding an IPv4 address are grouped by the /16 of the embedded address.
+func GroupKey(ip net.IP) string {
+	if ip == nil {
+		return "unroutable"
+	}
+	if isLocal(ip) {
+		return "local"
+	}
+	if !IsRoutable(ip) {
+		return "unroutable"
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		return ip4.Mask(net.CIDRMask(16, 32)).String()
+	}
+	if isRFC6145(ip) || isRFC6052(ip) {
+		// last four bytes are the ip address
+		newIP := ip[12:16]
+		return newIP.Mask(net.CIDRMask(16, 32)).String()
+	}
+	if isRFC3964(ip) {
+		newIP := ip[2:6]
+		return newIP.Mask(net.CIDRMask(16, 32)).String()
+	}
+	if isRFC4380(ip) {
+		// teredo tunnels have the last 4 bytes as the v4 address XOR
+		// 0xff.
+		newIP := net.IP(make([]byte, 4))
+		for i, byte := range ip[12:16] {
+			newIP[i] = byte ^ 0xff
+		}
+		return newIP.Mask(net.CIDRMask(16, 32)).String()
+	}
+	if isOnionCatTor(ip) {
+		// group is keyed off the first 4 bits of the actual onion key.
+		return fmt.Sprintf("tor:%d", ip[6]&((1<<4)-1))
+	}
+
+	// OK, so now we know ourselves to be a IPv6 address.
+	// bitcoind uses /32 for everything, except for Hurricane Electric's
+	// (he.net) IP range, which it uses /36 for.
+	bits := 32
+	if heNet.Contains(ip) {
+		bits = 36
+	}
+
+	return ip.Mask(net.CIDRMask(bits, 128)).String()
+}